@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// channelIdRe matches a YouTube channel ID, eg. UCjmJDM5pRKbUlVIzDYYWb6g.
+var channelIdRe = regexp.MustCompile(`^UC[A-Za-z0-9_-]{22}$`)
+
+// blockedChannelReasonPrefix starts the rejection reason of extras whose
+// video was uploaded by a blocked or non-allowed channel.
+const blockedChannelReasonPrefix = "Blocked channel"
+
+// ChannelsConfig holds the YouTube channels extras may be downloaded from.
+// Videos from allowed channels rank higher in searches; videos from blocked
+// channels are never shown or downloaded.
+type ChannelsConfig struct {
+	Allow []string `yaml:"allow" json:"allow"`
+	Block []string `yaml:"block" json:"block"`
+	// AllowOnly rejects every channel not in Allow
+	AllowOnly bool `yaml:"allowOnly" json:"allowOnly"`
+}
+
+func DefaultChannelsConfig() ChannelsConfig {
+	return ChannelsConfig{Allow: []string{}, Block: []string{}, AllowOnly: false}
+}
+
+// GetChannelsConfig loads the channels config from config.yml
+func GetChannelsConfig() (ChannelsConfig, error) {
+	cfg := DefaultChannelsConfig()
+	if err := decodeConfigSection("channels", &cfg); err != nil {
+		return DefaultChannelsConfig(), err
+	}
+	return cfg, nil
+}
+
+// SaveChannelsConfig saves the channels config to config.yml
+func SaveChannelsConfig(cfg ChannelsConfig) error {
+	return saveConfigSection("channels", cfg)
+}
+
+func ensureChannelsDefaults(config map[string]interface{}) bool {
+	if _, ok := config["channels"].(map[string]interface{}); ok {
+		return false
+	}
+	config["channels"] = DefaultChannelsConfig()
+	return true
+}
+
+// normalizeChannelList trims channel IDs, drops duplicates and reports the
+// first invalid ID.
+func normalizeChannelList(ids []string) ([]string, string) {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if !channelIdRe.MatchString(id) {
+			return nil, id
+		}
+		if !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out, ""
+}
+
+// Handler to get channels config
+func GetChannelsConfigHandler(c *gin.Context) {
+	cfg, _ := GetChannelsConfig()
+	respondJSON(c, http.StatusOK, cfg)
+}
+
+// Handler to save channels config
+func SaveChannelsConfigHandler(c *gin.Context) {
+	var req ChannelsConfig
+	if err := c.BindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	var bad string
+	if req.Allow, bad = normalizeChannelList(req.Allow); bad == "" {
+		req.Block, bad = normalizeChannelList(req.Block)
+	}
+	if bad != "" {
+		respondError(c, http.StatusBadRequest, "invalid channel id: "+bad)
+		return
+	}
+	for _, id := range req.Block {
+		if slices.Contains(req.Allow, id) {
+			respondError(c, http.StatusBadRequest, "channel both allowed and blocked: "+id)
+			return
+		}
+	}
+	if err := SaveChannelsConfig(req); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
+}
+
+// restricts reports whether the config can reject any channel.
+func (cfg ChannelsConfig) restricts() bool {
+	return len(cfg.Block) > 0 || cfg.AllowOnly
+}
+
+// allowed reports whether channelId is in the allowlist.
+func (cfg ChannelsConfig) allowed(channelId string) bool {
+	return channelId != "" && slices.Contains(cfg.Allow, channelId)
+}
+
+// rejectReason returns why videos from the channel may not be used, or ""
+// if they may.
+func (cfg ChannelsConfig) rejectReason(channelId, channel string) string {
+	name := channelId
+	if channel != "" {
+		name = fmt.Sprintf("%s (%s)", channel, channelId)
+	}
+	if channelId != "" && slices.Contains(cfg.Block, channelId) {
+		return fmt.Sprintf("%s: %s is blocklisted", blockedChannelReasonPrefix, name)
+	}
+	if cfg.AllowOnly && !cfg.allowed(channelId) {
+		if name == "" {
+			name = "unknown channel"
+		}
+		return fmt.Sprintf("%s: %s is not allowlisted", blockedChannelReasonPrefix, name)
+	}
+	return ""
+}
+
+// fetchVideoChannel returns the ID and name of the channel that uploaded a video.
+func fetchVideoChannel(ctx context.Context, youtubeId string) (string, string, error) {
+	out, err := fetchVideoInfoJSON(ctx, youtubeWatchURL(youtubeId))
+	if err != nil {
+		return "", "", err
+	}
+	var info struct {
+		ChannelID string `json:"channel_id"`
+		Channel   string `json:"channel"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return "", "", err
+	}
+	return info.ChannelID, info.Channel, nil
+}
+
+// rejectBlockedChannel checks the channel of a queued video before it is
+// downloaded. A video from a blocked channel is rejected and removed from the
+// queue, and true is returned. If the channel can't be looked up the download
+// goes ahead.
+func rejectBlockedChannel(ctx context.Context, item DownloadQueueItem) bool {
+	cfg, _ := GetChannelsConfig()
+	// Channel lists only apply to YouTube videos
+	if !cfg.restricts() || item.SourceURL != "" {
+		return false
+	}
+	channelId, channel, err := fetchVideoChannel(ctx, item.YouTubeID)
+	if err != nil {
+		TrailarrLog(WARN, "QUEUE", "Could not look up channel of %s, downloading anyway: %v", item.YouTubeID, err)
+		return false
+	}
+	reason := cfg.rejectReason(channelId, channel)
+	if reason == "" {
+		return false
+	}
+	TrailarrLog(WARN, "QUEUE", "Rejecting %s: %s", item.YouTubeID, reason)
+	if err := SetExtraRejectedPersistent(item.MediaType, item.MediaId, item.ExtraType, item.ExtraTitle, item.YouTubeID, reason); err != nil {
+		TrailarrLog(ERROR, "QUEUE", "Failed to mark extra as rejected in store: %v", err)
+	}
+	setDownloadStatus(item.YouTubeID, &DownloadStatus{Status: "rejected", UpdatedAt: time.Now(), Error: reason})
+	_ = removeDownloadQueueItem(ctx, item.YouTubeID, item.QueuedAt)
+	item.Status = "rejected"
+	item.Reason = reason
+	BroadcastDownloadQueueChanges([]DownloadQueueItem{item})
+	return true
+}
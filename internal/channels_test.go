@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	studioChannel   = "UCjmJDM5pRKbUlVIzDYYWb6g"
+	reuploadChannel = "UCabcdefghijklmnopqrstuv"
+)
+
+// reuploadInfo reports reuploadChannel as the uploader for `yt-dlp -J`.
+func reuploadInfo(string) ([]byte, error) {
+	return []byte(`{"id":"x","channel":"Reuploads","channel_id":"` + reuploadChannel + `"}`), nil
+}
+
+func TestSaveChannelsConfigHandlerValidates(t *testing.T) {
+	CreateTempConfig(t)
+	r := NewTestRouter()
+	r.POST("/api/settings/channels", SaveChannelsConfigHandler)
+	if w := DoRequest(r, "POST", "/api/settings/channels", []byte(`{"block":["Warner Bros"]}`)); w.Code != 400 {
+		t.Fatalf("expected 400 for invalid channel id, got %d", w.Code)
+	}
+	if w := DoRequest(r, "POST", "/api/settings/channels", []byte(`{"allow":["`+studioChannel+`"],"block":["`+studioChannel+`"]}`)); w.Code != 400 {
+		t.Fatalf("expected 400 for channel in both lists, got %d", w.Code)
+	}
+	w := DoRequest(r, "POST", "/api/settings/channels", []byte(`{"allow":[" `+studioChannel+`",""],"block":["`+reuploadChannel+`","`+reuploadChannel+`"],"allowOnly":true}`))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	cfg, _ := GetChannelsConfig()
+	if !reflect.DeepEqual(cfg.Allow, []string{studioChannel}) || !reflect.DeepEqual(cfg.Block, []string{reuploadChannel}) || !cfg.AllowOnly {
+		t.Fatalf("unexpected saved config: %+v", cfg)
+	}
+}
+
+func TestChannelsRejectReason(t *testing.T) {
+	cfg := ChannelsConfig{Allow: []string{studioChannel}, Block: []string{reuploadChannel}}
+	if r := cfg.rejectReason(reuploadChannel, "Reuploads"); r != "Blocked channel: Reuploads ("+reuploadChannel+") is blocklisted" {
+		t.Fatalf("unexpected reason %q", r)
+	}
+	if r := cfg.rejectReason("UCzzzzzzzzzzzzzzzzzzzzzz", "Other"); r != "" {
+		t.Fatalf("expected other channels allowed without allowOnly, got %q", r)
+	}
+	cfg.AllowOnly = true
+	if r := cfg.rejectReason("UCzzzzzzzzzzzzzzzzzzzzzz", "Other"); !strings.HasSuffix(r, "is not allowlisted") {
+		t.Fatalf("expected allowOnly rejection, got %q", r)
+	}
+	if r := cfg.rejectReason(studioChannel, "Studio"); r != "" {
+		t.Fatalf("expected allowed channel, got %q", r)
+	}
+}
+
+func TestParseYtDlpLineAppliesChannelLists(t *testing.T) {
+	target := searchTarget{Titles: []string{"Alien"}, Channels: ChannelsConfig{Allow: []string{studioChannel}, Block: []string{reuploadChannel}}}
+	results := []gin.H{}
+	seen := map[string]bool{}
+	for _, c := range []searchCandidate{
+		{ID: "reup", Title: "Alien Official Trailer", ChannelID: reuploadChannel},
+		{ID: "other", Title: "Alien Official Trailer", ChannelID: "UCzzzzzzzzzzzzzzzzzzzzzz"},
+		{ID: "studio", Title: "Alien Official Trailer", ChannelID: studioChannel},
+	} {
+		b, _ := json.Marshal(c)
+		parseYtDlpLine(b, seen, &results, target)
+	}
+	sortSearchResults(results)
+	if len(results) != 2 {
+		t.Fatalf("expected blocked result dropped, got %+v", results)
+	}
+	if id := results[0]["id"].(gin.H)["videoId"]; id != "studio" {
+		t.Fatalf("expected allowed channel first, got %v", id)
+	}
+	if !strings.Contains(strings.Join(results[0]["reasons"].([]string), "|"), "+30 allowed channel") {
+		t.Fatalf("expected allowlist boost, got %v", results[0]["reasons"])
+	}
+}
+
+func TestProcessQueueItemRejectsBlockedChannel(t *testing.T) {
+	CreateTempConfig(t)
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+	if err := SaveChannelsConfig(ChannelsConfig{Block: []string{reuploadChannel}}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	runner := &fakeRunner{videoInfo: reuploadInfo}
+	oldRunner := ytDlpRunner
+	ytDlpRunner = runner
+	defer func() { ytDlpRunner = oldRunner }()
+
+	pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 6161, ExtraType: "Trailers", ExtraTitle: "Reupload", YouTubeID: "blocked1"})
+	runQueuedItemForTest(t)
+
+	if len(runner.downloadArgs()) != 0 {
+		t.Fatalf("blocked video must not be downloaded")
+	}
+	if q := GetCurrentDownloadQueue(); len(q) != 0 {
+		t.Fatalf("expected blocked item removed from queue, got %+v", q)
+	}
+	e, _ := GetExtraByYoutubeId(ctx, "blocked1", MediaTypeMovie, 6161)
+	if e == nil || e.Status != "rejected" || !strings.HasPrefix(e.Reason, blockedChannelReasonPrefix) {
+		t.Fatalf("expected extra rejected for blocked channel, got %+v", e)
+	}
+}
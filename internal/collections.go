@@ -0,0 +1,442 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Where collection extras are written.
+const (
+	// CollectionPlacementFolder writes each extra once to a folder of the collection
+	CollectionPlacementFolder = "folder"
+	// CollectionPlacementMembers writes each extra into the Other folder of
+	// every movie of the collection
+	CollectionPlacementMembers = "members"
+)
+
+// ExtraSourceCollection is the source of the copies of a collection extra
+// stored for the movies of the collection.
+const ExtraSourceCollection = "collection"
+
+// CollectionsConfig controls the extras of TMDB movie collections (franchises).
+type CollectionsConfig struct {
+	// Enabled searches a franchise trailer for every collection with movies in Radarr
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Placement is CollectionPlacementFolder or CollectionPlacementMembers
+	Placement string `yaml:"placement" json:"placement"`
+	// Path is the folder holding a folder per collection; empty uses
+	// "collections" under TrailarrRoot
+	Path string `yaml:"path" json:"path"`
+}
+
+func DefaultCollectionsConfig() CollectionsConfig {
+	return CollectionsConfig{Enabled: false, Placement: CollectionPlacementFolder, Path: ""}
+}
+
+// GetCollectionsConfig loads the collections config from config.yml
+func GetCollectionsConfig() (CollectionsConfig, error) {
+	cfg := DefaultCollectionsConfig()
+	if err := decodeConfigSection("collections", &cfg); err != nil {
+		return DefaultCollectionsConfig(), err
+	}
+	return cfg, nil
+}
+
+// SaveCollectionsConfig saves the collections config to config.yml
+func SaveCollectionsConfig(cfg CollectionsConfig) error {
+	return saveConfigSection("collections", cfg)
+}
+
+func ensureCollectionsDefaults(config map[string]interface{}) bool {
+	if _, ok := config["collections"].(map[string]interface{}); ok {
+		return false
+	}
+	config["collections"] = DefaultCollectionsConfig()
+	return true
+}
+
+// Handler to get collections config
+func GetCollectionsConfigHandler(c *gin.Context) {
+	cfg, _ := GetCollectionsConfig()
+	respondJSON(c, http.StatusOK, cfg)
+}
+
+// Handler to save collections config
+func SaveCollectionsConfigHandler(c *gin.Context) {
+	var req CollectionsConfig
+	if err := c.BindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	if req.Placement == "" {
+		req.Placement = CollectionPlacementFolder
+	}
+	if req.Placement != CollectionPlacementFolder && req.Placement != CollectionPlacementMembers {
+		respondError(c, http.StatusBadRequest, "invalid placement: "+req.Placement)
+		return
+	}
+	req.Path = strings.TrimSpace(req.Path)
+	if req.Path != "" && !filepath.IsAbs(req.Path) {
+		respondError(c, http.StatusBadRequest, "collections path must be absolute")
+		return
+	}
+	if err := SaveCollectionsConfig(req); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
+}
+
+// root returns the folder holding the collection folders.
+func (cfg CollectionsConfig) root() string {
+	if cfg.Path != "" {
+		return cfg.Path
+	}
+	return filepath.Join(TrailarrRoot, "collections")
+}
+
+// MovieCollection is a TMDB collection with movies in Radarr.
+type MovieCollection struct {
+	TmdbId   int    `json:"tmdbId"`
+	Title    string `json:"title"`
+	MovieIds []int  `json:"movieIds"`
+}
+
+// movieCollectionOf returns the TMDB collection Radarr reports for a movie,
+// or 0 if the movie is not in one.
+func movieCollectionOf(movie map[string]interface{}) (int, string) {
+	collection, _ := movie["collection"].(map[string]interface{})
+	id, _ := parseMediaID(collection["tmdbId"])
+	title, _ := collection["title"].(string)
+	if title == "" {
+		// Radarr v3 names it "name"
+		title, _ = collection["name"].(string)
+	}
+	return id, title
+}
+
+// loadMovieCollections groups the cached Radarr movies by TMDB collection,
+// ordered by title.
+func loadMovieCollections() ([]MovieCollection, error) {
+	items, err := loadCache(MoviesStoreKey)
+	if err != nil {
+		return nil, err
+	}
+	byId := map[int]*MovieCollection{}
+	for _, item := range items {
+		collectionId, title := movieCollectionOf(item)
+		movieId, ok := parseMediaID(item["id"])
+		if collectionId <= 0 || !ok {
+			continue
+		}
+		col, ok := byId[collectionId]
+		if !ok {
+			col = &MovieCollection{TmdbId: collectionId, Title: title}
+			byId[collectionId] = col
+		}
+		col.MovieIds = append(col.MovieIds, movieId)
+	}
+	out := make([]MovieCollection, 0, len(byId))
+	for _, col := range byId {
+		if col.Title == "" {
+			col.Title = fmt.Sprintf("Collection %d", col.TmdbId)
+		}
+		sort.Ints(col.MovieIds)
+		out = append(out, *col)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Title < out[j].Title })
+	return out, nil
+}
+
+// findMovieCollection returns the collection with the given TMDB ID.
+func findMovieCollection(collectionId int) (MovieCollection, error) {
+	collections, err := loadMovieCollections()
+	if err != nil {
+		return MovieCollection{}, err
+	}
+	for _, col := range collections {
+		if col.TmdbId == collectionId {
+			return col, nil
+		}
+	}
+	return MovieCollection{}, fmt.Errorf("collection %d not found", collectionId)
+}
+
+// collectionMember is a movie of a collection with its folder.
+type collectionMember struct {
+	MovieId int
+	Path    string
+}
+
+// collectionMembers returns the movies of a collection that have a folder.
+func collectionMembers(col MovieCollection) []collectionMember {
+	mappings := getPathMappingsSafe(MediaTypeMovie)
+	members := make([]collectionMember, 0, len(col.MovieIds))
+	for _, id := range col.MovieIds {
+		_, title := resolveCacheAndTitle(MediaTypeMovie, id)
+		if p := deriveBasePath(findMappedMediaPath(MoviesStoreKey, mappings, id), mappings, title); p != "" {
+			members = append(members, collectionMember{MovieId: id, Path: p})
+		}
+	}
+	return members
+}
+
+// collectionMediaPath returns the folder the extras of a collection are
+// written to: the collection folder, or the folder of its first movie.
+func collectionMediaPath(cfg CollectionsConfig, col MovieCollection) (string, []collectionMember, error) {
+	if cfg.Placement != CollectionPlacementMembers {
+		return filepath.Join(cfg.root(), SanitizeFilename(col.Title)), nil, nil
+	}
+	members := collectionMembers(col)
+	if len(members) == 0 {
+		return "", nil, fmt.Errorf("no movie folder found for collection %d", col.TmdbId)
+	}
+	return members[0].Path, members[1:], nil
+}
+
+// collectionExtrasPath returns the folder the extras of a collection are written to.
+func collectionExtrasPath(collectionId int) (string, error) {
+	col, err := findMovieCollection(collectionId)
+	if err != nil {
+		return "", err
+	}
+	cfg, _ := GetCollectionsConfig()
+	mediaPath, _, err := collectionMediaPath(cfg, col)
+	return mediaPath, err
+}
+
+// prepareCollectionDownloadInfo is prepareDownloadInfo for collection extras:
+// the extra goes to the collection folder, or to the Other folder of the
+// first movie of the collection and is copied to the other movies afterwards.
+func prepareCollectionDownloadInfo(collectionId int, extraType, extraTitle, youtubeID string) (*downloadInfo, error) {
+	col, err := findMovieCollection(collectionId)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _ := GetCollectionsConfig()
+	naming, _ := GetNamingConfig()
+	values := namingValues{
+		MediaTitle: col.Title,
+		ExtraType:  canonicalizeExtraType(extraType),
+		ExtraTitle: extraTitle,
+		YouTubeID:  youtubeID,
+	}
+	basePath, members, err := collectionMediaPath(cfg, col)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Placement == CollectionPlacementMembers {
+		values.ExtraType = string(Other)
+	}
+	outDir, outFile := resolveExtraOutput(naming, basePath, values)
+
+	safeTitle := sanitizeFileName(extraTitle)
+	tempDir, tempFile, err := createTempPaths(safeTitle, "mkv")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir for yt-dlp: %w", err)
+	}
+	return &downloadInfo{
+		MediaType:         MediaTypeCollection,
+		MediaId:           collectionId,
+		MediaTitle:        col.Title,
+		MediaPath:         basePath,
+		OutDir:            outDir,
+		OutFile:           outFile,
+		TempDir:           tempDir,
+		TempFile:          tempFile,
+		YouTubeID:         youtubeID,
+		ExtraType:         extraType,
+		ExtraTitle:        extraTitle,
+		SafeTitle:         safeTitle,
+		Naming:            naming,
+		Values:            values,
+		CollectionMembers: members,
+	}, nil
+}
+
+// copyToCollectionMembers copies a collection extra downloaded into the
+// folder of one movie of the collection to the folders of the other movies.
+// Each copy is stored as an extra of its movie with a sidecar, so renames and
+// the cleanup treat it like any other extra. A previous copy under another
+// name, eg. before an upgrade changed the resolution in it, is removed.
+func copyToCollectionMembers(info *downloadInfo, youtubeId string) {
+	for _, member := range info.CollectionMembers {
+		previous, _ := GetExtraByYoutubeId(context.Background(), youtubeId, MediaTypeMovie, member.MovieId)
+		outDir, outFile := resolveExtraOutput(info.Naming, member.Path, info.Values)
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			TrailarrLog(WARN, "Collections", "Failed to create %s: %v", outDir, err)
+			continue
+		}
+		if err := copyFileAtomic(info.OutFile, outFile); err != nil {
+			TrailarrLog(WARN, "Collections", "Failed to copy %s to %s: %v", info.OutFile, outFile, err)
+			continue
+		}
+		if previous != nil && previous.FileName != "" && previous.FileName != outFile {
+			removeExtraFile(previous.FileName)
+		}
+		persistExtraEntry(ExtrasEntry{
+			MediaType:  MediaTypeMovie,
+			MediaId:    member.MovieId,
+			ExtraTitle: info.ExtraTitle,
+			ExtraType:  info.Values.ExtraType,
+			FileName:   outFile,
+			YoutubeId:  youtubeId,
+			Status:     "downloaded",
+			Language:   info.Values.Language,
+			Source:     ExtraSourceCollection,
+			SourceURL:  info.SourceURL,
+			SourceSite: info.SourceSite,
+			Video:      info.Video,
+		})
+		meta := NewExtraDownloadMetadata(info, youtubeId, "downloaded")
+		meta.MediaType, meta.MediaId = MediaTypeMovie, member.MovieId
+		meta.MediaTitle = getMediaTitleFromCache(MediaTypeMovie, member.MovieId)
+		meta.ExtraType, meta.FileName = info.Values.ExtraType, outFile
+		writeMetaFile(meta, outFile)
+		scheduleLibraryRefresh(member.Path)
+	}
+}
+
+// copyFileAtomic copies from to a temporary file next to to and renames that
+// into place, so to never appears half-written.
+func copyFileAtomic(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	partial := to + ".partial"
+	out, err := os.Create(partial)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		_ = os.Remove(partial)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(partial)
+		return err
+	}
+	return os.Rename(partial, to)
+}
+
+// deleteCollectionCopies removes the copies of a collection extra from the
+// movies of the collection, with their sidecars and store entries.
+func deleteCollectionCopies(ctx context.Context, collectionId int, youtubeId string) {
+	col, err := findMovieCollection(collectionId)
+	if err != nil {
+		return
+	}
+	for _, member := range collectionMembers(col) {
+		entry, err := GetExtraByYoutubeId(ctx, youtubeId, MediaTypeMovie, member.MovieId)
+		if err != nil || entry == nil || entry.Source != ExtraSourceCollection {
+			continue
+		}
+		if entry.FileName != "" {
+			_ = os.Remove(entry.FileName)
+			_ = os.Remove(strings.TrimSuffix(entry.FileName, ".mkv") + mkvJSONSuffix)
+			scheduleLibraryRefresh(member.Path)
+		}
+		if err := RemoveExtra(ctx, youtubeId, MediaTypeMovie, member.MovieId); err != nil {
+			TrailarrLog(WARN, "Collections", "Failed to remove copy of %s from movie %d: %v", youtubeId, member.MovieId, err)
+		}
+	}
+}
+
+// collectionHasTrailer reports whether a trailer of the collection was downloaded.
+func collectionHasTrailer(collectionId int) bool {
+	extras, _ := SearchExtras(MediaTypeCollection, collectionId)
+	for _, e := range extras {
+		if e.Status == "downloaded" && canonicalizeExtraType(e.ExtraType) == string(Trailers) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchCollectionTrailer searches YouTube for the franchise trailer of a
+// collection; TMDB lists no videos for collections. It returns the best
+// confident match, or nil if there is none or the last search found nothing
+// and is backed off.
+func searchCollectionTrailer(col MovieCollection) *Extra {
+	if searchBackedOff(searchKindTrailer, MediaTypeCollection, col.TmdbId) {
+		return nil
+	}
+	title := strings.TrimSpace(strings.TrimSuffix(col.Title, " Collection"))
+	target := newSearchTarget(MediaTypeCollection, col.TmdbId, title, "")
+	results, _ := searchYtDlpForTerms(searchFallbackTerms(target), searchFallbackResults, target)
+
+	rejected := map[string]struct{}{}
+	for _, r := range GetRejectedExtrasForMedia(MediaTypeCollection, col.TmdbId) {
+		rejected[r.YoutubeId] = struct{}{}
+	}
+	videoId, score := bestSearchFallback(results, rejected)
+	if videoId == "" {
+		TrailarrLog(INFO, "Tasks", "Found no confident trailer for collection %d (%q) in %d results", col.TmdbId, col.Title, len(results))
+		recordSearchMiss(searchKindTrailer, MediaTypeCollection, col.TmdbId)
+		return nil
+	}
+	clearSearchMiss(searchKindTrailer, MediaTypeCollection, col.TmdbId)
+	TrailarrLog(INFO, "Tasks", "Picked %s (score %d) as trailer for collection %d (%q)", videoId, score, col.TmdbId, col.Title)
+	return &Extra{
+		ExtraType:  string(Trailers),
+		ExtraTitle: title + " Trailer",
+		YoutubeId:  videoId,
+		Status:     "missing",
+		Source:     ExtraSourceSearch,
+	}
+}
+
+// processCollectionExtras queues a franchise trailer for every collection
+// without one, when collection extras are enabled.
+func processCollectionExtras(ctx context.Context) {
+	cfg, _ := GetCollectionsConfig()
+	if !cfg.Enabled {
+		return
+	}
+	collections, err := loadMovieCollections()
+	if err != nil {
+		TrailarrLog(WARN, "Tasks", "processCollectionExtras: %v", err)
+		return
+	}
+	for _, col := range collections {
+		if ctx != nil && ctx.Err() != nil {
+			return
+		}
+		if collectionHasTrailer(col.TmdbId) {
+			continue
+		}
+		extra := searchCollectionTrailer(col)
+		if extra == nil {
+			continue
+		}
+		if err := handleTypeFilteredExtraDownload(MediaTypeCollection, col.TmdbId, *extra); err != nil {
+			TrailarrLog(WARN, "Tasks", "Failed to queue trailer for collection %d: %v", col.TmdbId, err)
+		}
+	}
+}
+
+// GetCollectionsHandler lists the collections of the Radarr movies with their extras.
+func GetCollectionsHandler(c *gin.Context) {
+	collections, err := loadMovieCollections()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := make([]gin.H, 0, len(collections))
+	for _, col := range collections {
+		extras, _ := SearchExtras(MediaTypeCollection, col.TmdbId)
+		out = append(out, gin.H{"tmdbId": col.TmdbId, "title": col.Title, "movieIds": col.MovieIds, "extras": extras})
+	}
+	respondJSON(c, http.StatusOK, gin.H{"collections": out})
+}
@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupCollection caches three Radarr movies, two of them in the Alien
+// collection, with their folders mapped into a temp library.
+func setupCollection(t *testing.T, collectionsYaml string) string {
+	t.Helper()
+	CreateTempConfig(t)
+	library := t.TempDir()
+	WriteConfig(t, []byte("radarr:\n  pathMappings:\n    - from: /movies\n      to: "+library+"\n"+collectionsYaml))
+	alien := map[string]interface{}{"title": "Alien Collection", "tmdbId": 8091}
+	movies := []map[string]interface{}{
+		{"id": 12, "title": "Aliens", "path": "/movies/Aliens (1986)", "collection": alien},
+		{"id": 11, "title": "Alien", "path": "/movies/Alien (1979)", "collection": alien},
+		{"id": 13, "title": "Heat", "path": "/movies/Heat (1995)"},
+	}
+	if err := SaveMediaToStore(MoviesStoreKey, movies); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+	return library
+}
+
+// downloadCollectionExtra runs a queued collection extra through the download worker.
+func downloadCollectionExtra(t *testing.T, youtubeId string) {
+	t.Helper()
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	t.Cleanup(func() { _ = GetStoreClient().Del(ctx, DownloadQueue) })
+	oldRunner := ytDlpRunner
+	ytDlpRunner = &fakeRunner{}
+	t.Cleanup(func() { ytDlpRunner = oldRunner })
+
+	AddToDownloadQueue(DownloadQueueItem{MediaType: MediaTypeCollection, MediaId: 8091, ExtraType: "Trailers", ExtraTitle: "Alien Trailer", YouTubeID: youtubeId, QueuedAt: time.Now()}, QueueSourceAPI)
+	if item := runQueuedItemForTest(t); item.MediaTitle != "Alien Collection" {
+		t.Fatalf("expected the collection title on the queue item, got %q", item.MediaTitle)
+	}
+}
+
+func TestLoadMovieCollections(t *testing.T) {
+	setupCollection(t, "")
+	collections, err := loadMovieCollections()
+	if err != nil {
+		t.Fatalf("load collections: %v", err)
+	}
+	want := []MovieCollection{{TmdbId: 8091, Title: "Alien Collection", MovieIds: []int{11, 12}}}
+	if !reflect.DeepEqual(collections, want) {
+		t.Fatalf("expected %+v, got %+v", want, collections)
+	}
+
+	r := NewTestRouter()
+	r.GET("/api/collections", GetCollectionsHandler)
+	w := DoRequest(r, "GET", "/api/collections", nil)
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"tmdbId":8091`) || strings.Contains(w.Body.String(), "Heat") {
+		t.Fatalf("unexpected collections response %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCollectionExtraWrittenOnceToCollectionsFolder(t *testing.T) {
+	root := t.TempDir()
+	setupCollection(t, "collections:\n  enabled: true\n  placement: folder\n  path: "+root+"\n")
+	downloadCollectionExtra(t, "DDDDDDDDDDD")
+
+	file := filepath.Join(root, "Alien Collection", "Trailers", "Alien Trailer.mkv")
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("expected the extra in the collection folder: %v", err)
+	}
+	e, _ := GetExtraByYoutubeId(context.Background(), "DDDDDDDDDDD", MediaTypeCollection, 8091)
+	if e == nil || e.Status != "downloaded" || e.FileName != file || e.MediaTitle != "Alien Collection" {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if e, _ := GetExtraByYoutubeId(context.Background(), "DDDDDDDDDDD", MediaTypeMovie, 11); e != nil {
+		t.Fatalf("collection extras must not be stored for member movies, got %+v", e)
+	}
+	if !collectionHasTrailer(8091) {
+		t.Fatalf("expected the collection to have its trailer")
+	}
+}
+
+func TestCollectionExtraCopiedToMembersOtherFolder(t *testing.T) {
+	library := setupCollection(t, "collections:\n  enabled: true\n  placement: members\n")
+	downloadCollectionExtra(t, "EEEEEEEEEEE")
+
+	for _, movie := range []string{"Alien (1979)", "Aliens (1986)"} {
+		if _, err := os.Stat(filepath.Join(library, movie, "Other", "Alien Trailer.mkv.json")); err != nil {
+			t.Fatalf("expected the extra with its sidecar in the Other folder of %s: %v", movie, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(library, "Heat (1995)")); !os.IsNotExist(err) {
+		t.Fatalf("expected no extra for movies outside the collection: %v", err)
+	}
+	ctx := context.Background()
+	copied := filepath.Join(library, "Aliens (1986)", "Other", "Alien Trailer.mkv")
+	if e, _ := GetExtraByYoutubeId(ctx, "EEEEEEEEEEE", MediaTypeMovie, 12); e == nil || e.FileName != copied || e.Source != ExtraSourceCollection {
+		t.Fatalf("expected the copy stored for the member movie, got %+v", e)
+	}
+
+	r := NewTestRouter()
+	r.DELETE("/api/extras", deleteExtraHandler)
+	if w := DoRequest(r, "DELETE", "/api/extras", []byte(`{"mediaType":"collection","mediaId":8091,"youtubeId":"EEEEEEEEEEE"}`)); w.Code != 200 {
+		t.Fatalf("delete failed: %d %s", w.Code, w.Body.String())
+	}
+	for _, movie := range []string{"Alien (1979)", "Aliens (1986)"} {
+		if _, err := os.Stat(filepath.Join(library, movie, "Other", "Alien Trailer.mkv")); !os.IsNotExist(err) {
+			t.Fatalf("expected the extra deleted from %s: %v", movie, err)
+		}
+	}
+	if e, _ := GetExtraByYoutubeId(ctx, "EEEEEEEEEEE", MediaTypeMovie, 12); e != nil {
+		t.Fatalf("expected the copy removed from the store, got %+v", e)
+	}
+}
+
+func TestCollectionExtraUpgradeReplacesMemberCopies(t *testing.T) {
+	library := setupCollection(t, "collections:\n  enabled: true\n  placement: members\n")
+	ctx := context.Background()
+	_ = GetStoreClient().Del(ctx, ExtrasStoreKey)
+	downloadCollectionExtra(t, "FFFFFFFFFFF")
+	entries, _ := GetAllExtras(ctx)
+	for _, e := range entries {
+		if e.YoutubeId != "FFFFFFFFFFF" {
+			continue
+		}
+		e.Video = &VideoInfo{Height: 480, VideoCodec: "vp9"}
+		if e.Source == ExtraSourceCollection {
+			_ = os.WriteFile(e.FileName, []byte("old"), 0o644)
+		}
+		if err := AddOrUpdateExtra(ctx, e); err != nil {
+			t.Fatalf("update extra: %v", err)
+		}
+	}
+	runner := &upgradeRunner{}
+	oldRunner, oldProbe := ytDlpRunner, probeVideo
+	ytDlpRunner = runner
+	probeVideo = func(string) (*VideoInfo, error) {
+		return &VideoInfo{Duration: 120, Width: 1920, Height: 1080, VideoCodec: "vp9"}, nil
+	}
+	defer func() { ytDlpRunner, probeVideo = oldRunner, oldProbe }()
+
+	upgrades, err := findExtraUpgrades(ctx, 1080)
+	if err != nil || len(upgrades) != 1 || upgrades[0].MediaType != MediaTypeCollection {
+		t.Fatalf("expected only the collection extra to upgrade, got %+v %v", upgrades, err)
+	}
+	if err := upgradeExtra(ctx, upgrades[0]); err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	for _, movie := range []string{"Alien (1979)", "Aliens (1986)"} {
+		if b, _ := os.ReadFile(filepath.Join(library, movie, "Other", "Alien Trailer.mkv")); string(b) != "dummy" {
+			t.Fatalf("expected the upgraded extra in %s, got %q", movie, b)
+		}
+	}
+	if e, _ := GetExtraByYoutubeId(ctx, "FFFFFFFFFFF", MediaTypeMovie, 12); e == nil || e.Video == nil || e.Video.Height != 1080 {
+		t.Fatalf("expected the copy recorded at 1080p, got %+v", e)
+	}
+}
+
+func TestSaveCollectionsConfigValidates(t *testing.T) {
+	CreateTempConfig(t)
+	r := NewTestRouter()
+	r.POST("/api/settings/collections", SaveCollectionsConfigHandler)
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"enabled":true,"placement":"everywhere"}`, 400},
+		{`{"enabled":true,"path":"relative/dir"}`, 400},
+		{`{"enabled":true,"placement":"members"}`, 200},
+		{`{"enabled":true,"path":" /data/collections "}`, 200},
+	} {
+		if w := DoRequest(r, "POST", "/api/settings/collections", []byte(tc.body)); w.Code != tc.code {
+			t.Fatalf("%s: expected %d, got %d %s", tc.body, tc.code, w.Code, w.Body.String())
+		}
+	}
+	cfg, _ := GetCollectionsConfig()
+	if want := (CollectionsConfig{Enabled: true, Placement: CollectionPlacementFolder, Path: "/data/collections"}); cfg != want {
+		t.Fatalf("expected %+v saved, got %+v", want, cfg)
+	}
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestManualDownloadJumpsAheadOfTaskBackfill(t *testing.T) {
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+
+	for _, id := range []string{"task1", "task2"} {
+		if err := enqueueExtraDownload(MediaTypeMovie, 1, Extra{ExtraType: "Trailers", ExtraTitle: id, YoutubeId: id}, QueueSourceTask); err != nil {
+			t.Fatalf("enqueue %s: %v", id, err)
+		}
+	}
+	if err := enqueueExtraDownload(MediaTypeMovie, 2, Extra{ExtraType: "Trailers", ExtraTitle: "fresh", YoutubeId: "newmedia1"}, QueueSourceNewMedia); err != nil {
+		t.Fatalf("enqueue new media: %v", err)
+	}
+	AddToDownloadQueue(DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 3, ExtraType: "Trailers", ExtraTitle: "manual", YouTubeID: "manual1"}, QueueSourceAPI)
+
+	want := []string{"manual1", "newmedia1", "task1", "task2"}
+	for _, id := range want {
+		_, item, ok := claimNextQueuedItem(ctx, 0)
+		if !ok {
+			t.Fatalf("expected to claim %s", id)
+		}
+		finishActiveDownload(item)
+		releaseHostSlot(downloadHost(item))
+		if item.YouTubeID != id {
+			t.Fatalf("expected %s next, got %s (priority %d)", id, item.YouTubeID, item.Priority)
+		}
+	}
+}
+
+func TestPickQueuedItemSamePriorityIsFIFO(t *testing.T) {
+	now := time.Now()
+	older := DownloadQueueItem{YouTubeID: "older", Status: "queued", QueuedAt: now.Add(-time.Minute)}
+	newer := DownloadQueueItem{YouTubeID: "newer", Status: "queued", QueuedAt: now}
+	queue := []string{mustMarshalQueueItem(t, newer), mustMarshalQueueItem(t, older)}
+
+	idx, item, ok := pickQueuedItem(queue, func(DownloadQueueItem) bool { return true })
+	if !ok || idx != 1 || item.YouTubeID != "older" {
+		t.Fatalf("expected oldest item first, got idx=%d %+v", idx, item)
+	}
+
+	// A higher-priority item is skipped when it can't start, leaving the next best
+	newer.Priority = QueuePriorityManual
+	queue = []string{mustMarshalQueueItem(t, newer), mustMarshalQueueItem(t, older)}
+	_, item, ok = pickQueuedItem(queue, func(q DownloadQueueItem) bool { return q.YouTubeID != "newer" })
+	if !ok || item.YouTubeID != "older" {
+		t.Fatalf("expected fallback to startable item, got %+v", item)
+	}
+}
+
+func TestQueuePriorityForSource(t *testing.T) {
+	if queuePriorityForSource(QueueSourceAPI) <= queuePriorityForSource(QueueSourceNewMedia) {
+		t.Fatalf("manual downloads must outrank new media")
+	}
+	if queuePriorityForSource(QueueSourceNewMedia) <= queuePriorityForSource(QueueSourceTask) {
+		t.Fatalf("new media must outrank task backfill")
+	}
+}
+
+func mustMarshalQueueItem(t *testing.T, item DownloadQueueItem) string {
+	t.Helper()
+	b, err := json.Marshal(item)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ytDlpProgressPrefix marks the progress lines requested via --progress-template.
+const ytDlpProgressPrefix = "trailarr-progress:"
+
+// DownloadProgress is the live progress of a running download.
+type DownloadProgress struct {
+	Percent         float64 `json:"percent"`
+	Speed           float64 `json:"speed,omitempty"` // bytes per second
+	ETA             int     `json:"eta,omitempty"`   // seconds
+	DownloadedBytes int64   `json:"downloadedBytes,omitempty"`
+	TotalBytes      int64   `json:"totalBytes,omitempty"`
+}
+
+// ytDlpProgressTemplateArgs returns the yt-dlp flags that make it print one
+// JSON progress line per update.
+func ytDlpProgressTemplateArgs() []string {
+	return []string{"--newline", "--progress-template", "download:" + ytDlpProgressPrefix + "%(progress)j"}
+}
+
+// parseYtDlpProgressLine parses a yt-dlp progress line, either the JSON
+// produced by our --progress-template or yt-dlp's default "[download]" line.
+func parseYtDlpProgressLine(line string) (DownloadProgress, bool) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, ytDlpProgressPrefix) {
+		return parseYtDlpProgressJSON(strings.TrimPrefix(line, ytDlpProgressPrefix))
+	}
+	return parseYtDlpProgressText(line)
+}
+
+func parseYtDlpProgressJSON(data string) (DownloadProgress, bool) {
+	var raw struct {
+		DownloadedBytes    float64 `json:"downloaded_bytes"`
+		TotalBytes         float64 `json:"total_bytes"`
+		TotalBytesEstimate float64 `json:"total_bytes_estimate"`
+		Speed              float64 `json:"speed"`
+		ETA                float64 `json:"eta"`
+		PercentStr         string  `json:"_percent_str"`
+	}
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return DownloadProgress{}, false
+	}
+	total := raw.TotalBytes
+	if total <= 0 {
+		total = raw.TotalBytesEstimate
+	}
+	p := DownloadProgress{
+		Speed:           raw.Speed,
+		ETA:             int(raw.ETA),
+		DownloadedBytes: int64(raw.DownloadedBytes),
+		TotalBytes:      int64(total),
+	}
+	if total > 0 {
+		p.Percent = raw.DownloadedBytes / total * 100
+	} else if v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(raw.PercentStr), "%")), 64); err == nil {
+		p.Percent = v
+	}
+	return p, true
+}
+
+// ytDlpProgressTextRe matches eg. "[download]  42.0% of ~ 10.00MiB at  1.50MiB/s ETA 00:05".
+var ytDlpProgressTextRe = regexp.MustCompile(`^\[download\]\s+([\d.]+)%\s+of\s+~?\s*([\d.]+\s*[KMGT]?i?B)(?:\s+at\s+([\d.]+\s*[KMGT]?i?B)/s)?(?:\s+ETA\s+([\d:]+))?`)
+
+func parseYtDlpProgressText(line string) (DownloadProgress, bool) {
+	m := ytDlpProgressTextRe.FindStringSubmatch(line)
+	if m == nil {
+		return DownloadProgress{}, false
+	}
+	percent, _ := strconv.ParseFloat(m[1], 64)
+	total := parseByteSize(m[2])
+	return DownloadProgress{
+		Percent:         percent,
+		Speed:           float64(parseByteSize(m[3])),
+		ETA:             parseClockSeconds(m[4]),
+		DownloadedBytes: int64(float64(total) * percent / 100),
+		TotalBytes:      total,
+	}, true
+}
+
+// parseByteSize converts sizes like "10.5MiB" or "300KiB" to bytes.
+func parseByteSize(s string) int64 {
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	units := []struct {
+		suffix string
+		mult   float64
+	}{{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}, {"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3}, {"B", 1}}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(s, u.suffix), 64)
+			if err != nil {
+				return 0
+			}
+			return int64(v * u.mult)
+		}
+	}
+	return 0
+}
+
+// parseClockSeconds converts "HH:MM:SS" or "MM:SS" to seconds.
+func parseClockSeconds(s string) int {
+	if s == "" {
+		return 0
+	}
+	total := 0
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+		total = total*60 + n
+	}
+	return total
+}
+
+type progressReporterKey struct{}
+
+// withProgressReporter attaches a progress callback to ctx; performDownload
+// calls it for every progress line yt-dlp prints.
+func withProgressReporter(ctx context.Context, report func(DownloadProgress)) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, report)
+}
+
+// progressReporterFrom returns the callback attached to ctx, or a no-op.
+func progressReporterFrom(ctx context.Context) func(DownloadProgress) {
+	if report, ok := ctx.Value(progressReporterKey{}).(func(DownloadProgress)); ok {
+		return report
+	}
+	return func(DownloadProgress) {}
+}
+
+// newQueueProgressReporter returns a callback that records progress for a queue
+// item in memory and in the store and broadcasts it, at most once per
+// DownloadProgressInterval (completion is always sent).
+func newQueueProgressReporter(ctx context.Context, item DownloadQueueItem) func(DownloadProgress) {
+	var mu sync.Mutex
+	var last time.Time
+	return func(p DownloadProgress) {
+		mu.Lock()
+		if time.Since(last) < DownloadProgressInterval && p.Percent < 100 {
+			mu.Unlock()
+			return
+		}
+		last = time.Now()
+		mu.Unlock()
+
+		setDownloadStatus(item.YouTubeID, &DownloadStatus{Status: "downloading", UpdatedAt: time.Now(), Progress: &p})
+		updated, err := updateDownloadQueueItem(ctx, item.YouTubeID, item.QueuedAt, func(q *DownloadQueueItem) {
+			q.Progress = &p
+		})
+		if err != nil {
+			return
+		}
+		BroadcastDownloadQueueChanges([]DownloadQueueItem{updated})
+	}
+}
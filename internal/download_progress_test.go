@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseYtDlpProgressLine(t *testing.T) {
+	p, ok := parseYtDlpProgressLine(ytDlpProgressPrefix + `{"status":"downloading","downloaded_bytes":250,"total_bytes":1000,"speed":125.5,"eta":6}`)
+	if !ok || p.Percent != 25 || p.DownloadedBytes != 250 || p.TotalBytes != 1000 || p.Speed != 125.5 || p.ETA != 6 {
+		t.Fatalf("unexpected template progress: ok=%v %+v", ok, p)
+	}
+
+	p, ok = parseYtDlpProgressLine(ytDlpProgressPrefix + `{"downloaded_bytes":10,"total_bytes":null,"total_bytes_estimate":null,"speed":null,"eta":null,"_percent_str":" 12.5%"}`)
+	if !ok || p.Percent != 12.5 {
+		t.Fatalf("expected percent from _percent_str, got ok=%v %+v", ok, p)
+	}
+
+	p, ok = parseYtDlpProgressLine("[download]  50.0% of ~  10.00MiB at    2.00MiB/s ETA 01:05")
+	if !ok || p.Percent != 50 || p.TotalBytes != 10<<20 || p.Speed != 2<<20 || p.ETA != 65 {
+		t.Fatalf("unexpected text progress: ok=%v %+v", ok, p)
+	}
+
+	if _, ok := parseYtDlpProgressLine("[info] Downloading 1 format(s): 137+140"); ok {
+		t.Fatalf("non-progress line must not parse")
+	}
+}
+
+func TestQueueProgressReporterThrottlesAndBroadcasts(t *testing.T) {
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+	old := DownloadProgressInterval
+	DownloadProgressInterval = time.Hour
+	defer func() { DownloadProgressInterval = old }()
+
+	item := pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 1, YouTubeID: "progress1"})
+	report := newQueueProgressReporter(ctx, item)
+
+	report(DownloadProgress{Percent: 10})
+	report(DownloadProgress{Percent: 20}) // throttled
+	if q := GetCurrentDownloadQueue(); len(q) != 1 || q[0].Progress == nil || q[0].Progress.Percent != 10 {
+		t.Fatalf("expected first progress stored and second throttled, got %+v", q)
+	}
+	report(DownloadProgress{Percent: 100}) // completion is never throttled
+	if st := GetDownloadStatus("progress1"); st == nil || st.Progress == nil || st.Progress.Percent != 100 {
+		t.Fatalf("expected completion progress in status, got %+v", st)
+	}
+}
+
+func TestPerformDownloadReportsProgress(t *testing.T) {
+	info, err := prepareDownloadInfo(MediaTypeMovie, 1, "Trailers", "Progress", "progressDl")
+	if err != nil {
+		t.Fatalf("prepareDownloadInfo failed: %v", err)
+	}
+	defer os.RemoveAll(info.TempDir)
+
+	var got []DownloadProgress
+	ctx := withProgressReporter(context.Background(), func(p DownloadProgress) { got = append(got, p) })
+	if _, err := performDownload(ctx, info, "progressDl"); err != nil {
+		t.Fatalf("performDownload failed: %v", err)
+	}
+	if len(got) != 2 || got[0].Percent != 50 || got[1].Percent != 100 {
+		t.Fatalf("expected two progress updates from fake runner, got %+v", got)
+	}
+	_ = os.Remove(info.OutFile)
+}
@@ -3,10 +3,13 @@ package internal
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
 	"os"
 	"sync"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
 
@@ -86,6 +89,49 @@ func GetCurrentDownloadQueue() []DownloadQueueItem {
 	return queue
 }
 
+// PauseDownloadQueueHandler stops workers from starting new downloads. Downloads
+// already running are left to finish.
+func PauseDownloadQueueHandler(c *gin.Context) {
+	setDownloadQueuePaused(true)
+	TrailarrLog(INFO, "QUEUE", "Download queue paused via API")
+	respondJSON(c, http.StatusOK, gin.H{"paused": true})
+}
+
+// ResumeDownloadQueueHandler lets workers pick up queued items again.
+func ResumeDownloadQueueHandler(c *gin.Context) {
+	setDownloadQueuePaused(false)
+	TrailarrLog(INFO, "QUEUE", "Download queue resumed via API")
+	respondJSON(c, http.StatusOK, gin.H{"paused": false})
+}
+
+// CancelDownloadQueueItemHandler cancels the queued or running downloads for a
+// YouTube ID. A running yt-dlp process is killed and its temp dir removed by
+// the worker; items that never started are removed from the queue here.
+func CancelDownloadQueueItemHandler(c *gin.Context) {
+	youtubeId := c.Param("youtubeId")
+	cancelled, err := cancelQueueItems(context.Background(), youtubeId)
+	if errors.Is(err, ErrNotFound) {
+		respondError(c, http.StatusNotFound, "queue item not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	TrailarrLog(INFO, "QUEUE", "Cancelled %d queue item(s) for youtubeId=%s", len(cancelled), youtubeId)
+	setDownloadStatus(youtubeId, &DownloadStatus{Status: "cancelled", UpdatedAt: time.Now()})
+	BroadcastDownloadQueueChanges(cancelled)
+	for _, item := range cancelled {
+		if !isQueueItemActive(item) {
+			go func(item DownloadQueueItem) {
+				time.Sleep(QueueItemRemoveDelay)
+				_ = removeDownloadQueueItem(context.Background(), item.YouTubeID, item.QueuedAt)
+			}(item)
+		}
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "cancelled", "queue": cancelled})
+}
+
 // DownloadQueueWatcher watches download_queue.json for changes and triggers a callback
 func DownloadQueueWatcher(path string, onChange func(changed []DownloadQueueItem)) {
 	var lastModTime time.Time
@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// blockingRunner keeps the download stream open until ctx is cancelled, recording the temp file in use.
+type blockingRunner struct {
+	started chan string
+}
+
+func (b *blockingRunner) StartCommand(ctx context.Context, name string, args []string, dir string, stderr io.Writer) (io.ReadCloser, *exec.Cmd, error) {
+	pr, pw := io.Pipe()
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == "--output" {
+			b.started <- filepath.Dir(args[i+1])
+		}
+	}
+	go func() {
+		<-ctx.Done()
+		pw.Close()
+	}()
+	return pr, &exec.Cmd{}, nil
+}
+
+func (b *blockingRunner) CombinedOutput(ctx context.Context, name string, args []string, dir string) ([]byte, error) {
+	return (&fakeRunner{}).CombinedOutput(ctx, name, args, dir)
+}
+
+func TestPauseAndResumeQueueEndpoints(t *testing.T) {
+	r := NewTestRouter()
+	RegisterRoutes(r)
+	defer setDownloadQueuePaused(false)
+
+	if w := DoRequest(r, "POST", "/api/queue/pause", nil); w.Code != 200 {
+		t.Fatalf("expected 200 on pause, got %d", w.Code)
+	}
+	if !isDownloadQueuePaused() {
+		t.Fatalf("expected queue to be paused")
+	}
+	if !waitForQueuePause(0) {
+		t.Fatalf("expected workers to wait while paused")
+	}
+	if w := DoRequest(r, "POST", "/api/queue/resume", nil); w.Code != 200 {
+		t.Fatalf("expected 200 on resume, got %d", w.Code)
+	}
+	if isDownloadQueuePaused() {
+		t.Fatalf("expected queue to be resumed")
+	}
+}
+
+func TestCancelQueuedItemEndpoint(t *testing.T) {
+	ctx := context.Background()
+	r := NewTestRouter()
+	RegisterRoutes(r)
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+
+	pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 1, YouTubeID: "cancelQ"})
+
+	if w := DoRequest(r, "DELETE", "/api/queue/unknownId", nil); w.Code != 404 {
+		t.Fatalf("expected 404 for unknown item, got %d", w.Code)
+	}
+	w := DoRequest(r, "DELETE", "/api/queue/cancelQ", nil)
+	if w.Code != 200 {
+		t.Fatalf("expected 200 on cancel, got %d body=%s", w.Code, w.Body.String())
+	}
+	if st := GetDownloadStatus("cancelQ"); st == nil || st.Status != "cancelled" {
+		t.Fatalf("expected cancelled status, got %+v", st)
+	}
+	if _, _, ok := claimNextQueuedItem(ctx, 0); ok {
+		t.Fatalf("cancelled item must not be claimable")
+	}
+	// the never-started item is removed after QueueItemRemoveDelay
+	deadline := time.Now().Add(time.Second)
+	for len(GetCurrentDownloadQueue()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if q := GetCurrentDownloadQueue(); len(q) != 0 {
+		t.Fatalf("expected cancelled item removed, got %+v", q)
+	}
+}
+
+func TestCancelRunningDownloadKillsProcess(t *testing.T) {
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+
+	runner := &blockingRunner{started: make(chan string, 1)}
+	oldRunner := ytDlpRunner
+	ytDlpRunner = runner
+	defer func() { ytDlpRunner = oldRunner }()
+
+	pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 4242, ExtraType: "Trailers", ExtraTitle: "Cancel Me", YouTubeID: "cancelRun"})
+	itemCtx, item, ok := claimNextQueuedItem(ctx, 0)
+	if !ok {
+		t.Fatalf("expected to claim item")
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = processQueueItem(itemCtx, item)
+		finishActiveDownload(item)
+		releaseHostSlot(downloadHost(item))
+		close(done)
+	}()
+
+	var tempDir string
+	select {
+	case tempDir = <-runner.started:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("download never started")
+	}
+	if _, err := cancelQueueItems(ctx, "cancelRun"); err != nil {
+		t.Fatalf("cancelQueueItems error: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("download was not interrupted by cancel")
+	}
+
+	if st := GetDownloadStatus("cancelRun"); st == nil || st.Status != "cancelled" {
+		t.Fatalf("expected cancelled status, got %+v", st)
+	}
+	if _, err := os.Stat(tempDir); !os.IsNotExist(err) {
+		t.Fatalf("expected temp dir %s removed, stat err=%v", filepath.Base(tempDir), err)
+	}
+	if e, _ := GetExtraByYoutubeId(ctx, "cancelRun", MediaTypeMovie, 4242); e != nil && e.Status == "rejected" {
+		t.Fatalf("cancelled download must not be rejected")
+	}
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// TransientDownloadError is returned for download failures that may succeed on
+// a later attempt (network errors, timeouts, extractor hiccups). Unlike
+// permanent failures these never mark the extra as rejected.
+type TransientDownloadError struct {
+	Reason string
+}
+
+func (e *TransientDownloadError) Error() string {
+	return e.Reason
+}
+
+// permanentFailureMarkers are yt-dlp messages for videos that will never download.
+var permanentFailureMarkers = []string{
+	"private video",
+	"video unavailable",
+	"this video is unavailable",
+	"this video has been removed",
+	"video has been removed by the uploader",
+	"account associated with this video has been terminated",
+	"this video does not exist",
+	"unsupported url",
+}
+
+// ageGateMarkers identify age-restricted videos; these are only permanent when
+// there are no cookies that could satisfy the age check.
+var ageGateMarkers = []string{
+	"sign in to confirm your age",
+	"age-restricted",
+	"inappropriate for some users",
+}
+
+// isPermanentDownloadFailure classifies yt-dlp output from a failed download.
+// Anything not recognised as permanent is treated as transient.
+func isPermanentDownloadFailure(output string) bool {
+	lower := strings.ToLower(output)
+	for _, m := range permanentFailureMarkers {
+		if strings.Contains(lower, m) {
+			return true
+		}
+	}
+	for _, m := range ageGateMarkers {
+		if strings.Contains(lower, m) {
+			return !hasUsableCookies()
+		}
+	}
+	return false
+}
+
+// hasUsableCookies reports whether a non-empty cookies file is available to yt-dlp.
+func hasUsableCookies() bool {
+	fi, err := os.Stat(CookiesFile)
+	return err == nil && fi.Size() > 0
+}
+
+// isTransientDownloadError reports whether err should be retried.
+func isTransientDownloadError(err error) bool {
+	var transient *TransientDownloadError
+	var tooMany *TooManyRequestsError
+	return errors.As(err, &transient) || errors.As(err, &tooMany)
+}
+
+// retryBackoff returns the wait before the given attempt number (1-based count
+// of failed attempts so far), doubling from RetryBackoffBase up to RetryBackoffMax.
+func retryBackoff(attempts int) time.Duration {
+	d := RetryBackoffBase
+	for i := 1; i < attempts && d < RetryBackoffMax; i++ {
+		d *= 2
+	}
+	if d > RetryBackoffMax {
+		d = RetryBackoffMax
+	}
+	return d
+}
+
+// requeueForRetry puts a failed item back in the queue with its attempt count
+// bumped and a backoff before it can be claimed again. It returns false when the
+// item has used all its attempts and should be marked failed instead.
+func requeueForRetry(ctx context.Context, item DownloadQueueItem, reason string) bool {
+	attempts := item.Attempts + 1
+	maxAttempts := item.MaxAttempts
+	if maxAttempts <= 0 {
+		cfg, _ := GetDownloadQueueConfig()
+		maxAttempts = cfg.MaxAttempts
+	}
+	if attempts >= maxAttempts {
+		TrailarrLog(WARN, "QUEUE", "Giving up on %s after %d attempts: %s", item.YouTubeID, attempts, reason)
+		return false
+	}
+	next := time.Now().Add(retryBackoff(attempts))
+	updated, err := updateDownloadQueueItem(ctx, item.YouTubeID, item.QueuedAt, func(q *DownloadQueueItem) {
+		q.Status = "queued"
+		q.Reason = reason
+		q.Attempts = attempts
+		q.MaxAttempts = maxAttempts
+		q.NextAttemptAt = next
+		q.Progress = nil
+	})
+	if err != nil {
+		TrailarrLog(WARN, "QUEUE", "Failed to re-queue %s for retry: %v", item.YouTubeID, err)
+		return false
+	}
+	TrailarrLog(INFO, "QUEUE", "Re-queued %s for retry %d/%d at %s: %s", item.YouTubeID, attempts+1, maxAttempts, next.Format(time.RFC3339), reason)
+	setDownloadStatus(item.YouTubeID, &DownloadStatus{Status: "queued", UpdatedAt: time.Now(), Error: reason})
+	BroadcastDownloadQueueChanges([]DownloadQueueItem{updated})
+	return true
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// failingRunner runs a shell command that prints message to stderr and exits 1.
+type failingRunner struct {
+	message string
+}
+
+func (f *failingRunner) StartCommand(ctx context.Context, name string, args []string, dir string, stderr io.Writer) (io.ReadCloser, *exec.Cmd, error) {
+	return (&DefaultYtDlpRunner{}).StartCommand(ctx, "sh", []string{"-c", `echo "$0" >&2; exit 1`, f.message}, dir, stderr)
+}
+
+func (f *failingRunner) CombinedOutput(ctx context.Context, name string, args []string, dir string) ([]byte, error) {
+	return []byte(f.message), exec.ErrNotFound
+}
+
+func TestIsPermanentDownloadFailure(t *testing.T) {
+	if !isPermanentDownloadFailure("ERROR: [youtube] abc: Private video. Sign in if you've been granted access") {
+		t.Fatalf("private video must be permanent")
+	}
+	if !isPermanentDownloadFailure("ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader") {
+		t.Fatalf("removed video must be permanent")
+	}
+	if isPermanentDownloadFailure("ERROR: unable to download video data: <urlopen error timed out>") {
+		t.Fatalf("timeouts must be transient")
+	}
+
+	ageGate := "ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users."
+	_ = os.Remove(CookiesFile)
+	if !isPermanentDownloadFailure(ageGate) {
+		t.Fatalf("age gate without cookies must be permanent")
+	}
+	if err := os.WriteFile(CookiesFile, []byte("# Netscape HTTP Cookie File\n"), 0o644); err != nil {
+		t.Fatalf("write cookies: %v", err)
+	}
+	defer os.Remove(CookiesFile)
+	if isPermanentDownloadFailure(ageGate) {
+		t.Fatalf("age gate with cookies must be retried")
+	}
+}
+
+func TestRetryBackoffDoublesUpToMax(t *testing.T) {
+	oldBase, oldMax := RetryBackoffBase, RetryBackoffMax
+	RetryBackoffBase, RetryBackoffMax = time.Second, 5*time.Second
+	defer func() { RetryBackoffBase, RetryBackoffMax = oldBase, oldMax }()
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := retryBackoff(i + 1); got != w {
+			t.Fatalf("retryBackoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestTransientFailureRequeuesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+
+	oldRunner := ytDlpRunner
+	ytDlpRunner = &failingRunner{message: "ERROR: unable to download video data: HTTP Error 503: Service Unavailable"}
+	defer func() { ytDlpRunner = oldRunner }()
+
+	pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 5151, ExtraType: "Trailers", ExtraTitle: "Retry", YouTubeID: "retry1", MaxAttempts: 2})
+	runQueuedItemForTest(t)
+
+	q := GetCurrentDownloadQueue()
+	if len(q) != 1 || q[0].Status != "queued" || q[0].Attempts != 1 || !q[0].NextAttemptAt.After(time.Now()) {
+		t.Fatalf("expected item re-queued with backoff, got %+v", q)
+	}
+	if _, _, ok := claimNextQueuedItem(ctx, 0); ok {
+		t.Fatalf("item in backoff must not be claimable")
+	}
+	if e, _ := GetExtraByYoutubeId(ctx, "retry1", MediaTypeMovie, 5151); e != nil && e.Status == "rejected" {
+		t.Fatalf("transient failure must not reject the extra")
+	}
+
+	// Final attempt: no more retries, the item fails without being rejected
+	if requeueForRetry(ctx, q[0], "still failing") {
+		t.Fatalf("expected no retry once attempts are used up")
+	}
+}
+
+func TestPermanentFailureRejectsExtra(t *testing.T) {
+	ctx := context.Background()
+	oldRunner := ytDlpRunner
+	ytDlpRunner = &failingRunner{message: "ERROR: [youtube] perm1: Private video"}
+	defer func() { ytDlpRunner = oldRunner }()
+
+	_, err := DownloadYouTubeExtra(MediaTypeMovie, 5152, "Trailers", "Perm", "perm1")
+	if err == nil || isTransientDownloadError(err) {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+	if e, _ := GetExtraByYoutubeId(ctx, "perm1", MediaTypeMovie, 5152); e == nil || e.Status != "rejected" {
+		t.Fatalf("expected extra rejected after permanent failure, got %+v", e)
+	}
+}
@@ -0,0 +1,402 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Download worker pool state. Every mutation of the DownloadQueue list goes
+// through downloadQueueStoreMu so list indexes stay stable between the LRange
+// that finds an item and the LSet/LRem that changes it.
+var (
+	downloadQueueStoreMu sync.Mutex
+	// activeDownloads holds the cancel func of every claimed item, keyed by
+	// queueItemKey. Guarded by downloadQueueStoreMu so a cancel request can
+	// never slip between a claim and its registration.
+	activeDownloads = make(map[string]context.CancelFunc)
+
+	downloadWorkersOnce sync.Once
+	downloadWorkersMu   sync.Mutex
+	activeHostDownloads = make(map[string]int)
+	queuePausedUntil    time.Time
+	queueManualPause    bool
+)
+
+// errDownloadCancelled is returned by a download whose queue item was cancelled.
+var errDownloadCancelled = errors.New("download cancelled")
+
+// startDownloadWorkerPool starts the configured number of download workers.
+// Pool size is read once at startup; changing it requires a restart.
+func startDownloadWorkerPool(ctx context.Context) {
+	cfg, _ := GetDownloadQueueConfig()
+	TrailarrLog(INFO, "QUEUE", "Starting %d download workers (max %d per host)", cfg.MaxConcurrent, cfg.MaxPerHost)
+	for i := 0; i < cfg.MaxConcurrent; i++ {
+		go runDownloadWorker(ctx, i, cfg.MaxPerHost)
+	}
+}
+
+// runDownloadWorker claims and processes queue items until the process exits.
+func runDownloadWorker(ctx context.Context, workerID int, maxPerHost int) {
+	for {
+		if waitForQueuePause(workerID) {
+			continue
+		}
+		itemCtx, item, ok := claimNextQueuedItem(ctx, maxPerHost)
+		if !ok {
+			time.Sleep(2 * time.Second)
+			continue
+		}
+		if err := processQueueItem(itemCtx, item); err != nil {
+			TrailarrLog(ERROR, "QUEUE", "[worker %d] processQueueItem error: %v", workerID, err)
+		}
+		finishActiveDownload(item)
+		releaseHostSlot(downloadHost(item))
+	}
+}
+
+// downloadHost returns the host an item will be downloaded from, used for the
+// per-host concurrency cap.
+func downloadHost(item DownloadQueueItem) string {
+	if item.SourceURL != "" {
+		if u, err := url.Parse(item.SourceURL); err == nil && u.Hostname() != "" {
+			return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		}
+	}
+	return "youtube.com"
+}
+
+// queueRecoverySummary describes what recoverDownloadQueue did at startup.
+type queueRecoverySummary struct {
+	Queued   int // items left queued, including re-queued ones
+	Requeued int // items that were downloading when the process stopped
+	Dropped  int // finished items that were waiting to be removed
+}
+
+// recoverDownloadQueue prepares the persisted queue for a fresh worker pool:
+// items stuck in "downloading" go back to "queued" and finished items, which
+// would have been removed shortly after completing, are dropped.
+func recoverDownloadQueue(ctx context.Context) (queueRecoverySummary, error) {
+	var summary queueRecoverySummary
+	downloadQueueStoreMu.Lock()
+	defer downloadQueueStoreMu.Unlock()
+	client := GetStoreClient()
+	vals, err := client.LRange(ctx, DownloadQueue, 0, -1)
+	if err != nil {
+		return summary, err
+	}
+	var kept [][]byte
+	for _, v := range vals {
+		var item DownloadQueueItem
+		if err := json.Unmarshal([]byte(v), &item); err != nil {
+			summary.Dropped++
+			continue
+		}
+		switch item.Status {
+		case "downloading":
+			item.Status = "queued"
+			summary.Requeued++
+		case "queued":
+		default:
+			summary.Dropped++
+			continue
+		}
+		summary.Queued++
+		b, _ := json.Marshal(item)
+		kept = append(kept, b)
+		setDownloadStatus(item.YouTubeID, &DownloadStatus{Status: "queued", UpdatedAt: time.Now()})
+	}
+	if summary.Requeued == 0 && summary.Dropped == 0 {
+		return summary, nil
+	}
+	if err := client.Del(ctx, DownloadQueue); err != nil {
+		return summary, err
+	}
+	for _, b := range kept {
+		if err := client.RPush(ctx, DownloadQueue, b); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+// queueItemKey identifies a queue entry; the same YouTube ID may be queued
+// more than once (eg. for different media).
+func queueItemKey(youtubeID string, queuedAt time.Time) string {
+	return fmt.Sprintf("%s|%d", youtubeID, queuedAt.UnixNano())
+}
+
+// claimNextQueuedItem picks the next queued item whose host has a free slot,
+// marks it downloading in the store, reserves the host slot and returns a
+// context that is cancelled when the item is cancelled. The lookup and the
+// update happen under the queue lock so two workers never claim the same item.
+func claimNextQueuedItem(ctx context.Context, maxPerHost int) (context.Context, DownloadQueueItem, bool) {
+	downloadQueueStoreMu.Lock()
+	client := GetStoreClient()
+	vals, err := client.LRange(ctx, DownloadQueue, 0, -1)
+	if err != nil {
+		downloadQueueStoreMu.Unlock()
+		return nil, DownloadQueueItem{}, false
+	}
+	i, item, ok := pickQueuedItem(vals, func(it DownloadQueueItem) bool {
+		return hostHasFreeSlot(downloadHost(it), maxPerHost)
+	})
+	if !ok {
+		downloadQueueStoreMu.Unlock()
+		return nil, DownloadQueueItem{}, false
+	}
+	item.Status = "downloading"
+	b, _ := json.Marshal(item)
+	if err := client.LSet(ctx, DownloadQueue, int64(i), b); err != nil {
+		downloadQueueStoreMu.Unlock()
+		TrailarrLog(WARN, "QUEUE", "[claimNextQueuedItem] Failed to mark downloading: %v", err)
+		return nil, DownloadQueueItem{}, false
+	}
+	acquireHostSlot(downloadHost(item))
+	itemCtx, cancel := context.WithCancel(ctx)
+	activeDownloads[queueItemKey(item.YouTubeID, item.QueuedAt)] = cancel
+	downloadQueueStoreMu.Unlock()
+	setDownloadStatus(item.YouTubeID, &DownloadStatus{Status: "downloading", UpdatedAt: time.Now()})
+	BroadcastDownloadQueueChanges([]DownloadQueueItem{item})
+	return itemCtx, item, true
+}
+
+// finishActiveDownload releases the context registered for a claimed item.
+func finishActiveDownload(item DownloadQueueItem) {
+	key := queueItemKey(item.YouTubeID, item.QueuedAt)
+	downloadQueueStoreMu.Lock()
+	cancel := activeDownloads[key]
+	delete(activeDownloads, key)
+	downloadQueueStoreMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// cancelQueueItems marks every queued or downloading entry for youtubeID as
+// cancelled and cancels the context of running ones, which kills their yt-dlp
+// process. Returns the cancelled entries, or ErrNotFound if there were none.
+func cancelQueueItems(ctx context.Context, youtubeID string) ([]DownloadQueueItem, error) {
+	downloadQueueStoreMu.Lock()
+	defer downloadQueueStoreMu.Unlock()
+	client := GetStoreClient()
+	vals, err := client.LRange(ctx, DownloadQueue, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	var cancelled []DownloadQueueItem
+	for i, v := range vals {
+		var item DownloadQueueItem
+		if err := json.Unmarshal([]byte(v), &item); err != nil || item.YouTubeID != youtubeID {
+			continue
+		}
+		if item.Status != "queued" && item.Status != "downloading" {
+			continue
+		}
+		item.Status = "cancelled"
+		b, _ := json.Marshal(item)
+		if err := client.LSet(ctx, DownloadQueue, int64(i), b); err != nil {
+			return cancelled, err
+		}
+		if cancel, ok := activeDownloads[queueItemKey(item.YouTubeID, item.QueuedAt)]; ok {
+			cancel()
+		}
+		cancelled = append(cancelled, item)
+	}
+	if len(cancelled) == 0 {
+		return nil, ErrNotFound
+	}
+	return cancelled, nil
+}
+
+// isQueueItemActive reports whether a cancelled item is still owned by a worker,
+// in which case the worker removes it from the queue when it finishes.
+func isQueueItemActive(item DownloadQueueItem) bool {
+	downloadQueueStoreMu.Lock()
+	defer downloadQueueStoreMu.Unlock()
+	_, ok := activeDownloads[queueItemKey(item.YouTubeID, item.QueuedAt)]
+	return ok
+}
+
+// updateDownloadQueueItem finds the queue entry matching YouTubeID+QueuedAt and
+// applies update to it. Matching on identity rather than index keeps updates
+// correct while other workers remove finished items from the list.
+func updateDownloadQueueItem(ctx context.Context, youtubeID string, queuedAt time.Time, update func(*DownloadQueueItem)) (DownloadQueueItem, error) {
+	downloadQueueStoreMu.Lock()
+	defer downloadQueueStoreMu.Unlock()
+	client := GetStoreClient()
+	vals, err := client.LRange(ctx, DownloadQueue, 0, -1)
+	if err != nil {
+		return DownloadQueueItem{}, err
+	}
+	for i, v := range vals {
+		var item DownloadQueueItem
+		if err := json.Unmarshal([]byte(v), &item); err != nil {
+			continue
+		}
+		if item.YouTubeID != youtubeID || !item.QueuedAt.Equal(queuedAt) {
+			continue
+		}
+		update(&item)
+		b, err := json.Marshal(item)
+		if err != nil {
+			return DownloadQueueItem{}, err
+		}
+		return item, client.LSet(ctx, DownloadQueue, int64(i), b)
+	}
+	return DownloadQueueItem{}, ErrNotFound
+}
+
+// removeDownloadQueueItem removes the queue entry matching YouTubeID+QueuedAt,
+// whatever its current status.
+func removeDownloadQueueItem(ctx context.Context, youtubeID string, queuedAt time.Time) error {
+	downloadQueueStoreMu.Lock()
+	defer downloadQueueStoreMu.Unlock()
+	client := GetStoreClient()
+	vals, err := client.LRange(ctx, DownloadQueue, 0, -1)
+	if err != nil {
+		return err
+	}
+	for _, v := range vals {
+		var item DownloadQueueItem
+		if err := json.Unmarshal([]byte(v), &item); err != nil {
+			continue
+		}
+		if item.YouTubeID == youtubeID && item.QueuedAt.Equal(queuedAt) {
+			return client.LRem(ctx, DownloadQueue, 1, []byte(v))
+		}
+	}
+	return nil
+}
+
+// hostHasFreeSlot reports whether host is below its per-host download cap.
+// A maxPerHost of zero or less disables the cap.
+func hostHasFreeSlot(host string, maxPerHost int) bool {
+	downloadWorkersMu.Lock()
+	defer downloadWorkersMu.Unlock()
+	return maxPerHost <= 0 || activeHostDownloads[host] < maxPerHost
+}
+
+// acquireHostSlot reserves a download slot for host. Callers check
+// hostHasFreeSlot first while holding downloadQueueStoreMu.
+func acquireHostSlot(host string) {
+	downloadWorkersMu.Lock()
+	activeHostDownloads[host]++
+	downloadWorkersMu.Unlock()
+}
+
+// releaseHostSlot frees a slot reserved by acquireHostSlot.
+func releaseHostSlot(host string) {
+	downloadWorkersMu.Lock()
+	defer downloadWorkersMu.Unlock()
+	if activeHostDownloads[host] <= 1 {
+		delete(activeHostDownloads, host)
+		return
+	}
+	activeHostDownloads[host]--
+}
+
+// errDownloadQueuePaused is returned to downloads run outside the queue while
+// the pool is paused on user request.
+var errDownloadQueuePaused = errors.New("download queue is paused")
+
+// reserveHostSlot waits until the pool isn't paused for a 429 and host is
+// below its cap, then reserves a slot for host like a worker claiming an
+// item. It is used by downloads run outside the queue, such as upgrades;
+// callers release the slot with releaseHostSlot.
+func reserveHostSlot(ctx context.Context, host string) error {
+	cfg, _ := GetDownloadQueueConfig()
+	for {
+		if isDownloadQueuePaused() {
+			return errDownloadQueuePaused
+		}
+		if downloadQueuePauseRemaining() == 0 {
+			downloadQueueStoreMu.Lock()
+			free := hostHasFreeSlot(host, cfg.MaxPerHost)
+			if free {
+				acquireHostSlot(host)
+			}
+			downloadQueueStoreMu.Unlock()
+			if free {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(QueuePollInterval):
+		}
+	}
+}
+
+// pauseDownloadQueueUntil stops all workers from claiming new items until t.
+// An earlier deadline never shortens an existing pause.
+func pauseDownloadQueueUntil(t time.Time) {
+	downloadWorkersMu.Lock()
+	defer downloadWorkersMu.Unlock()
+	if t.After(queuePausedUntil) {
+		queuePausedUntil = t
+	}
+}
+
+// downloadQueuePauseRemaining returns how long the pool stays paused, or zero.
+func downloadQueuePauseRemaining() time.Duration {
+	downloadWorkersMu.Lock()
+	defer downloadWorkersMu.Unlock()
+	if d := time.Until(queuePausedUntil); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// setDownloadQueuePaused pauses or resumes the pool on user request. Running
+// downloads are not interrupted. Resuming also lifts any 429 pause.
+func setDownloadQueuePaused(paused bool) {
+	downloadWorkersMu.Lock()
+	defer downloadWorkersMu.Unlock()
+	queueManualPause = paused
+	if !paused {
+		queuePausedUntil = time.Time{}
+	}
+}
+
+// isDownloadQueuePaused reports whether the pool was paused on user request.
+func isDownloadQueuePaused() bool {
+	downloadWorkersMu.Lock()
+	defer downloadWorkersMu.Unlock()
+	return queueManualPause
+}
+
+// waitForQueuePause sleeps while the pool is paused, either on user request
+// or for a 429, and reports whether it did. Only the first worker logs to
+// avoid duplicate lines.
+func waitForQueuePause(workerID int) bool {
+	if isDownloadQueuePaused() {
+		time.Sleep(QueuePollInterval)
+		return true
+	}
+	remaining := downloadQueuePauseRemaining()
+	if remaining <= 0 {
+		return false
+	}
+	if workerID == 0 {
+		TrailarrLog(INFO, "QUEUE", "Download queue paused. Resuming in %v seconds...", int(remaining.Seconds()))
+	}
+	if remaining > TooManyRequestsPauseLogInterval {
+		remaining = TooManyRequestsPauseLogInterval
+	}
+	time.Sleep(remaining)
+	return true
+}
+
+// setDownloadStatus records the in-memory status for a YouTube ID.
+func setDownloadStatus(youtubeID string, st *DownloadStatus) {
+	queueMutex.Lock()
+	downloadStatusMap[youtubeID] = st
+	queueMutex.Unlock()
+}
@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// pushQueueItemForTest appends a queued item directly to the store list.
+func pushQueueItemForTest(t *testing.T, item DownloadQueueItem) DownloadQueueItem {
+	t.Helper()
+	item.Status = "queued"
+	if item.QueuedAt.IsZero() {
+		item.QueuedAt = time.Now()
+	}
+	b, _ := json.Marshal(item)
+	if err := GetStoreClient().RPush(context.Background(), DownloadQueue, b); err != nil {
+		t.Fatalf("RPush failed: %v", err)
+	}
+	return item
+}
+
+// holdWorkerPool pauses any running worker pool so tests can claim items themselves.
+func holdWorkerPool(t *testing.T) {
+	t.Helper()
+	pauseDownloadQueueUntil(time.Now().Add(time.Minute))
+	t.Cleanup(func() {
+		downloadWorkersMu.Lock()
+		queuePausedUntil = time.Time{}
+		downloadWorkersMu.Unlock()
+	})
+}
+
+// runQueuedItemForTest claims the next queued item and downloads it the way a
+// worker would, returning the claimed item.
+func runQueuedItemForTest(t *testing.T) DownloadQueueItem {
+	t.Helper()
+	itemCtx, item, ok := claimNextQueuedItem(context.Background(), 0)
+	if !ok {
+		t.Fatalf("expected to claim a queued item")
+	}
+	_ = processQueueItem(itemCtx, item)
+	finishActiveDownload(item)
+	releaseHostSlot(downloadHost(item))
+	return item
+}
+
+func TestClaimNextQueuedItemRespectsHostCap(t *testing.T) {
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+
+	pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 1, YouTubeID: "claimA"})
+	pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 1, YouTubeID: "claimB"})
+
+	_, first, ok := claimNextQueuedItem(ctx, 1)
+	if !ok || first.YouTubeID != "claimA" || first.Status != "downloading" {
+		t.Fatalf("expected claimA claimed as downloading, got ok=%v item=%+v", ok, first)
+	}
+	if _, _, ok := claimNextQueuedItem(ctx, 1); ok {
+		t.Fatalf("expected per-host cap to block a second claim")
+	}
+	finishActiveDownload(first)
+	releaseHostSlot(downloadHost(first))
+
+	_, second, ok := claimNextQueuedItem(ctx, 1)
+	if !ok || second.YouTubeID != "claimB" {
+		t.Fatalf("expected claimB after releasing slot, got ok=%v item=%+v", ok, second)
+	}
+	finishActiveDownload(second)
+	releaseHostSlot(downloadHost(second))
+
+	if _, _, ok := claimNextQueuedItem(ctx, 1); ok {
+		t.Fatalf("expected no queued items left")
+	}
+}
+
+func TestUpdateDownloadQueueItemMatchesByIdentity(t *testing.T) {
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+
+	a := pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 1, YouTubeID: "idA"})
+	b := pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 1, YouTubeID: "idB"})
+
+	// Removing the first entry shifts indexes; the update must still hit idB.
+	if err := removeDownloadQueueItem(ctx, a.YouTubeID, a.QueuedAt); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if err := updateFinalStatusInStore(ctx, b, "downloaded", ""); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	q := GetCurrentDownloadQueue()
+	if len(q) != 1 || q[0].YouTubeID != "idB" || q[0].Status != "downloaded" {
+		t.Fatalf("unexpected queue after update: %+v", q)
+	}
+	if _, err := updateDownloadQueueItem(ctx, "missing", time.Now(), func(*DownloadQueueItem) {}); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound for unknown item, got %v", err)
+	}
+}
+
+func TestTooManyRequestsPausesWholePool(t *testing.T) {
+	holdWorkerPool(t)
+	downloadWorkersMu.Lock()
+	queuePausedUntil = time.Time{}
+	downloadWorkersMu.Unlock()
+
+	handleTooManyRequestsPause(&TooManyRequestsError{Message: "429"})
+	if downloadQueuePauseRemaining() <= 0 {
+		t.Fatalf("expected pool to be paused after 429")
+	}
+	if !waitForQueuePause(1) {
+		t.Fatalf("expected workers to wait while paused")
+	}
+}
+
+func TestDownloadQueueConfigDefaultsAndSave(t *testing.T) {
+	CreateTempConfig(t)
+	cfg, err := GetDownloadQueueConfig()
+	if err != nil {
+		t.Fatalf("GetDownloadQueueConfig error: %v", err)
+	}
+	if cfg != DefaultDownloadQueueConfig() {
+		t.Fatalf("expected defaults, got %+v", cfg)
+	}
+	if err := SaveDownloadQueueConfig(DownloadQueueConfig{MaxConcurrent: 4, MaxPerHost: 1}); err != nil {
+		t.Fatalf("SaveDownloadQueueConfig error: %v", err)
+	}
+	cfg, _ = GetDownloadQueueConfig()
+	if cfg.MaxConcurrent != 4 || cfg.MaxPerHost != 1 {
+		t.Fatalf("unexpected saved config: %+v", cfg)
+	}
+}
+
+func TestRecoverDownloadQueueRequeuesInterruptedItems(t *testing.T) {
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+
+	now := time.Now()
+	for i, st := range []string{"queued", "downloading", "downloaded", "failed"} {
+		item := DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 1, YouTubeID: "rec-" + st, Status: st, QueuedAt: now.Add(time.Duration(i) * time.Second)}
+		b, _ := json.Marshal(item)
+		_ = GetStoreClient().RPush(ctx, DownloadQueue, b)
+	}
+
+	summary, err := recoverDownloadQueue(ctx)
+	if err != nil {
+		t.Fatalf("recoverDownloadQueue error: %v", err)
+	}
+	if summary.Queued != 2 || summary.Requeued != 1 || summary.Dropped != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	q := GetCurrentDownloadQueue()
+	if len(q) != 2 || q[0].YouTubeID != "rec-queued" || q[1].YouTubeID != "rec-downloading" {
+		t.Fatalf("unexpected recovered queue: %+v", q)
+	}
+	for _, item := range q {
+		if item.Status != "queued" {
+			t.Fatalf("expected all recovered items queued, got %+v", item)
+		}
+	}
+}
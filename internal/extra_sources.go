@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// youtubeIdRe matches a bare YouTube video ID.
+	youtubeIdRe = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	// unsafeSourceIdRe matches the characters not kept in source extra IDs.
+	unsafeSourceIdRe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+)
+
+// maxSourceIdLen caps source extra IDs; longer ones are replaced by a hash of the URL.
+const maxSourceIdLen = 64
+
+// SourceURLLookupTimeout bounds the lookup of a manual extra URL, which runs
+// while the request waits.
+var SourceURLLookupTimeout = 30 * time.Second
+
+// lookupSourceHost resolves the host of a manual extra URL.
+var lookupSourceHost = net.DefaultResolver.LookupIPAddr
+
+// youtubeWatchURL returns the watch page URL of a YouTube video.
+func youtubeWatchURL(youtubeId string) string {
+	return "https://www.youtube.com/watch?v=" + youtubeId
+}
+
+// youtubeIDFromURL returns the video ID of a YouTube watch, short, embed or
+// youtu.be URL.
+func youtubeIDFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", false
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(u.Host), "www."), "m.")
+	var id string
+	switch host {
+	case "youtu.be":
+		id = strings.Trim(u.Path, "/")
+	case "youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		if u.Path == "/watch" {
+			id = u.Query().Get("v")
+		} else {
+			for _, prefix := range []string{"/shorts/", "/embed/", "/live/", "/v/"} {
+				if rest, ok := strings.CutPrefix(u.Path, prefix); ok {
+					id = strings.Trim(rest, "/")
+					break
+				}
+			}
+		}
+	}
+	return id, youtubeIdRe.MatchString(id)
+}
+
+// extraDownloadURL returns what yt-dlp is given to download an extra: its
+// source URL, or the YouTube ID for YouTube extras.
+func extraDownloadURL(youtubeId, sourceURL string) string {
+	if sourceURL != "" {
+		return sourceURL
+	}
+	return youtubeId
+}
+
+// extraInfoURL returns the URL `yt-dlp -J` is given to look up an extra.
+func extraInfoURL(youtubeId, sourceURL string) string {
+	if sourceURL != "" {
+		return sourceURL
+	}
+	return youtubeWatchURL(youtubeId)
+}
+
+// sourceVideo is the part of the `yt-dlp -J` output used to add an extra
+// from a non-YouTube URL.
+type sourceVideo struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	ExtractorKey string `json:"extractor_key"`
+	WebpageURL   string `json:"webpage_url"`
+	Domain       string `json:"webpage_url_domain"`
+}
+
+// site returns the name of the site the video is on, eg. Vimeo. Videos found
+// by yt-dlp's generic extractor are named by their domain.
+func (v sourceVideo) site() string {
+	if v.ExtractorKey != "" && v.ExtractorKey != "Generic" {
+		return v.ExtractorKey
+	}
+	return v.Domain
+}
+
+// sourceExtraID derives the stable ID a non-YouTube extra is stored under
+// from the extractor and the site's video ID, eg. vimeo-76979871. It stands
+// in for the YouTube ID everywhere extras are keyed.
+func sourceExtraID(v sourceVideo, sourceURL string) string {
+	extractor := strings.ToLower(unsafeSourceIdRe.ReplaceAllString(v.ExtractorKey, ""))
+	if extractor == "" {
+		extractor = "url"
+	}
+	id := strings.Trim(unsafeSourceIdRe.ReplaceAllString(v.ID, "_"), "_")
+	if id == "" || len(extractor)+1+len(id) > maxSourceIdLen {
+		sum := sha1.Sum([]byte(sourceURL))
+		id = hex.EncodeToString(sum[:8])
+	}
+	return extractor + "-" + id
+}
+
+// probeSourceURL asks yt-dlp for the video at a URL, failing if no extractor supports it.
+func probeSourceURL(ctx context.Context, sourceURL string) (sourceVideo, error) {
+	var v sourceVideo
+	out, err := fetchVideoInfoJSON(ctx, sourceURL, "--no-playlist")
+	if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(out, &v); err != nil {
+		return v, err
+	}
+	if v.ID == "" {
+		return v, fmt.Errorf("no video found at %s", sourceURL)
+	}
+	return v, nil
+}
+
+// manualExtraSource is where a manually added extra is downloaded from.
+type manualExtraSource struct {
+	ID    string // YouTube ID, or the source-derived ID for other sites
+	URL   string // empty for YouTube
+	Site  string
+	Title string
+}
+
+// resolveManualExtraURL resolves a URL given for a manual extra. YouTube URLs
+// resolve to their video ID without a lookup; other URLs must be supported by
+// yt-dlp.
+func resolveManualExtraURL(ctx context.Context, rawURL string) (manualExtraSource, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if id, ok := youtubeIDFromURL(rawURL); ok {
+		return manualExtraSource{ID: id}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, SourceURLLookupTimeout)
+	defer cancel()
+	if err := checkPublicURL(ctx, rawURL); err != nil {
+		return manualExtraSource{}, err
+	}
+	v, err := probeSourceURL(ctx, rawURL)
+	if err != nil {
+		return manualExtraSource{}, fmt.Errorf("unsupported url %s: %w", rawURL, err)
+	}
+	sourceURL := rawURL
+	if v.WebpageURL != "" && v.WebpageURL != rawURL {
+		if err := checkPublicURL(ctx, v.WebpageURL); err != nil {
+			return manualExtraSource{}, err
+		}
+		sourceURL = v.WebpageURL
+	}
+	return manualExtraSource{ID: sourceExtraID(v, sourceURL), URL: sourceURL, Site: v.site(), Title: v.Title}, nil
+}
+
+// checkPublicURL rejects URLs that aren't http(s) or whose host is or
+// resolves to a loopback, private or link-local address, so manual extras
+// can't make the server fetch from the local network.
+func checkPublicURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid url: %s", rawURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("url host is not public: %s", host)
+	}
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := lookupSourceHost(ctx, host)
+		if err != nil {
+			return fmt.Errorf("cannot resolve url host %s: %w", host, err)
+		}
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+	}
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+			ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+			return fmt.Errorf("url host is not public: %s", host)
+		}
+	}
+	return nil
+}
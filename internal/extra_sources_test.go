@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const vimeoURL = "https://vimeo.com/76979871"
+
+// vimeoInfo reports a Vimeo video for `yt-dlp -J` of vimeoURL.
+func vimeoInfo(url string) ([]byte, error) {
+	if url != vimeoURL {
+		return []byte("ERROR: Unsupported URL: " + url), exec.ErrNotFound
+	}
+	return []byte(`{"id":"76979871","title":"The New Vimeo Player","extractor_key":"Vimeo","webpage_url":"` + vimeoURL + `","webpage_url_domain":"vimeo.com"}`), nil
+}
+
+// stubSourceHosts resolves the hosts of manual extra URLs from hosts instead of DNS.
+func stubSourceHosts(t *testing.T, hosts map[string]string) {
+	t.Helper()
+	old := lookupSourceHost
+	lookupSourceHost = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		ip, ok := hosts[host]
+		if !ok {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+	}
+	t.Cleanup(func() { lookupSourceHost = old })
+}
+
+func TestCheckPublicURL(t *testing.T) {
+	stubSourceHosts(t, map[string]string{"vimeo.com": "162.159.138.60", "nas.lan": "192.168.1.20"})
+	for rawURL, public := range map[string]bool{
+		vimeoURL:                                   true,
+		"https://93.184.216.34/trailer.mp4":        true,
+		"http://127.0.0.1:7889/api/settings":       false,
+		"http://localhost/trailer.mp4":             false,
+		"http://10.0.0.5/trailer.mp4":              false,
+		"http://169.254.169.254/latest/meta-data/": false,
+		"http://[::1]/trailer.mp4":                 false,
+		"http://nas.lan/trailer.mp4":               false,
+		"http://unknown.invalid/trailer.mp4":       false,
+		"file:///etc/passwd":                       false,
+	} {
+		if err := checkPublicURL(context.Background(), rawURL); (err == nil) != public {
+			t.Fatalf("checkPublicURL(%q) = %v, want public=%v", rawURL, err, public)
+		}
+	}
+}
+
+func TestYoutubeIDFromURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=10":   "dQw4w9WgXcQ",
+		"https://youtu.be/dQw4w9WgXcQ":                       "dQw4w9WgXcQ",
+		"https://m.youtube.com/shorts/dQw4w9WgXcQ":           "dQw4w9WgXcQ",
+		"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ": "dQw4w9WgXcQ",
+		vimeoURL:                                "",
+		"https://www.youtube.com/watch?v=short": "",
+	} {
+		got, ok := youtubeIDFromURL(in)
+		if ok != (want != "") || (ok && got != want) {
+			t.Fatalf("youtubeIDFromURL(%q) = %q %v, want %q", in, got, ok, want)
+		}
+	}
+}
+
+func TestSourceExtraID(t *testing.T) {
+	if id := sourceExtraID(sourceVideo{ID: "76979871", ExtractorKey: "Vimeo"}, vimeoURL); id != "vimeo-76979871" {
+		t.Fatalf("unexpected id %q", id)
+	}
+	if id := sourceExtraID(sourceVideo{ID: "trailer final.mp4", ExtractorKey: "Generic"}, "https://studio.example/trailer final.mp4"); id != "generic-trailer_final_mp4" {
+		t.Fatalf("unexpected id %q", id)
+	}
+	long := sourceVideo{ID: strings.Repeat("x", 80), ExtractorKey: "Generic"}
+	a, b := sourceExtraID(long, "https://a.example/v"), sourceExtraID(long, "https://b.example/v")
+	if a == b || len(a) > maxSourceIdLen || a != sourceExtraID(long, "https://a.example/v") {
+		t.Fatalf("expected stable hashed ids per url, got %q %q", a, b)
+	}
+}
+
+func TestDownloadExtraFromSourceURL(t *testing.T) {
+	CreateTempConfig(t)
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+	stubSourceHosts(t, map[string]string{"vimeo.com": "162.159.138.60", "example.com": "93.184.216.34"})
+	runner := &fakeRunner{videoInfo: vimeoInfo}
+	oldRunner := ytDlpRunner
+	ytDlpRunner = runner
+	defer func() { ytDlpRunner = oldRunner }()
+
+	r := NewTestRouter()
+	r.POST("/api/extras/download", downloadExtraHandler)
+	if w := DoRequest(r, "POST", "/api/extras/download", []byte(`{"mediaType":"movie","mediaId":8181,"extraType":"Trailers","url":"https://example.com/nothing"}`)); w.Code != 400 {
+		t.Fatalf("expected 400 for unsupported url, got %d", w.Code)
+	}
+	if w := DoRequest(r, "POST", "/api/extras/download", []byte(`{"mediaType":"movie","mediaId":8181,"extraType":"Trailers","url":"http://192.168.1.1/admin"}`)); w.Code != 400 {
+		t.Fatalf("expected 400 for a LAN url, got %d", w.Code)
+	}
+	w := DoRequest(r, "POST", "/api/extras/download", []byte(`{"mediaType":"movie","mediaId":8181,"extraType":"Trailers","url":"`+vimeoURL+`"}`))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "vimeo-76979871") {
+		t.Fatalf("expected queued vimeo extra, got %d %s", w.Code, w.Body.String())
+	}
+	q := GetCurrentDownloadQueue()
+	if len(q) != 1 || q[0].YouTubeID != "vimeo-76979871" || q[0].SourceURL != vimeoURL || q[0].SourceSite != "Vimeo" || q[0].ExtraTitle != "The New Vimeo Player" {
+		t.Fatalf("unexpected queue %+v", q)
+	}
+	if host := downloadHost(q[0]); host != "vimeo.com" {
+		t.Fatalf("expected vimeo.com host, got %q", host)
+	}
+
+	runQueuedItemForTest(t)
+	if d := runner.downloadArgs(); len(d) != 1 || d[0][len(d[0])-1] != vimeoURL {
+		t.Fatalf("expected yt-dlp to download the source url, got %v", d)
+	}
+	e, _ := GetExtraByYoutubeId(ctx, "vimeo-76979871", MediaTypeMovie, 8181)
+	if e == nil || e.Status != "downloaded" || e.SourceURL != vimeoURL || e.SourceSite != "Vimeo" {
+		t.Fatalf("expected downloaded entry with source, got %+v", e)
+	}
+}
+
+func TestRejectedSourceExtraKeepsURL(t *testing.T) {
+	ctx := context.Background()
+	info := &downloadInfo{MediaType: MediaTypeMovie, MediaId: 8182, ExtraType: "Trailers", ExtraTitle: "Teaser", SourceURL: vimeoURL, SourceSite: "Vimeo"}
+	if err := setDownloadRejected(info, "vimeo-1", "HTTP Error 404"); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	e, _ := GetExtraByYoutubeId(ctx, "vimeo-1", MediaTypeMovie, 8182)
+	if e == nil || e.Status != "rejected" || e.SourceURL != vimeoURL {
+		t.Fatalf("expected rejected entry with source url, got %+v", e)
+	}
+	if meta := checkRejectedExtras(info, "vimeo-1"); meta == nil || meta.Status != "rejected" {
+		t.Fatalf("expected rejected source extra to be skipped, got %+v", meta)
+	}
+}
+
+func TestRequeuedSourceExtraKeepsURL(t *testing.T) {
+	CreateTempConfig(t)
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+
+	extra := Extra{ExtraType: "Trailers", ExtraTitle: "Teaser", YoutubeId: "vimeo-76979871", Status: "missing", SourceURL: vimeoURL, SourceSite: "Vimeo"}
+	if err := enqueueExtraDownload(MediaTypeMovie, 8183, extra, QueueSourceTask); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	q := GetCurrentDownloadQueue()
+	if len(q) != 1 || q[0].SourceURL != vimeoURL || q[0].SourceSite != "Vimeo" {
+		t.Fatalf("expected the source url on the queued item, got %+v", q)
+	}
+	if got := extraDownloadURL(q[0].YouTubeID, q[0].SourceURL); got != vimeoURL {
+		t.Fatalf("expected yt-dlp to be given the source url, got %q", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -43,11 +44,11 @@ func RemoveAll429Rejections() error {
 		}
 	}
 	// After bulk removals, refresh the rejected-index asynchronously.
-	go func() {
+	updateIndexAsync(func() {
 		if err := SaveRejectedIndex(); err != nil {
 			TrailarrLog(WARN, "RemoveAll429Rejections", rejectedIndexSaveErrFmt, err)
 		}
-	}()
+	})
 	return nil
 }
 
@@ -129,6 +130,16 @@ type ExtrasEntry struct {
 	YoutubeId  string    `json:"youtubeId"`
 	Status     string    `json:"status"`
 	Reason     string    `json:"reason,omitempty"`
+	Language   string    `json:"language,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	// SourceURL and SourceSite are set for extras not on YouTube, whose
+	// YoutubeId is then an ID derived from the source (eg. vimeo-76979871)
+	SourceURL  string `json:"sourceUrl,omitempty"`
+	SourceSite string `json:"sourceSite,omitempty"`
+	// Season is set for extras of a single season of a series
+	Season int `json:"season,omitempty"`
+	// Video is what ffprobe reported for the downloaded file, if it ran
+	Video *VideoInfo `json:"video,omitempty"`
 }
 
 // MarkRejectedExtrasInMemory sets Status="rejected" for extras whose YoutubeId is in rejectedYoutubeIds (in-memory only)
@@ -264,6 +275,15 @@ type Extra struct {
 	YoutubeId  string
 	Status     string
 	Reason     string
+	// Language is the video language TMDB reports, eg. es-MX
+	Language string `json:",omitempty"`
+	// Source is where the extra was found when not listed by TMDB, eg. search
+	Source string `json:",omitempty"`
+	// SourceURL and SourceSite are set for extras not on YouTube
+	SourceURL  string `json:",omitempty"`
+	SourceSite string `json:",omitempty"`
+	// Season is set for extras of a single season of a series
+	Season int `json:",omitempty"`
 }
 
 // GetRejectedExtrasForMedia returns rejected extras for a given media type and id, using the store cache
@@ -283,6 +303,7 @@ func GetRejectedExtrasForMedia(mediaType MediaType, id int) []RejectedExtra {
 				ExtraTitle: e.ExtraTitle,
 				YoutubeId:  e.YoutubeId,
 				Reason:     e.Reason,
+				Season:     e.Season,
 			})
 		}
 	}
@@ -292,25 +313,28 @@ func GetRejectedExtrasForMedia(mediaType MediaType, id int) []RejectedExtra {
 // SetExtraRejectedPersistent sets the Status of an extra to "rejected" in the store, adding it if not present (persistent)
 func SetExtraRejectedPersistent(mediaType MediaType, mediaId int, extraType, extraTitle, youtubeId, reason string) error {
 	TrailarrLog(INFO, "SetExtraRejectedPersistent", "Attempting to mark rejected: mediaType=%s, mediaId=%d, extraType=%s, extraTitle=%s, youtubeId=%s, reason=%s", mediaType, mediaId, extraType, extraTitle, youtubeId, reason)
-	ctx := context.Background()
-	entry := ExtrasEntry{
+	return setExtraEntryRejected(ExtrasEntry{
 		MediaType:  mediaType,
 		MediaId:    mediaId,
 		ExtraType:  extraType,
 		ExtraTitle: extraTitle,
 		YoutubeId:  youtubeId,
-		Status:     "rejected",
-		Reason:     reason,
-	}
-	if err := AddOrUpdateExtra(ctx, entry); err != nil {
+	}, reason)
+}
+
+// setExtraEntryRejected stores entry as rejected with reason and refreshes the rejected index.
+func setExtraEntryRejected(entry ExtrasEntry, reason string) error {
+	entry.Status = "rejected"
+	entry.Reason = reason
+	if err := AddOrUpdateExtra(context.Background(), entry); err != nil {
 		return err
 	}
 	// Update rejected-index async to avoid blocking the caller.
-	go func() {
+	updateIndexAsync(func() {
 		if err := SaveRejectedIndex(); err != nil {
 			TrailarrLog(WARN, "SetExtraRejectedPersistent", rejectedIndexSaveErrFmt, err)
 		}
-	}()
+	})
 	return nil
 }
 
@@ -321,11 +345,11 @@ func UnmarkExtraRejected(mediaType MediaType, mediaId int, extraType, extraTitle
 		return err
 	}
 	// Update rejected-index async
-	go func() {
+	updateIndexAsync(func() {
 		if err := SaveRejectedIndex(); err != nil {
 			TrailarrLog(WARN, "UnmarkExtraRejected", rejectedIndexSaveErrFmt, err)
 		}
-	}()
+	})
 	return nil
 }
 
@@ -344,11 +368,11 @@ func MarkExtraDownloaded(mediaType MediaType, mediaId int, extraType, extraTitle
 		return err
 	}
 	// Update rejected-index async in case this cleared a rejected flag
-	go func() {
+	updateIndexAsync(func() {
 		if err := SaveRejectedIndex(); err != nil {
 			TrailarrLog(WARN, "MarkExtraDownloaded", rejectedIndexSaveErrFmt, err)
 		}
-	}()
+	})
 	return nil
 }
 
@@ -367,11 +391,11 @@ func MarkExtraDeleted(mediaType MediaType, mediaId int, extraType, extraTitle, y
 		return err
 	}
 	// Update rejected-index async in case this cleared a rejected flag
-	go func() {
+	updateIndexAsync(func() {
 		if err := SaveRejectedIndex(); err != nil {
 			TrailarrLog(WARN, "MarkExtraDeleted", rejectedIndexSaveErrFmt, err)
 		}
-	}()
+	})
 	return nil
 }
 
@@ -387,8 +411,14 @@ func deleteExtraHandler(c *gin.Context) {
 		return
 	}
 
-	cacheFile, _ := resolveCachePath(req.MediaType)
-	mediaPath, err := FindMediaPathByID(cacheFile, req.MediaId)
+	var mediaPath string
+	var err error
+	if req.MediaType == MediaTypeCollection {
+		mediaPath, err = collectionExtrasPath(req.MediaId)
+	} else {
+		cacheFile, _ := resolveCachePath(req.MediaType)
+		mediaPath, err = FindMediaPathByID(cacheFile, req.MediaId)
+	}
 	if err != nil || mediaPath == "" {
 		respondError(c, http.StatusNotFound, "Media not found")
 		return
@@ -401,8 +431,17 @@ func deleteExtraHandler(c *gin.Context) {
 		respondError(c, http.StatusNotFound, "Extra not found in collection")
 		return
 	}
-	// Try to delete files, but do not fail if missing
-	_ = deleteExtraFiles(mediaPath, entry.ExtraType, entry.ExtraTitle)
+	// Try to delete files, but do not fail if missing. The recorded file name
+	// tells same-titled extras apart.
+	if entry.FileName == "" || os.Remove(entry.FileName) != nil {
+		_ = deleteExtraFiles(mediaPath, entry.ExtraType, entry.ExtraTitle)
+	} else {
+		_ = os.Remove(strings.TrimSuffix(entry.FileName, ".mkv") + mkvJSONSuffix)
+	}
+	scheduleLibraryRefresh(mediaPath)
+	if req.MediaType == MediaTypeCollection {
+		deleteCollectionCopies(ctx, req.MediaId, req.YoutubeId)
+	}
 
 	// Remove from the unified collection in the store
 	if err := RemoveExtra(ctx, req.YoutubeId, req.MediaType, req.MediaId); err != nil {
@@ -440,13 +479,42 @@ func lookupMediaTitle(cacheFile string, mediaId int) string {
 	return ""
 }
 
+// lookupMediaYear returns the release year of a cached movie or series, or "".
+func lookupMediaYear(cacheFile string, mediaId int) string {
+	items, err := loadCache(cacheFile)
+	if err != nil {
+		return ""
+	}
+	for _, m := range items {
+		idInt, ok := parseMediaID(m["id"])
+		if ok && idInt == mediaId {
+			if y, ok := m["year"].(float64); ok && y > 0 {
+				return strconv.Itoa(int(y))
+			}
+		}
+	}
+	return ""
+}
+
+// deleteExtraFiles removes an extra and its .mkv.json sidecar wherever the
+// naming schemes would have put it.
 func deleteExtraFiles(mediaPath, extraType, extraTitle string) error {
-	extraDir := mediaPath + "/" + extraType
-	extraFile := extraDir + "/" + SanitizeFilename(extraTitle) + ".mkv"
-	metaFile := extraDir + "/" + SanitizeFilename(extraTitle) + mkvJSONSuffix
-	err1 := os.Remove(extraFile)
-	err2 := os.Remove(metaFile)
-	if err1 != nil && err2 != nil {
+	var err1, err2 error
+	removed := false
+	for _, extraFile := range extraFileCandidates(mediaPath, extraType, SanitizeFilename(extraTitle)) {
+		metaFile := strings.TrimSuffix(extraFile, ".mkv") + mkvJSONSuffix
+		if err := os.Remove(extraFile); err == nil {
+			removed = true
+		} else if err1 == nil {
+			err1 = err
+		}
+		if err := os.Remove(metaFile); err == nil {
+			removed = true
+		} else if err2 == nil {
+			err2 = err
+		}
+	}
+	if !removed {
 		return fmt.Errorf("file error: %v, meta error: %v", err1, err2)
 	}
 	return nil
@@ -455,6 +523,10 @@ func deleteExtraFiles(mediaPath, extraType, extraTitle string) error {
 func recordDeleteHistory(mediaType MediaType, mediaId int, extraType, extraTitle string) {
 	cacheFile, _ := resolveCachePath(mediaType)
 	mediaTitle := lookupMediaTitle(cacheFile, mediaId)
+	if mediaType == MediaTypeCollection {
+		col, _ := findMovieCollection(mediaId)
+		mediaTitle = col.Title
+	}
 	if mediaTitle == "" {
 		panic(fmt.Errorf("recordDeleteHistory: could not find media title for mediaType=%v, mediaId=%v", mediaType, mediaId))
 	}
@@ -492,7 +564,7 @@ func FetchTMDBExtrasForMedia(mediaType MediaType, id int) ([]Extra, error) {
 		return nil, err
 	}
 
-	extras, err := FetchTMDBExtras(mediaType, tmdbId, tmdbKey)
+	extras, err := FetchTMDBExtras(mediaType, tmdbId, tmdbKey, preferredLanguages(mediaType))
 	if err != nil {
 		return nil, err
 	}
@@ -513,36 +585,54 @@ func collectExistingFromSubdir(subdir string, dupCount map[string]int) []map[str
 		if f.IsDir() || !strings.HasSuffix(f.Name(), ".mkv") {
 			continue
 		}
-		metaFile := filepath.Join(subdir, strings.TrimSuffix(f.Name(), ".mkv")+mkvJSONSuffix)
-		var meta struct {
-			ExtraType  string `json:"extraType"`
-			ExtraTitle string `json:"extraTitle"`
-			FileName   string `json:"fileName"`
-			YoutubeId  string `json:"youtubeId"`
-			Status     string `json:"status"`
-		}
-		status := "not-downloaded"
-		if err := ReadJSONFile(metaFile, &meta); err == nil {
-			status = meta.Status
-			if status == "" {
-				status = "downloaded"
-			}
+		results = append(results, existingExtraEntry(dirName, filepath.Join(subdir, f.Name()), dupCount))
+	}
+	return results
+}
+
+// collectExistingSuffixed lists Jellyfin-style "<name>-trailer.mkv" extras stored
+// directly in the media folder.
+func collectExistingSuffixed(mediaPath string, dupCount map[string]int) []map[string]interface{} {
+	var results []map[string]interface{}
+	for _, f := range listExtraFiles(mediaPath) {
+		if filepath.Dir(f.Path) != filepath.Clean(mediaPath) {
+			continue
 		}
-		key := dirName + "|" + meta.ExtraTitle
-		dupCount[key]++
-		results = append(results, map[string]interface{}{
-			"type":       dirName,
-			"extraType":  meta.ExtraType,
-			"extraTitle": meta.ExtraTitle,
-			"fileName":   meta.FileName,
-			"YoutubeId":  meta.YoutubeId,
-			"_dupIndex":  dupCount[key],
-			"status":     status,
-		})
+		results = append(results, existingExtraEntry(f.ExtraType, f.Path, dupCount))
 	}
 	return results
 }
 
+// existingExtraEntry describes one extra file on disk using its .mkv.json sidecar if present.
+func existingExtraEntry(typeName, filePath string, dupCount map[string]int) map[string]interface{} {
+	metaFile := strings.TrimSuffix(filePath, ".mkv") + mkvJSONSuffix
+	var meta struct {
+		ExtraType  string `json:"extraType"`
+		ExtraTitle string `json:"extraTitle"`
+		FileName   string `json:"fileName"`
+		YoutubeId  string `json:"youtubeId"`
+		Status     string `json:"status"`
+	}
+	status := "not-downloaded"
+	if err := ReadJSONFile(metaFile, &meta); err == nil {
+		status = meta.Status
+		if status == "" {
+			status = "downloaded"
+		}
+	}
+	key := typeName + "|" + meta.ExtraTitle
+	dupCount[key]++
+	return map[string]interface{}{
+		"type":       typeName,
+		"extraType":  meta.ExtraType,
+		"extraTitle": meta.ExtraTitle,
+		"fileName":   meta.FileName,
+		"YoutubeId":  meta.YoutubeId,
+		"_dupIndex":  dupCount[key],
+		"status":     status,
+	}
+}
+
 func existingExtrasHandler(c *gin.Context) {
 	moviePath := c.Query("moviePath")
 	if moviePath == "" {
@@ -561,6 +651,7 @@ func existingExtrasHandler(c *gin.Context) {
 	for _, subdir := range subdirs {
 		existing = append(existing, collectExistingFromSubdir(subdir, dupCount)...)
 	}
+	existing = append(existing, collectExistingSuffixed(moviePath, dupCount)...)
 	respondJSON(c, http.StatusOK, gin.H{"existing": existing})
 }
 
@@ -571,13 +662,30 @@ func downloadExtraHandler(c *gin.Context) {
 		ExtraType  string    `json:"extraType"`
 		ExtraTitle string    `json:"extraTitle"`
 		YoutubeId  string    `json:"youtubeId"`
+		// URL is any video URL yt-dlp supports, used when YoutubeId is empty
+		URL string `json:"url"`
+		// Season places the extra in a season folder of a series
+		Season int `json:"season"`
 	}
 	if err := c.BindJSON(&req); err != nil {
 		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
 		return
 	}
-	TrailarrLog(INFO, "Extras", "[downloadExtraHandler] Download request: mediaType=%s, mediaId=%d, extraType=%s, extraTitle=%s, youtubeId=%s",
-		req.MediaType, req.MediaId, req.ExtraType, req.ExtraTitle, req.YoutubeId)
+	TrailarrLog(INFO, "Extras", "[downloadExtraHandler] Download request: mediaType=%s, mediaId=%d, extraType=%s, extraTitle=%s, youtubeId=%s, url=%s",
+		req.MediaType, req.MediaId, req.ExtraType, req.ExtraTitle, req.YoutubeId, req.URL)
+
+	var source manualExtraSource
+	if req.YoutubeId == "" && req.URL != "" {
+		var err error
+		if source, err = resolveManualExtraURL(c.Request.Context(), req.URL); err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		req.YoutubeId = source.ID
+		if req.ExtraTitle == "" {
+			req.ExtraTitle = source.Title
+		}
+	}
 
 	// Enqueue the download request
 	item := DownloadQueueItem{
@@ -586,36 +694,54 @@ func downloadExtraHandler(c *gin.Context) {
 		ExtraType:  req.ExtraType,
 		ExtraTitle: req.ExtraTitle,
 		YouTubeID:  req.YoutubeId,
+		SourceURL:  source.URL,
+		SourceSite: source.Site,
 		QueuedAt:   time.Now(),
 	}
-	AddToDownloadQueue(item, "api")
+	if req.MediaType == MediaTypeTV && req.Season > 0 {
+		item.Season = req.Season
+	}
+	AddToDownloadQueue(item, QueueSourceAPI)
 	TrailarrLog(INFO, "Extras", "[downloadExtraHandler] Enqueued download: mediaType=%s, mediaId=%d, extraType=%s, extraTitle=%s, youtubeId=%s", req.MediaType, req.MediaId, req.ExtraType, req.ExtraTitle, req.YoutubeId)
 
-	// Write .mkv.json meta file for manual download
+	// Write .mkv.json meta file for manual download, unless the file name
+	// depends on the downloaded video and isn't known yet
 	cacheFile, _ := resolveCachePath(req.MediaType)
 	mediaPath, err := FindMediaPathByID(cacheFile, req.MediaId)
-	if err == nil && mediaPath != "" {
-		extraDir := mediaPath + "/" + req.ExtraType
+	namingCfg, _ := GetNamingConfig()
+	if err == nil && mediaPath != "" && !templateUsesDownloadedVideoTokens(namingCfg.Template) {
+		if item.Season > 0 {
+			mediaPath = seasonFolder(mediaPath, item.Season)
+		}
+		extraDir, extraFile := resolveExtraOutput(namingCfg, mediaPath, namingValues{
+			MediaTitle: lookupMediaTitle(cacheFile, req.MediaId),
+			Year:       lookupMediaYear(cacheFile, req.MediaId),
+			ExtraType:  canonicalizeExtraType(req.ExtraType),
+			ExtraTitle: req.ExtraTitle,
+			YouTubeID:  req.YoutubeId,
+		})
 		if err := os.MkdirAll(extraDir, 0775); err == nil {
-			metaFile := extraDir + "/" + SanitizeFilename(req.ExtraTitle) + mkvJSONSuffix
+			metaFile := strings.TrimSuffix(extraFile, ".mkv") + mkvJSONSuffix
 			meta := struct {
 				ExtraType  string `json:"extraType"`
 				ExtraTitle string `json:"extraTitle"`
 				FileName   string `json:"fileName"`
 				YoutubeId  string `json:"youtubeId"`
+				SourceURL  string `json:"sourceUrl,omitempty"`
 				Status     string `json:"status"`
 			}{
 				ExtraType:  req.ExtraType,
 				ExtraTitle: req.ExtraTitle,
-				FileName:   SanitizeFilename(req.ExtraTitle) + ".mkv",
+				FileName:   filepath.Base(extraFile),
 				YoutubeId:  req.YoutubeId,
+				SourceURL:  source.URL,
 				Status:     "queued",
 			}
 			// Use shared helper to write JSON with indentation
 			_ = WriteJSONFile(metaFile, meta)
 		}
 	}
-	respondJSON(c, http.StatusOK, gin.H{"status": "queued"})
+	respondJSON(c, http.StatusOK, gin.H{"status": "queued", "youtubeId": req.YoutubeId})
 }
 
 // shouldDownloadExtra determines if an extra should be downloaded
@@ -632,44 +758,27 @@ func shouldDownloadExtra(extra Extra, config ExtraTypesConfig) bool {
 
 // handleExtraDownload downloads an extra unless it's rejected
 func handleExtraDownload(mediaType MediaType, mediaId int, extra Extra) error {
+	return enqueueExtraDownload(mediaType, mediaId, extra, QueueSourceTask)
+}
+
+// enqueueExtraDownload queues an extra unless it's rejected; source sets its queue priority
+func enqueueExtraDownload(mediaType MediaType, mediaId int, extra Extra, source string) error {
 	if extra.Status == "rejected" {
 		TrailarrLog(INFO, "DownloadMissingExtras", "Skipping rejected extra: mediaType=%v, mediaId=%v, extraType=%s, extraTitle=%s, youtubeId=%s", mediaType, mediaId, extra.ExtraType, extra.ExtraTitle, extra.YoutubeId)
 		return nil
 	}
 	// Enqueue the extra for download using the queue system
-	item := DownloadQueueItem{
-		MediaType:  mediaType,
-		MediaId:    mediaId,
-		ExtraType:  extra.ExtraType,
-		ExtraTitle: extra.ExtraTitle,
-		YouTubeID:  extra.YoutubeId,
-		QueuedAt:   time.Now(),
-	}
-	AddToDownloadQueue(item, "task")
+	AddToDownloadQueue(newExtraQueueItem(mediaType, mediaId, extra), source)
 	TrailarrLog(INFO, "QUEUE", "[handleExtraDownload] Enqueued extra: mediaType=%v, mediaId=%v, extraType=%s, extraTitle=%s, youtubeId=%s", mediaType, mediaId, extra.ExtraType, extra.ExtraTitle, extra.YoutubeId)
 	return nil
 }
 
 // Scans a media path and returns a map of existing extras (type|title)
+// Extras are recognised in every naming scheme, keyed by canonical type.
 func ScanExistingExtras(mediaPath string) map[string]bool {
 	existing := map[string]bool{}
-	if mediaPath == "" {
-		return existing
-	}
-	subdirs, err := ListSubdirectories(mediaPath)
-	if err != nil {
-		return existing
-	}
-	for _, subdir := range subdirs {
-		dirName := filepath.Base(subdir)
-		files, _ := os.ReadDir(subdir)
-		for _, f := range files {
-			if !f.IsDir() && strings.HasSuffix(f.Name(), ".mkv") {
-				title := strings.TrimSuffix(f.Name(), ".mkv")
-				key := dirName + "|" + title
-				existing[key] = true
-			}
-		}
+	for _, f := range listExtraFiles(mediaPath) {
+		existing[f.ExtraType+"|"+f.Title] = true
 	}
 	return existing
 }
@@ -685,7 +794,7 @@ func MarkDownloadedExtras(extras []Extra, mediaPath string, typeKey, titleKey st
 		title := SanitizeFilename(extras[i].ExtraTitle)
 		key := typeStr + "|" + title
 		extras[i].Status = "missing"
-		if existing[key] {
+		if existing[key] || (typeStr == string(ThemeMusic) && hasThemeMusic(mediaPath)) {
 			extras[i].Status = "downloaded"
 		}
 	}
@@ -746,6 +855,11 @@ func DownloadMissingExtras(mediaType MediaType, cacheFile string) error {
 
 // filterAndDownloadExtras filters extras and downloads them if enabled
 func filterAndDownloadExtras(mediaType MediaType, mediaId int, extras []Extra, config ExtraTypesConfig) {
+	filterAndEnqueueExtras(mediaType, mediaId, extras, config, QueueSourceTask)
+}
+
+// filterAndEnqueueExtras is filterAndDownloadExtras with an explicit queue source
+func filterAndEnqueueExtras(mediaType MediaType, mediaId int, extras []Extra, config ExtraTypesConfig, source string) {
 	// Mark extras as rejected if their YouTube ID matches any in rejected_extras.json
 	rejectedExtras := GetRejectedExtrasForMedia(mediaType, mediaId)
 	rejectedYoutubeIds := make(map[string]struct{})
@@ -753,8 +867,8 @@ func filterAndDownloadExtras(mediaType MediaType, mediaId int, extras []Extra, c
 		rejectedYoutubeIds[r.YoutubeId] = struct{}{}
 	}
 	MarkRejectedExtrasInMemory(extras, rejectedYoutubeIds)
-	// Filter extras according to config and status
-	filtered := Filter(extras, func(extra Extra) bool {
+	// Filter extras according to config, status and language preferences
+	filtered := Filter(applyLanguagePreferences(mediaType, extras), func(extra Extra) bool {
 		return shouldDownloadExtra(extra, config)
 	})
 	// Debug: log what will be processed
@@ -772,7 +886,7 @@ func filterAndDownloadExtras(mediaType MediaType, mediaId int, extras []Extra, c
 	}
 	for _, extra := range filtered {
 		TrailarrLog(INFO, "Extras", "Enqueuing extra for mediaType=%v id=%d youtubeId=%s title=%s", mediaType, mediaId, extra.YoutubeId, extra.ExtraTitle)
-		err := handleExtraDownload(mediaType, mediaId, extra)
+		err := enqueueExtraDownload(mediaType, mediaId, extra, source)
 		if err != nil {
 			TrailarrLog(WARN, "Extras", "Failed to download extra: %v", err)
 		}
@@ -825,6 +939,16 @@ func scanExtrasInfo(mediaPath string) map[string][]map[string]interface{} {
 			}
 		}
 	}
+	// Jellyfin-style extras live next to the media file
+	for _, f := range listExtraFiles(mediaPath) {
+		if filepath.Dir(f.Path) != filepath.Clean(mediaPath) {
+			continue
+		}
+		var meta map[string]interface{}
+		if err := ReadJSONFile(strings.TrimSuffix(f.Path, ".mkv")+mkvJSONSuffix, &meta); err == nil {
+			extrasInfo[f.ExtraType] = append(extrasInfo[f.ExtraType], canonicalizeMeta(meta))
+		}
+	}
 	return extrasInfo
 }
 
@@ -851,6 +975,19 @@ func storeRejectedIndexInMemory(items []ExtrasEntry) {
 	rejectedIndexMem = out
 }
 
+// indexUpdates tracks the wanted and rejected index refreshes running in the
+// background, so they can be waited for before the store or config changes.
+var indexUpdates sync.WaitGroup
+
+// updateIndexAsync runs an index refresh in the background, tracked by indexUpdates.
+func updateIndexAsync(fn func()) {
+	indexUpdates.Add(1)
+	go func() {
+		defer indexUpdates.Done()
+		fn()
+	}()
+}
+
 // SaveRejectedIndex builds a lightweight list of rejected extras and persists it
 // to the store so the blacklist handler can serve it quickly.
 func SaveRejectedIndex() error {
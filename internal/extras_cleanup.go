@@ -0,0 +1,295 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Kinds of leftovers found by the extras cleanup.
+const (
+	// store entry whose movie/series is no longer in Radarr/Sonarr
+	CleanupOrphanEntry = "orphan-entry"
+	// file still on disk for an extra that was rejected
+	CleanupRejectedFile = "rejected-file"
+	// .mkv.json sidecar without its video
+	CleanupOrphanSidecar = "orphan-sidecar"
+	// video without a sidecar; reported only, never deleted (see the import task)
+	CleanupUnmanagedVideo = "unmanaged-video"
+	// empty extra type folder such as Trailers/
+	CleanupEmptyDir = "empty-dir"
+)
+
+// CleanupConfig controls the scheduled extras cleanup.
+type CleanupConfig struct {
+	// AutoDelete removes leftovers on each scheduled run instead of only reporting them
+	AutoDelete bool `yaml:"autoDelete" json:"autoDelete"`
+}
+
+func DefaultCleanupConfig() CleanupConfig {
+	return CleanupConfig{AutoDelete: false}
+}
+
+// GetCleanupConfig loads the cleanup config from config.yml
+func GetCleanupConfig() (CleanupConfig, error) {
+	cfg := DefaultCleanupConfig()
+	if err := decodeConfigSection("cleanup", &cfg); err != nil {
+		return DefaultCleanupConfig(), err
+	}
+	return cfg, nil
+}
+
+// SaveCleanupConfig saves the cleanup config to config.yml
+func SaveCleanupConfig(cfg CleanupConfig) error {
+	return saveConfigSection("cleanup", cfg)
+}
+
+func ensureCleanupDefaults(config map[string]interface{}) bool {
+	if _, ok := config["cleanup"].(map[string]interface{}); ok {
+		return false
+	}
+	config["cleanup"] = DefaultCleanupConfig()
+	return true
+}
+
+// Handler to get cleanup config
+func GetCleanupConfigHandler(c *gin.Context) {
+	cfg, _ := GetCleanupConfig()
+	respondJSON(c, http.StatusOK, cfg)
+}
+
+// Handler to save cleanup config
+func SaveCleanupConfigHandler(c *gin.Context) {
+	var req CleanupConfig
+	if err := c.BindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	if err := SaveCleanupConfig(req); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
+}
+
+// CleanupFinding is one leftover found by the extras cleanup.
+type CleanupFinding struct {
+	Kind      string    `json:"kind"`
+	Path      string    `json:"path,omitempty"`
+	MediaType MediaType `json:"mediaType,omitempty"`
+	MediaId   int       `json:"mediaId,omitempty"`
+	YoutubeId string    `json:"youtubeId,omitempty"`
+}
+
+// findExtrasCleanup scans the store and every media folder for leftovers.
+func findExtrasCleanup(ctx context.Context) ([]CleanupFinding, error) {
+	findings := []CleanupFinding{}
+	entries, err := GetAllExtras(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mediaPaths := map[MediaType]map[int]string{}
+	for _, mediaType := range []MediaType{MediaTypeMovie, MediaTypeTV} {
+		cacheFile, _ := resolveCachePath(mediaType)
+		items, err := loadCache(cacheFile)
+		if err != nil || len(items) == 0 {
+			// Not synced yet: every entry of this type would look orphaned
+			continue
+		}
+		mediaPaths[mediaType] = map[int]string{}
+		for _, item := range items {
+			if id, ok := getMediaID(item); ok {
+				mediaPaths[mediaType][id], _ = item["path"].(string)
+			}
+		}
+	}
+
+	for _, e := range entries {
+		known, synced := mediaPaths[e.MediaType]
+		if !synced {
+			continue
+		}
+		if _, ok := known[e.MediaId]; !ok {
+			findings = append(findings, CleanupFinding{Kind: CleanupOrphanEntry, Path: existingPath(e.FileName), MediaType: e.MediaType, MediaId: e.MediaId, YoutubeId: e.YoutubeId})
+			continue
+		}
+		if e.Status == "rejected" && existingPath(e.FileName) != "" {
+			findings = append(findings, CleanupFinding{Kind: CleanupRejectedFile, Path: e.FileName, MediaType: e.MediaType, MediaId: e.MediaId, YoutubeId: e.YoutubeId})
+		}
+	}
+
+	active := activeQueueYouTubeIDs()
+	scanCfg := loadExtraScanConfig()
+	for mediaType, paths := range mediaPaths {
+		// season extras are only fetched for series
+		scanCfg.Seasons = mediaType == MediaTypeTV
+		for id, mediaPath := range paths {
+			for _, f := range findMediaFolderLeftovers(scanCfg, mediaPath, active) {
+				f.MediaType, f.MediaId = mediaType, id
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings, nil
+}
+
+// findMediaFolderLeftovers finds orphan sidecars, unmanaged videos and empty
+// extra type folders in a media folder. Sidecars of queued downloads are kept.
+func findMediaFolderLeftovers(scanCfg extraScanConfig, mediaPath string, activeIDs map[string]bool) []CleanupFinding {
+	var findings []CleanupFinding
+	if mediaPath == "" {
+		return findings
+	}
+	dirs := []string{mediaPath}
+	subdirs, _ := ListSubdirectories(mediaPath)
+	for _, d := range subdirs {
+		if _, ok := scanCfg.folderType(filepath.Base(d)); ok {
+			dirs = append(dirs, d)
+		}
+	}
+	for i, dir := range dirs {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		if i > 0 && len(files) == 0 {
+			findings = append(findings, CleanupFinding{Kind: CleanupEmptyDir, Path: dir})
+			continue
+		}
+		for _, f := range files {
+			if f.IsDir() || !strings.HasSuffix(f.Name(), mkvJSONSuffix) {
+				continue
+			}
+			sidecar := filepath.Join(dir, f.Name())
+			video := strings.TrimSuffix(sidecar, mkvJSONSuffix) + ".mkv"
+			if existingPath(video) != "" {
+				continue
+			}
+			if meta, ok := readExtraSidecar(video); ok && activeIDs[meta.YouTubeID] {
+				continue
+			}
+			findings = append(findings, CleanupFinding{Kind: CleanupOrphanSidecar, Path: sidecar})
+		}
+	}
+	for _, f := range scanExtraFiles(scanCfg, mediaPath) {
+		if existingPath(strings.TrimSuffix(f.Path, ".mkv")+mkvJSONSuffix) == "" {
+			findings = append(findings, CleanupFinding{Kind: CleanupUnmanagedVideo, Path: f.Path})
+		}
+	}
+	return findings
+}
+
+// activeQueueYouTubeIDs returns the videos queued or downloading right now.
+func activeQueueYouTubeIDs() map[string]bool {
+	ids := map[string]bool{}
+	for _, item := range GetCurrentDownloadQueue() {
+		if item.Status == "queued" || item.Status == "downloading" {
+			ids[item.YouTubeID] = true
+		}
+	}
+	return ids
+}
+
+func existingPath(p string) string {
+	if p == "" {
+		return ""
+	}
+	if _, err := os.Stat(p); err != nil {
+		return ""
+	}
+	return p
+}
+
+// applyExtrasCleanup deletes what the findings point at and returns how many
+// were cleaned up. Unmanaged videos are left alone.
+func applyExtrasCleanup(ctx context.Context, findings []CleanupFinding) int {
+	cleaned := 0
+	refresh := map[string]bool{}
+	for _, f := range findings {
+		var err error
+		switch f.Kind {
+		case CleanupOrphanEntry:
+			removeExtraFile(f.Path)
+			err = RemoveExtra(ctx, f.YoutubeId, f.MediaType, f.MediaId)
+		case CleanupRejectedFile:
+			removeExtraFile(f.Path)
+		case CleanupOrphanSidecar, CleanupEmptyDir:
+			err = os.Remove(f.Path)
+		default:
+			continue
+		}
+		if err != nil {
+			TrailarrLog(WARN, "Cleanup", "Failed to clean up %s %s: %v", f.Kind, f.Path, err)
+			continue
+		}
+		TrailarrLog(INFO, "Cleanup", "Cleaned up %s %s", f.Kind, f.Path)
+		if f.Kind == CleanupRejectedFile {
+			refresh[mediaFolderOf(f.Path)] = true
+		}
+		cleaned++
+	}
+	for folder := range refresh {
+		scheduleLibraryRefresh(folder)
+	}
+	return cleaned
+}
+
+// removeExtraFile removes an extra video and its sidecar if present.
+func removeExtraFile(p string) {
+	if p == "" {
+		return
+	}
+	_ = os.Remove(p)
+	_ = os.Remove(strings.TrimSuffix(p, ".mkv") + mkvJSONSuffix)
+}
+
+// mediaFolderOf returns the media folder of an extra file in a type folder or
+// next to the media.
+func mediaFolderOf(p string) string {
+	dir := filepath.Dir(p)
+	if isKnownExtraType(canonicalTypeForFolder(filepath.Base(dir))) {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// runExtrasCleanupTask is the scheduled cleanup: it always reports leftovers
+// and deletes them when auto-delete is enabled.
+func runExtrasCleanupTask() error {
+	ctx := context.Background()
+	findings, err := findExtrasCleanup(ctx)
+	if err != nil {
+		return err
+	}
+	TrailarrLog(INFO, "Cleanup", "Found %d leftover extras items", len(findings))
+	cfg, _ := GetCleanupConfig()
+	if cfg.AutoDelete && len(findings) > 0 {
+		TrailarrLog(INFO, "Cleanup", "Auto-delete cleaned up %d items", applyExtrasCleanup(ctx, findings))
+	}
+	return nil
+}
+
+// Handler to list leftover extras without deleting anything (dry run)
+func GetExtrasCleanupHandler(c *gin.Context) {
+	findings, err := findExtrasCleanup(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"findings": findings})
+}
+
+// Handler to delete leftover extras now
+func RunExtrasCleanupHandler(c *gin.Context) {
+	findings, err := findExtrasCleanup(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	cleaned := applyExtrasCleanup(c.Request.Context(), findings)
+	respondJSON(c, http.StatusOK, gin.H{"findings": findings, "cleaned": cleaned})
+}
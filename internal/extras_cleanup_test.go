@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type cleanupFixture struct {
+	media, orphanFile, rejectedFile, orphanSidecar, emptyDir, unmanaged, good string
+}
+
+func setupCleanupTest(t *testing.T) cleanupFixture {
+	t.Helper()
+	CreateTempConfig(t)
+	ctx := context.Background()
+	_ = GetStoreClient().Del(ctx, ExtrasStoreKey)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	root := t.TempDir()
+	f := cleanupFixture{media: filepath.Join(root, "Heat (1995)")}
+	if err := SaveMediaToStore(MoviesStoreKey, []map[string]interface{}{{"id": 9301, "title": "Heat", "path": f.media}}); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+	write := func(p, content string) string {
+		_ = os.MkdirAll(filepath.Dir(p), 0o755)
+		_ = os.WriteFile(p, []byte(content), 0o644)
+		return p
+	}
+	f.orphanFile = write(filepath.Join(root, "Gone (2000)", "Trailers", "Old.mkv"), "x")
+	f.rejectedFile = write(filepath.Join(f.media, "Trailers", "Bad.mkv"), "x")
+	write(strings.TrimSuffix(f.rejectedFile, ".mkv")+mkvJSONSuffix, `{"youtubeId":"bad"}`)
+	f.orphanSidecar = write(filepath.Join(f.media, "Trailers", "Lost.mkv.json"), `{"youtubeId":"lost"}`)
+	f.unmanaged = write(filepath.Join(f.media, "Trailers", "Own.mkv"), "x")
+	f.good = write(filepath.Join(f.media, "Trailers", "Good.mkv"), "x")
+	write(strings.TrimSuffix(f.good, ".mkv")+mkvJSONSuffix, `{"youtubeId":"good"}`)
+	f.emptyDir = filepath.Join(f.media, "Featurettes")
+	_ = os.MkdirAll(f.emptyDir, 0o755)
+	_ = os.MkdirAll(filepath.Join(f.media, "Season 1"), 0o755) // not an extras folder
+
+	for _, e := range []ExtrasEntry{
+		{MediaType: MediaTypeMovie, MediaId: 9399, ExtraType: "Trailers", ExtraTitle: "Old", YoutubeId: "old", FileName: f.orphanFile, Status: "downloaded"},
+		{MediaType: MediaTypeMovie, MediaId: 9301, ExtraType: "Trailers", ExtraTitle: "Bad", YoutubeId: "bad", FileName: f.rejectedFile, Status: "rejected"},
+		{MediaType: MediaTypeMovie, MediaId: 9301, ExtraType: "Trailers", ExtraTitle: "Good", YoutubeId: "good", FileName: f.good, Status: "downloaded"},
+	} {
+		if err := AddOrUpdateExtra(ctx, e); err != nil {
+			t.Fatalf("add extra: %v", err)
+		}
+	}
+	return f
+}
+
+func cleanupKinds(findings []CleanupFinding) map[string]string {
+	kinds := map[string]string{}
+	for _, f := range findings {
+		kinds[f.Kind] = f.Path
+	}
+	return kinds
+}
+
+func TestExtrasCleanupDryRunReportsLeftovers(t *testing.T) {
+	f := setupCleanupTest(t)
+	r := NewTestRouter()
+	r.GET("/api/extras/cleanup", GetExtrasCleanupHandler)
+	w := DoRequest(r, "GET", "/api/extras/cleanup", nil)
+	var resp struct {
+		Findings []CleanupFinding `json:"findings"`
+	}
+	if w.Code != 200 || json.Unmarshal(w.Body.Bytes(), &resp) != nil {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+	want := map[string]string{
+		CleanupOrphanEntry:    f.orphanFile,
+		CleanupRejectedFile:   f.rejectedFile,
+		CleanupOrphanSidecar:  f.orphanSidecar,
+		CleanupUnmanagedVideo: f.unmanaged,
+		CleanupEmptyDir:       f.emptyDir,
+	}
+	got := cleanupKinds(resp.Findings)
+	if len(resp.Findings) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), resp.Findings)
+	}
+	for kind, path := range want {
+		if got[kind] != path {
+			t.Fatalf("expected %s for %s, got %q", path, kind, got[kind])
+		}
+	}
+	if _, err := os.Stat(f.orphanSidecar); err != nil {
+		t.Fatalf("dry run must not delete anything: %v", err)
+	}
+}
+
+func TestExtrasCleanupDeletesLeftovers(t *testing.T) {
+	f := setupCleanupTest(t)
+	ctx := context.Background()
+	findings, err := findExtrasCleanup(ctx)
+	if err != nil {
+		t.Fatalf("find failed: %v", err)
+	}
+	if cleaned := applyExtrasCleanup(ctx, findings); cleaned != 4 {
+		t.Fatalf("expected 4 items cleaned, got %d", cleaned)
+	}
+	for _, p := range []string{f.orphanFile, f.rejectedFile, f.orphanSidecar, f.emptyDir} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("expected %s removed", p)
+		}
+	}
+	for _, p := range []string{f.unmanaged, f.good} {
+		if _, err := os.Stat(p); err != nil {
+			t.Fatalf("expected %s kept: %v", p, err)
+		}
+	}
+	if e, _ := GetExtraByYoutubeId(ctx, "old", MediaTypeMovie, 9399); e != nil {
+		t.Fatalf("expected orphan entry removed from store, got %+v", e)
+	}
+	if e, _ := GetExtraByYoutubeId(ctx, "bad", MediaTypeMovie, 9301); e == nil || e.Status != "rejected" {
+		t.Fatalf("expected rejected entry kept as blacklist, got %+v", e)
+	}
+}
+
+func TestExtrasCleanupTaskHonoursAutoDelete(t *testing.T) {
+	f := setupCleanupTest(t)
+	if err := runExtrasCleanupTask(); err != nil {
+		t.Fatalf("task failed: %v", err)
+	}
+	if _, err := os.Stat(f.orphanSidecar); err != nil {
+		t.Fatalf("expected report-only run without auto-delete: %v", err)
+	}
+	if err := SaveCleanupConfig(CleanupConfig{AutoDelete: true}); err != nil {
+		t.Fatalf("save cleanup config: %v", err)
+	}
+	if err := runExtrasCleanupTask(); err != nil {
+		t.Fatalf("task failed: %v", err)
+	}
+	if _, err := os.Stat(f.orphanSidecar); !os.IsNotExist(err) {
+		t.Fatalf("expected auto-delete to remove the orphan sidecar")
+	}
+}
+
+func TestExtrasCleanupKeepsQueuedSidecars(t *testing.T) {
+	f := setupCleanupTest(t)
+	holdWorkerPool(t)
+	t.Cleanup(func() { _ = GetStoreClient().Del(context.Background(), DownloadQueue) })
+	pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 9301, YouTubeID: "lost", Status: "queued"})
+	findings, _ := findExtrasCleanup(context.Background())
+	if got := cleanupKinds(findings); got[CleanupOrphanSidecar] != "" {
+		t.Fatalf("sidecar of a queued download must be kept, got %s (%s)", got[CleanupOrphanSidecar], f.orphanSidecar)
+	}
+}
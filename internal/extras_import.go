@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importExtrasRunning guards against concurrent library imports.
+var importExtrasRunning atomic.Bool
+
+// bracketedYouTubeIDRe matches yt-dlp's default "<title> [<id>]" file names.
+var bracketedYouTubeIDRe = regexp.MustCompile(`\s*\[([A-Za-z0-9_-]{11})\]`)
+
+// ExtrasImportResult summarises a library import.
+type ExtrasImportResult struct {
+	Scanned      int `json:"scanned"`
+	Imported     int `json:"imported"`
+	Unidentified int `json:"unidentified"`
+}
+
+// importedExtra is an extra file on disk identified as a YouTube video.
+type importedExtra struct {
+	ExtraTitle string
+	YouTubeID  string
+	HasSidecar bool
+}
+
+// importExistingExtras walks every movie and series folder and records extras
+// already on disk as downloaded, so the store and wanted flags match reality.
+func importExistingExtras(ctx context.Context) (ExtrasImportResult, error) {
+	var result ExtrasImportResult
+	scanCfg := loadExtraScanConfig()
+	for _, mediaType := range []MediaType{MediaTypeMovie, MediaTypeTV} {
+		cacheFile, _ := resolveCachePath(mediaType)
+		items, err := loadCache(cacheFile)
+		if err != nil {
+			TrailarrLog(WARN, "Import", "Failed to load %s cache: %v", mediaType, err)
+			continue
+		}
+		changed := false
+		for _, item := range items {
+			mediaId, ok := getMediaID(item)
+			mediaPath, _ := item["path"].(string)
+			if !ok || mediaPath == "" {
+				continue
+			}
+			mediaTitle, _ := item["title"].(string)
+			if importMediaExtras(ctx, mediaType, mediaId, mediaTitle, mediaPath, scanCfg, &result) {
+				changed = true
+			}
+		}
+		if changed {
+			if err := updateWantedStatusInStore(cacheFile); err != nil {
+				TrailarrLog(WARN, "Import", "updateWantedStatusInStore failed for %s: %v", cacheFile, err)
+			}
+		}
+	}
+	TrailarrLog(INFO, "Import", "Scanned %d extras on disk: %d imported, %d unidentified", result.Scanned, result.Imported, result.Unidentified)
+	return result, nil
+}
+
+// importMediaExtras imports the extras of one media folder and reports whether any were added.
+func importMediaExtras(ctx context.Context, mediaType MediaType, mediaId int, mediaTitle, mediaPath string, scanCfg extraScanConfig, result *ExtrasImportResult) bool {
+	changed := false
+	for _, f := range scanExtraFiles(scanCfg, mediaPath) {
+		if !isKnownExtraType(f.ExtraType) {
+			continue
+		}
+		result.Scanned++
+		found, ok := identifyExtraFile(f, scanCfg.Template)
+		if !ok {
+			TrailarrLog(DEBUG, "Import", "No YouTube ID found for %s; skipping", f.Path)
+			result.Unidentified++
+			continue
+		}
+		if existing, err := GetExtraByYoutubeId(ctx, found.YouTubeID, mediaType, mediaId); err == nil && existing != nil &&
+			existing.Status == "downloaded" && existing.FileName == f.Path {
+			continue
+		}
+		entry := ExtrasEntry{
+			MediaType:  mediaType,
+			MediaId:    mediaId,
+			MediaTitle: mediaTitle,
+			ExtraTitle: found.ExtraTitle,
+			ExtraType:  f.ExtraType,
+			FileName:   f.Path,
+			YoutubeId:  found.YouTubeID,
+			Status:     "downloaded",
+		}
+		if err := AddOrUpdateExtra(ctx, entry); err != nil {
+			TrailarrLog(WARN, "Import", "Failed to store imported extra %s: %v", f.Path, err)
+			continue
+		}
+		if !found.HasSidecar {
+			writeMetaFile(&ExtraDownloadMetadata{
+				MediaType:  mediaType,
+				MediaId:    mediaId,
+				MediaTitle: mediaTitle,
+				ExtraType:  f.ExtraType,
+				ExtraTitle: found.ExtraTitle,
+				YouTubeID:  found.YouTubeID,
+				FileName:   f.Path,
+				Status:     "downloaded",
+			}, f.Path)
+		}
+		TrailarrLog(INFO, "Import", "Imported %s %s (%s) from %s", f.ExtraType, found.ExtraTitle, found.YouTubeID, f.Path)
+		result.Imported++
+		changed = true
+	}
+	return changed
+}
+
+// identifyExtraFile finds the YouTube video behind an extra file from, in
+// order, its .mkv.json sidecar, a yt-dlp .info.json, the filename template
+// or a "[<id>]" in the file name.
+func identifyExtraFile(f extraFileOnDisk, tpl string) (importedExtra, bool) {
+	stem := strings.TrimSuffix(f.Path, ".mkv")
+	if meta, ok := readExtraSidecar(f.Path); ok && meta.YouTubeID != "" {
+		title := meta.ExtraTitle
+		if title == "" {
+			title = f.Title
+		}
+		return importedExtra{ExtraTitle: title, YouTubeID: meta.YouTubeID, HasSidecar: true}, true
+	}
+	var info struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	}
+	if err := ReadJSONFile(stem+".info.json", &info); err == nil && info.ID != "" {
+		title := info.Title
+		if title == "" {
+			title = f.Title
+		}
+		return importedExtra{ExtraTitle: title, YouTubeID: info.ID}, true
+	}
+	name := strings.TrimSuffix(filepath.Base(f.Path), ".mkv")
+	if templateUsesToken(tpl, TokenYouTubeID) {
+		if values, ok := parseNamingTemplateTokens(tpl, name); ok && values[TokenYouTubeID] != "" {
+			return importedExtra{ExtraTitle: f.Title, YouTubeID: values[TokenYouTubeID]}, true
+		}
+	}
+	if m := bracketedYouTubeIDRe.FindStringSubmatch(f.Title); m != nil {
+		title := strings.TrimSpace(bracketedYouTubeIDRe.ReplaceAllString(f.Title, ""))
+		return importedExtra{ExtraTitle: title, YouTubeID: m[1]}, true
+	}
+	return importedExtra{}, false
+}
+
+// runImportExtrasTask is the on-demand "importExtras" task.
+func runImportExtrasTask() error {
+	if !importExtrasRunning.CompareAndSwap(false, true) {
+		return errors.New("import already running")
+	}
+	defer importExtrasRunning.Store(false)
+	_, err := importExistingExtras(context.Background())
+	return err
+}
+
+// Handler to start importing extras found on disk
+func ImportExtrasHandler(c *gin.Context) {
+	if importExtrasRunning.Load() {
+		respondError(c, http.StatusConflict, "import already running")
+		return
+	}
+	go tasksMeta["importExtras"].Function()
+	respondJSON(c, http.StatusAccepted, gin.H{"status": "started"})
+}
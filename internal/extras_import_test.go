@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportExistingExtras(t *testing.T) {
+	CreateTempConfig(t)
+	ctx := context.Background()
+	_ = GetStoreClient().Del(ctx, ExtrasStoreKey)
+	media := filepath.Join(t.TempDir(), "Ronin (1998)")
+	if err := SaveMediaToStore(MoviesStoreKey, []map[string]interface{}{{"id": 9201, "title": "Ronin", "path": media}}); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+	_ = SaveMediaToStore(SeriesStoreKey, []map[string]interface{}{})
+
+	write := func(rel, content string) string {
+		p := filepath.Join(media, rel)
+		_ = os.MkdirAll(filepath.Dir(p), 0o755)
+		_ = os.WriteFile(p, []byte(content), 0o644)
+		return p
+	}
+	bracketed := write("Trailers/Teaser [dQw4w9WgXcQ].mkv", "x")
+	infoJSON := write("Featurettes/Car Chase.mkv", "x")
+	write("Featurettes/Car Chase.info.json", `{"id":"abcdefghijk","title":"The Car Chase"}`)
+	write("Trailers/Unknown.mkv", "x")
+	write("Season 1/Episode [zzzzzzzzzzz].mkv", "x") // not an extras folder
+
+	result, err := importExistingExtras(ctx)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if result.Scanned != 3 || result.Imported != 2 || result.Unidentified != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	entry, err := GetExtraByYoutubeId(ctx, "dQw4w9WgXcQ", MediaTypeMovie, 9201)
+	if err != nil || entry == nil || entry.Status != "downloaded" || entry.ExtraTitle != "Teaser" || entry.ExtraType != "Trailers" || entry.FileName != bracketed {
+		t.Fatalf("unexpected bracketed entry: %+v %v", entry, err)
+	}
+	entry, err = GetExtraByYoutubeId(ctx, "abcdefghijk", MediaTypeMovie, 9201)
+	if err != nil || entry == nil || entry.ExtraTitle != "The Car Chase" || entry.FileName != infoJSON {
+		t.Fatalf("unexpected info.json entry: %+v %v", entry, err)
+	}
+	if meta, ok := readExtraSidecar(bracketed); !ok || meta.YouTubeID != "dQw4w9WgXcQ" {
+		t.Fatalf("expected sidecar written for imported file, got %+v", meta)
+	}
+	if got := ScanExistingExtras(media); !got["Trailers|Teaser"] || !got["Featurettes|The Car Chase"] {
+		t.Fatalf("expected imported extras recognised by title, got %v", got)
+	}
+
+	// A second run finds nothing new
+	again, _ := importExistingExtras(ctx)
+	if again.Imported != 0 {
+		t.Fatalf("expected re-import to be a no-op, got %+v", again)
+	}
+}
+
+func TestIdentifyExtraFileByTemplate(t *testing.T) {
+	f := extraFileOnDisk{ExtraType: "Trailers", Title: "Teaser", Path: filepath.Join(t.TempDir(), "Teaser - LjLamj-b0I8.mkv")}
+	found, ok := identifyExtraFile(f, "{ExtraTitle} - {YouTubeId}")
+	if !ok || found.YouTubeID != "LjLamj-b0I8" || found.ExtraTitle != "Teaser" {
+		t.Fatalf("unexpected identification: %+v %v", found, ok)
+	}
+}
+
+func TestImportExtrasIsOnDemandTask(t *testing.T) {
+	meta, ok := tasksMeta["importExtras"]
+	if !ok || meta.Function == nil {
+		t.Fatalf("expected importExtras registered as a task")
+	}
+	for _, bg := range buildBgTasks(TaskStates{}) {
+		if bg.id == "importExtras" {
+			t.Fatalf("importExtras must not be scheduled")
+		}
+	}
+	importExtrasRunning.Store(true)
+	defer importExtrasRunning.Store(false)
+	if err := runImportExtrasTask(); err == nil {
+		t.Fatalf("expected a second import run to be refused")
+	}
+}
@@ -39,7 +39,9 @@ func TestShouldDownloadExtra(t *testing.T) {
 func TestHandleExtraDownloadEnqueues(t *testing.T) {
 	ctx := context.Background()
 	// purge queue
+	holdWorkerPool(t)
 	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	t.Cleanup(func() { _ = GetStoreClient().Del(ctx, DownloadQueue) })
 	e := Extra{Status: "missing", YoutubeId: "q1", ExtraType: "Trailers", ExtraTitle: "T"}
 	// call handleExtraDownload - should enqueue via AddToDownloadQueue
 	if err := handleExtraDownload(MediaTypeMovie, 1, e); err != nil {
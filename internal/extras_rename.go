@@ -0,0 +1,261 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// renameExtrasRunning guards against two rename runs moving the same files.
+var renameExtrasRunning atomic.Bool
+
+// ExtraRename is one downloaded extra whose file no longer matches the naming
+// settings, with the path it moves to.
+type ExtraRename struct {
+	MediaType  MediaType `json:"mediaType"`
+	MediaId    int       `json:"mediaId"`
+	ExtraType  string    `json:"extraType"`
+	ExtraTitle string    `json:"extraTitle"`
+	YoutubeId  string    `json:"youtubeId"`
+	MediaPath  string    `json:"mediaPath"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	// Conflict explains why the file can't be moved; such renames are skipped
+	Conflict string `json:"conflict,omitempty"`
+}
+
+// planExtraRenames computes the new path of every downloaded extra under the
+// current naming scheme, filename template and extra type mapping.
+func planExtraRenames(ctx context.Context) ([]ExtraRename, error) {
+	entries, err := GetAllExtras(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _ := GetNamingConfig()
+	claimed := map[string]bool{}
+	renames := []ExtraRename{}
+	for _, entry := range entries {
+		if entry.Status != "downloaded" {
+			continue
+		}
+		r, ok := planExtraRename(cfg, entry, claimed)
+		if !ok {
+			continue
+		}
+		claimed[r.To] = true
+		renames = append(renames, r)
+	}
+	return renames, nil
+}
+
+// planExtraRename plans the move of one extra. Theme music is theme.mp3 in the
+// media folder whatever the naming settings, so only .mkv video extras move;
+// season extras stay in their season folder.
+func planExtraRename(cfg NamingConfig, entry ExtrasEntry, claimed map[string]bool) (ExtraRename, bool) {
+	if isThemeMusic(entry.ExtraType) {
+		return ExtraRename{}, false
+	}
+	cacheFile, _ := resolveCachePath(entry.MediaType)
+	mediaPath, err := FindMediaPathByID(cacheFile, entry.MediaId)
+	if err != nil || mediaPath == "" {
+		return ExtraRename{}, false
+	}
+	if entry.Season > 0 {
+		mediaPath = seasonFolder(mediaPath, entry.Season)
+	}
+	from := locateExtraFile(mediaPath, entry)
+	if from == "" || !strings.EqualFold(filepath.Ext(from), ".mkv") {
+		return ExtraRename{}, false
+	}
+	meta, _ := readExtraSidecar(from)
+	values := namingValues{
+		MediaTitle: lookupMediaTitle(cacheFile, entry.MediaId),
+		Year:       lookupMediaYear(cacheFile, entry.MediaId),
+		ExtraType:  canonicalizeExtraType(entry.ExtraType),
+		ExtraTitle: entry.ExtraTitle,
+		YouTubeID:  entry.YoutubeId,
+		Resolution: meta.Resolution,
+		Language:   meta.Language,
+	}
+	_, base := extraOutputPath(cfg.Scheme, mediaPath, values.ExtraType, renderNamingTemplate(cfg.Template, values), "mkv")
+	to := resolveExtraFileCollision(base, entry.YoutubeId)
+	for n := 2; claimed[to]; n++ {
+		to = resolveExtraFileCollision(numberedExtraFile(base, n), entry.YoutubeId)
+	}
+	if to == from {
+		return ExtraRename{}, false
+	}
+	r := ExtraRename{
+		MediaType:  entry.MediaType,
+		MediaId:    entry.MediaId,
+		ExtraType:  entry.ExtraType,
+		ExtraTitle: entry.ExtraTitle,
+		YoutubeId:  entry.YoutubeId,
+		MediaPath:  mediaPath,
+		From:       from,
+		To:         to,
+	}
+	if _, err := os.Stat(to); err == nil {
+		r.Conflict = "target file already exists"
+	}
+	return r, true
+}
+
+// locateExtraFile finds the file of a downloaded extra: the recorded file name,
+// else a file with its YouTube ID, else where a naming scheme would put it.
+func locateExtraFile(mediaPath string, entry ExtrasEntry) string {
+	if entry.FileName != "" {
+		if _, err := os.Stat(entry.FileName); err == nil {
+			return entry.FileName
+		}
+	}
+	for _, f := range listExtraFiles(mediaPath) {
+		if f.YouTubeID == entry.YoutubeId {
+			return f.Path
+		}
+	}
+	for _, p := range extraFileCandidates(mediaPath, entry.ExtraType, SanitizeFilename(entry.ExtraTitle)) {
+		if _, err := os.Stat(p); err == nil && extraFileYouTubeID(p) == "" {
+			return p
+		}
+	}
+	return ""
+}
+
+// renameExtras moves every planned extra and its sidecar, updating the store.
+// Renames with a conflict are skipped and reported in the log.
+func renameExtras(ctx context.Context) error {
+	renames, err := planExtraRenames(ctx)
+	if err != nil {
+		return err
+	}
+	moved, failed := 0, 0
+	refresh := map[string]bool{}
+	for _, r := range renames {
+		if r.Conflict != "" {
+			TrailarrLog(WARN, "Rename", "Skipping %s: %s (%s)", r.From, r.Conflict, r.To)
+			failed++
+			continue
+		}
+		if err := applyExtraRename(ctx, r); err != nil {
+			TrailarrLog(WARN, "Rename", "Failed to move %s to %s: %v", r.From, r.To, err)
+			failed++
+			continue
+		}
+		TrailarrLog(INFO, "Rename", "Moved %s to %s", r.From, r.To)
+		refresh[r.MediaPath] = true
+		moved++
+	}
+	for mediaPath := range refresh {
+		scheduleLibraryRefresh(mediaPath)
+	}
+	TrailarrLog(INFO, "Rename", "Renamed %d extras, %d skipped or failed", moved, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d extras could not be renamed", failed, len(renames))
+	}
+	return nil
+}
+
+func applyExtraRename(ctx context.Context, r ExtraRename) error {
+	if _, err := os.Stat(r.To); err == nil {
+		return fmt.Errorf("target file already exists")
+	}
+	if err := os.MkdirAll(filepath.Dir(r.To), 0775); err != nil {
+		return err
+	}
+	if err := moveFileAtomic(r.From, r.To); err != nil {
+		return err
+	}
+	fromMeta := strings.TrimSuffix(r.From, ".mkv") + mkvJSONSuffix
+	toMeta := strings.TrimSuffix(r.To, ".mkv") + mkvJSONSuffix
+	if _, err := os.Stat(fromMeta); err == nil {
+		if err := moveFileAtomic(fromMeta, toMeta); err != nil {
+			TrailarrLog(WARN, "Rename", "Failed to move sidecar %s: %v", fromMeta, err)
+		} else {
+			updateSidecarFileName(toMeta, r.From, r.To)
+		}
+	}
+	// Drop the old type folder once it's empty; Remove fails if it isn't
+	if dir := filepath.Dir(r.From); filepath.Clean(dir) != filepath.Clean(r.MediaPath) {
+		_ = os.Remove(dir)
+	}
+
+	entry, err := GetExtraByYoutubeId(ctx, r.YoutubeId, r.MediaType, r.MediaId)
+	if err != nil || entry == nil {
+		return nil
+	}
+	entry.FileName = r.To
+	return AddOrUpdateExtra(ctx, *entry)
+}
+
+// moveFileAtomic renames from to to. Across devices it copies to a temporary
+// file next to to and renames that into place, so to never appears half-written.
+func moveFileAtomic(from, to string) error {
+	err := os.Rename(from, to)
+	if err == nil {
+		return nil
+	}
+	if linkErr, ok := err.(*os.LinkError); !ok || !strings.Contains(linkErr.Error(), "cross-device link") {
+		return err
+	}
+	partial := to + ".partial"
+	if err := copyFileAcrossDevices(from, partial); err != nil {
+		_ = os.Remove(partial)
+		return err
+	}
+	return os.Rename(partial, to)
+}
+
+// updateSidecarFileName points a moved sidecar's file name at the new file,
+// keeping whether it recorded a full path or just the base name.
+func updateSidecarFileName(metaFile, from, to string) {
+	var meta map[string]interface{}
+	if err := ReadJSONFile(metaFile, &meta); err != nil {
+		return
+	}
+	for _, key := range []string{"FileName", "fileName"} {
+		switch meta[key] {
+		case from:
+			meta[key] = to
+		case filepath.Base(from):
+			meta[key] = filepath.Base(to)
+		}
+	}
+	_ = WriteJSONFile(metaFile, meta)
+}
+
+// Handler to preview extras renames (dry run)
+func GetExtraRenamesHandler(c *gin.Context) {
+	renames, err := planExtraRenames(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"renames": renames})
+}
+
+// runRenameExtrasTask is the on-demand "renameExtras" task.
+func runRenameExtrasTask() error {
+	if !renameExtrasRunning.CompareAndSwap(false, true) {
+		return errors.New("rename already running")
+	}
+	defer renameExtrasRunning.Store(false)
+	return renameExtras(context.Background())
+}
+
+// Handler to start renaming extras to the current naming settings
+func RenameExtrasHandler(c *gin.Context) {
+	if renameExtrasRunning.Load() {
+		respondError(c, http.StatusConflict, "rename already running")
+		return
+	}
+	go tasksMeta["renameExtras"].Function()
+	respondJSON(c, http.StatusAccepted, gin.H{"status": "started"})
+}
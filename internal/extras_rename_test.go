@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupRenameTest stores one movie with a downloaded Plex-style trailer and
+// switches naming to the Jellyfin scheme.
+func setupRenameTest(t *testing.T) (string, string) {
+	t.Helper()
+	CreateTempConfig(t)
+	ctx := context.Background()
+	_ = GetStoreClient().Del(ctx, ExtrasStoreKey)
+	media := filepath.Join(t.TempDir(), "Heat (1995)")
+	if err := SaveMediaToStore(MoviesStoreKey, []map[string]interface{}{{"id": 9101, "title": "Heat", "path": media}}); err != nil {
+		t.Fatalf("save media: %v", err)
+	}
+	file := filepath.Join(media, "Trailers", "Teaser.mkv")
+	_ = os.MkdirAll(filepath.Dir(file), 0o755)
+	_ = os.WriteFile(file, []byte("video"), 0o644)
+	_ = WriteJSONFile(strings.TrimSuffix(file, ".mkv")+mkvJSONSuffix, map[string]string{"ExtraTitle": "Teaser", "YouTubeID": "vid1", "FileName": file})
+	if err := AddOrUpdateExtra(ctx, ExtrasEntry{MediaType: MediaTypeMovie, MediaId: 9101, ExtraType: "Trailers", ExtraTitle: "Teaser", YoutubeId: "vid1", FileName: file, Status: "downloaded"}); err != nil {
+		t.Fatalf("add extra: %v", err)
+	}
+	if err := SaveNamingConfig(NamingConfig{Scheme: NamingSchemeJellyfin, Template: DefaultNamingTemplate}); err != nil {
+		t.Fatalf("save naming: %v", err)
+	}
+	return media, file
+}
+
+func TestExtraRenamesDryRun(t *testing.T) {
+	media, file := setupRenameTest(t)
+	r := NewTestRouter()
+	r.GET("/api/extras/rename", GetExtraRenamesHandler)
+	w := DoRequest(r, "GET", "/api/extras/rename", nil)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	want := filepath.Join(media, "Heat (1995)-Teaser-trailer.mkv")
+	if !strings.Contains(w.Body.String(), `"to":"`+want+`"`) || !strings.Contains(w.Body.String(), `"from":"`+file+`"`) {
+		t.Fatalf("unexpected plan: %s", w.Body.String())
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("dry run must not move files: %v", err)
+	}
+}
+
+func TestRenameExtrasMovesFilesAndUpdatesStore(t *testing.T) {
+	media, file := setupRenameTest(t)
+	ctx := context.Background()
+	if err := renameExtras(ctx); err != nil {
+		t.Fatalf("renameExtras failed: %v", err)
+	}
+	want := filepath.Join(media, "Heat (1995)-Teaser-trailer.mkv")
+	if _, err := os.Stat(want); err != nil {
+		t.Fatalf("expected moved file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(file)); !os.IsNotExist(err) {
+		t.Fatalf("expected empty Trailers folder removed")
+	}
+	var meta map[string]string
+	if err := ReadJSONFile(strings.TrimSuffix(want, ".mkv")+mkvJSONSuffix, &meta); err != nil || meta["FileName"] != want {
+		t.Fatalf("expected sidecar moved and updated, got %v %v", meta, err)
+	}
+	entry, err := GetExtraByYoutubeId(ctx, "vid1", MediaTypeMovie, 9101)
+	if err != nil || entry == nil || entry.FileName != want {
+		t.Fatalf("expected store file name updated, got %+v %v", entry, err)
+	}
+	if got := ScanExistingExtras(media); !got["Trailers|Teaser"] {
+		t.Fatalf("expected moved extra still recognised, got %v", got)
+	}
+	// Nothing left to rename
+	if renames, _ := planExtraRenames(ctx); len(renames) != 0 {
+		t.Fatalf("expected no further renames, got %+v", renames)
+	}
+}
+
+func TestRenameExtrasSkipsConflicts(t *testing.T) {
+	media, file := setupRenameTest(t)
+	occupied := filepath.Join(media, "Heat (1995)-Teaser-trailer.mkv")
+	_ = os.WriteFile(occupied, []byte("someone else's"), 0o644)
+
+	// A video without a sidecar at the target is kept; the extra is numbered
+	renames, err := planExtraRenames(context.Background())
+	numbered := filepath.Join(media, "Heat (1995)-Teaser-trailer (2).mkv")
+	if err != nil || len(renames) != 1 || renames[0].To != numbered || renames[0].Conflict != "" {
+		t.Fatalf("expected a numbered rename, got %+v %v", renames, err)
+	}
+	// A target taken after planning is skipped
+	_ = os.WriteFile(numbered, []byte("someone else's"), 0o644)
+	if err := applyExtraRename(context.Background(), renames[0]); err == nil {
+		t.Fatalf("expected an error reporting the taken target")
+	}
+	for _, p := range []string{occupied, numbered} {
+		if data, _ := os.ReadFile(p); string(data) != "someone else's" {
+			t.Fatalf("existing file %s must not be overwritten", p)
+		}
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("source must stay in place: %v", err)
+	}
+}
+
+func TestRenameExtrasIsOnDemandTask(t *testing.T) {
+	meta, ok := tasksMeta["renameExtras"]
+	if !ok || meta.Function == nil {
+		t.Fatalf("expected renameExtras registered as a task")
+	}
+	for _, bg := range buildBgTasks(TaskStates{}) {
+		if bg.id == "renameExtras" {
+			t.Fatalf("renameExtras must not be scheduled")
+		}
+	}
+	renameExtrasRunning.Store(true)
+	defer renameExtrasRunning.Store(false)
+	if err := runRenameExtrasTask(); err == nil {
+		t.Fatalf("expected a second rename run to be refused")
+	}
+}
+
+func TestRenameKeepsSeasonExtrasInSeasonFolder(t *testing.T) {
+	setupRenameTest(t)
+	ctx := context.Background()
+	series := filepath.Join(t.TempDir(), "Severance")
+	if err := SaveMediaToStore(SeriesStoreKey, []map[string]interface{}{{"id": 9102, "title": "Severance", "path": series}}); err != nil {
+		t.Fatalf("save series: %v", err)
+	}
+	file := filepath.Join(series, "Season 01", "Trailers", "S1 Trailer.mkv")
+	_ = os.MkdirAll(filepath.Dir(file), 0o755)
+	_ = os.WriteFile(file, []byte("video"), 0o644)
+	if err := AddOrUpdateExtra(ctx, ExtrasEntry{MediaType: MediaTypeTV, MediaId: 9102, ExtraType: "Trailers", ExtraTitle: "S1 Trailer", YoutubeId: "vidS1", FileName: file, Season: 1, Status: "downloaded"}); err != nil {
+		t.Fatalf("add extra: %v", err)
+	}
+	renames, _ := planExtraRenames(ctx)
+	for _, r := range renames {
+		if r.YoutubeId == "vidS1" {
+			if filepath.Dir(r.To) != filepath.Join(series, "Season 01") {
+				t.Fatalf("expected the season extra to stay in its season folder, got %s", r.To)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected a rename for the season extra, got %+v", renames)
+}
+
+func TestRenameSkipsThemeMusic(t *testing.T) {
+	media, _ := setupRenameTest(t)
+	ctx := context.Background()
+	theme := filepath.Join(media, themeMusicFile)
+	_ = os.WriteFile(theme, []byte("ID3"), 0o644)
+	if err := AddOrUpdateExtra(ctx, ExtrasEntry{MediaType: MediaTypeMovie, MediaId: 9101, ExtraType: string(ThemeMusic), ExtraTitle: "Theme", YoutubeId: "vidTheme", FileName: theme, Status: "downloaded"}); err != nil {
+		t.Fatalf("add extra: %v", err)
+	}
+	renames, _ := planExtraRenames(ctx)
+	for _, r := range renames {
+		if r.YoutubeId == "vidTheme" {
+			t.Fatalf("theme music must not be renamed, got %+v", r)
+		}
+	}
+	if len(renames) != 1 {
+		t.Fatalf("expected only the trailer renamed, got %+v", renames)
+	}
+}
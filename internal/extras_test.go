@@ -39,13 +39,13 @@ func TestMarkRejectedExtrasInMemory(t *testing.T) {
 
 func TestCollectExistingFromSubdirAndScan(t *testing.T) {
 	tmp := t.TempDir()
-	sub := filepath.Join(tmp, "ExtrasType")
+	sub := filepath.Join(tmp, "Featurettes")
 	_ = os.MkdirAll(sub, 0o755)
 	// create mkv file and meta
 	file := filepath.Join(sub, "MyExtra.mkv")
 	_ = os.WriteFile(file, []byte("dummy"), 0o644)
 	meta := filepath.Join(sub, "MyExtra.mkv.json")
-	_ = os.WriteFile(meta, []byte(`{"extraType":"Type","extraTitle":"MyExtra","fileName":"MyExtra.mkv","youtubeId":"y1","status":"downloaded"}`), 0o644)
+	_ = os.WriteFile(meta, []byte(`{"extraType":"Featurettes","extraTitle":"MyExtra","fileName":"MyExtra.mkv","youtubeId":"y1","status":"downloaded"}`), 0o644)
 
 	dup := make(map[string]int)
 	res := collectExistingFromSubdir(sub, dup)
@@ -63,12 +63,12 @@ func TestMarkDownloadedExtrasAndCanonicalizeMeta(t *testing.T) {
 	// prepare extras and a media path with mkv files
 	tmp := t.TempDir()
 	movieDir := filepath.Join(tmp, "Movie")
-	_ = os.MkdirAll(filepath.Join(movieDir, "Type"), 0o755)
-	_ = os.WriteFile(filepath.Join(movieDir, "Type", "MyExtra.mkv"), []byte(""), 0o644)
+	_ = os.MkdirAll(filepath.Join(movieDir, "Featurettes"), 0o755)
+	_ = os.WriteFile(filepath.Join(movieDir, "Featurettes", "MyExtra.mkv"), []byte(""), 0o644)
 
-	extras := []Extra{{ExtraType: "Type", ExtraTitle: "MyExtra", YoutubeId: "y1"}, {ExtraType: "Other", ExtraTitle: "Nope", YoutubeId: "y2"}}
+	extras := []Extra{{ExtraType: "Featurettes", ExtraTitle: "MyExtra", YoutubeId: "y1"}, {ExtraType: "Other", ExtraTitle: "Nope", YoutubeId: "y2"}}
 	MarkDownloadedExtras(extras, movieDir, "type", "title")
-	// since we placed a file under tmp/Type/MyExtra.mkv, the key should be Type|MyExtra
+	// since we placed a file under tmp/Featurettes/MyExtra.mkv, the key should be Featurettes|MyExtra
 	found := false
 	for _, e := range extras {
 		if e.ExtraTitle == "MyExtra" && e.Status == "downloaded" {
@@ -111,16 +111,12 @@ func TestCanonicalizeExtraTypeMapping(t *testing.T) {
 	tmp := t.TempDir()
 	old := TrailarrRoot
 	TrailarrRoot = tmp
-	oldCfg := ConfigPath
-	ConfigPath = TrailarrRoot + "/config/config.yml"
+	setConfigPathForTest(t, TrailarrRoot+"/config/config.yml")
 	_ = os.MkdirAll(filepath.Dir(ConfigPath), 0o755)
 	// write config with mapping
 	cfg := []byte("canonicalizeExtraType:\n  mapping:\n    OldType: NewType\n")
 	WriteConfig(t, cfg)
-	defer func() {
-		TrailarrRoot = old
-		ConfigPath = oldCfg
-	}()
+	defer func() { TrailarrRoot = old }()
 	got := canonicalizeExtraType("OldType")
 	if got != "NewType" {
 		t.Fatalf("canonicalizeExtraType did not map: %s", got)
@@ -0,0 +1,345 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// upgradeExtrasRunning guards against concurrent upgrade passes.
+var upgradeExtrasRunning atomic.Bool
+
+// UpgradeConfig controls the extras quality upgrade task.
+type UpgradeConfig struct {
+	// CutoffHeight is the resolution (eg. 1080) extras are upgraded towards;
+	// extras recorded at or above it are left alone. 0 disables upgrades.
+	CutoffHeight int `yaml:"cutoffHeight" json:"cutoffHeight"`
+}
+
+func DefaultUpgradeConfig() UpgradeConfig {
+	return UpgradeConfig{CutoffHeight: 1080}
+}
+
+// GetUpgradeConfig loads the upgrade config from config.yml
+func GetUpgradeConfig() (UpgradeConfig, error) {
+	cfg := DefaultUpgradeConfig()
+	if err := decodeConfigSection("upgrade", &cfg); err != nil {
+		return DefaultUpgradeConfig(), err
+	}
+	return cfg, nil
+}
+
+// SaveUpgradeConfig saves the upgrade config to config.yml
+func SaveUpgradeConfig(cfg UpgradeConfig) error {
+	return saveConfigSection("upgrade", cfg)
+}
+
+func ensureUpgradeDefaults(config map[string]interface{}) bool {
+	if _, ok := config["upgrade"].(map[string]interface{}); ok {
+		return false
+	}
+	config["upgrade"] = DefaultUpgradeConfig()
+	return true
+}
+
+// Handler to get upgrade config
+func GetUpgradeConfigHandler(c *gin.Context) {
+	cfg, _ := GetUpgradeConfig()
+	respondJSON(c, http.StatusOK, cfg)
+}
+
+// Handler to save upgrade config
+func SaveUpgradeConfigHandler(c *gin.Context) {
+	var req UpgradeConfig
+	if err := c.BindJSON(&req); err != nil || req.CutoffHeight < 0 {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	if err := SaveUpgradeConfig(req); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
+}
+
+// ExtraUpgrade is a downloaded extra for which a higher resolution is available.
+type ExtraUpgrade struct {
+	MediaType       MediaType `json:"mediaType"`
+	MediaId         int       `json:"mediaId"`
+	ExtraType       string    `json:"extraType"`
+	ExtraTitle      string    `json:"extraTitle"`
+	YoutubeId       string    `json:"youtubeId"`
+	SourceURL       string    `json:"sourceUrl,omitempty"`
+	SourceSite      string    `json:"sourceSite,omitempty"`
+	Season          int       `json:"season,omitempty"`
+	FileName        string    `json:"fileName"`
+	Language        string    `json:"language,omitempty"`
+	CurrentHeight   int       `json:"currentHeight"`
+	AvailableHeight int       `json:"availableHeight"`
+}
+
+// findExtraUpgrades lists downloaded extras recorded below the cutoff whose
+// video is now available in a higher resolution with the requested formats.
+func findExtraUpgrades(ctx context.Context, cutoff int) ([]ExtraUpgrade, error) {
+	upgrades := []ExtraUpgrade{}
+	if cutoff <= 0 {
+		return upgrades, nil
+	}
+	entries, err := GetAllExtras(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return upgrades, ctx.Err()
+		}
+		if e.Status != "downloaded" || e.YoutubeId == "" || existingPath(e.FileName) == "" {
+			continue
+		}
+		// Copies of a collection extra are upgraded along with the collection's extra
+		if e.Source == ExtraSourceCollection {
+			continue
+		}
+		current := recordedExtraHeight(ctx, e)
+		if current == 0 || current >= cutoff {
+			continue
+		}
+		available, err := probeAvailableHeight(ctx, extraInfoURL(e.YoutubeId, e.SourceURL))
+		if err != nil {
+			TrailarrLog(WARN, "Upgrade", "Failed to check formats for %s: %v", e.YoutubeId, err)
+			continue
+		}
+		if available <= current {
+			continue
+		}
+		upgrades = append(upgrades, ExtraUpgrade{
+			MediaType:       e.MediaType,
+			MediaId:         e.MediaId,
+			ExtraType:       e.ExtraType,
+			ExtraTitle:      e.ExtraTitle,
+			YoutubeId:       e.YoutubeId,
+			SourceURL:       e.SourceURL,
+			SourceSite:      e.SourceSite,
+			Season:          e.Season,
+			FileName:        e.FileName,
+			Language:        e.Language,
+			CurrentHeight:   current,
+			AvailableHeight: available,
+		})
+	}
+	return upgrades, nil
+}
+
+// recordedExtraHeight returns the height recorded for a downloaded extra: the
+// probe stored with the entry, the sidecar's resolution, or a fresh probe of
+// the file (saved on the entry for next time). 0 means unknown.
+func recordedExtraHeight(ctx context.Context, e ExtrasEntry) int {
+	if e.Video != nil && e.Video.Height > 0 {
+		return e.Video.Height
+	}
+	if meta, ok := readExtraSidecar(e.FileName); ok {
+		if h, err := strconv.Atoi(strings.TrimSuffix(meta.Resolution, "p")); err == nil && h > 0 {
+			return h
+		}
+	}
+	v, err := probeVideo(e.FileName)
+	if err != nil || v == nil || v.Height == 0 {
+		return 0
+	}
+	e.Video = v
+	if err := AddOrUpdateExtra(ctx, e); err != nil {
+		TrailarrLog(WARN, "Upgrade", "Failed to record probed video for %s: %v", e.FileName, err)
+	}
+	return v.Height
+}
+
+// probeAvailableHeight asks yt-dlp which format it would pick for the video
+// with the configured RequestedFormats and returns its height.
+func probeAvailableHeight(ctx context.Context, videoURL string) (int, error) {
+	cfg, _ := GetYtdlpFlagsConfig()
+	out, err := fetchVideoInfoJSON(ctx, videoURL, "--format", cfg.RequestedFormats)
+	if err != nil {
+		return 0, err
+	}
+	return parseSelectedFormatHeight(out)
+}
+
+// parseSelectedFormatHeight reads the height of the selected format from
+// `yt-dlp -J` output.
+func parseSelectedFormatHeight(out []byte) (int, error) {
+	line, err := videoInfoJSONLine(out)
+	if err != nil {
+		return 0, err
+	}
+	var info struct {
+		Height           int `json:"height"`
+		RequestedFormats []struct {
+			Height int `json:"height"`
+		} `json:"requested_formats"`
+	}
+	if err := json.Unmarshal(line, &info); err != nil {
+		return 0, err
+	}
+	height := info.Height
+	for _, f := range info.RequestedFormats {
+		if f.Height > height {
+			height = f.Height
+		}
+	}
+	return height, nil
+}
+
+// upgradeExtra re-downloads an extra into a temp dir and replaces the current
+// file only when the new download passes validation and really is higher
+// resolution. Failures leave the existing file and entry untouched.
+func upgradeExtra(ctx context.Context, u ExtraUpgrade) error {
+	info, err := prepareDownloadInfo(u.MediaType, u.MediaId, u.ExtraType, u.ExtraTitle, u.YoutubeId)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(info.TempDir)
+	info.Values.Language = u.Language
+	info.SourceURL, info.SourceSite = u.SourceURL, u.SourceSite
+	if u.Season > 0 {
+		useSeasonFolder(info, u.Season)
+	}
+	// Replace the file where it is unless the name depends on the resolution
+	info.OutDir, info.OutFile = filepath.Dir(u.FileName), u.FileName
+
+	if output, err := downloadToTemp(ctx, info, u.YoutubeId); err != nil {
+		if ctx.Err() != nil {
+			return errDownloadCancelled
+		}
+		return fmt.Errorf("download failed: %s", downloadFailureReason(err, output))
+	}
+	if _, err := os.Stat(info.TempFile); err != nil {
+		return fmt.Errorf("yt-dlp did not produce expected output file: %s", info.TempFile)
+	}
+	if err := probeAndValidateDownload(info); err != nil {
+		return err
+	}
+	if info.Video != nil && info.Video.Height <= u.CurrentHeight {
+		return fmt.Errorf("downloaded %dp is not better than %dp", info.Video.Height, u.CurrentHeight)
+	}
+	applyDownloadedVideoTokens(info)
+
+	if err := os.MkdirAll(info.OutDir, 0755); err != nil {
+		return err
+	}
+	if err := moveFileAtomic(info.TempFile, info.OutFile); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", u.FileName, err)
+	}
+	if info.OutFile != u.FileName {
+		removeExtraFile(u.FileName)
+	}
+	recordExtraUpgrade(ctx, info, u)
+	return nil
+}
+
+// recordExtraUpgrade updates the store entry, sidecar and history after an
+// upgrade, and replaces the copies of a collection extra in its movies.
+func recordExtraUpgrade(ctx context.Context, info *downloadInfo, u ExtraUpgrade) {
+	if e, err := GetExtraByYoutubeId(ctx, u.YoutubeId, u.MediaType, u.MediaId); err == nil && e != nil {
+		e.FileName = info.OutFile
+		e.Video = info.Video
+		if err := AddOrUpdateExtra(ctx, *e); err != nil {
+			TrailarrLog(WARN, "Upgrade", "Failed to update extra after upgrade: %v", err)
+		}
+	}
+	writeMetaFile(NewExtraDownloadMetadata(info, u.YoutubeId, "downloaded"), info.OutFile)
+	copyToCollectionMembers(info, u.YoutubeId)
+	mediaTitle := getMediaTitleFromCache(u.MediaType, u.MediaId)
+	if mediaTitle == "" {
+		mediaTitle = "Unknown"
+	}
+	_ = AppendHistoryEvent(HistoryEvent{
+		Action:     "upgrade",
+		MediaTitle: mediaTitle,
+		MediaType:  u.MediaType,
+		MediaId:    u.MediaId,
+		ExtraType:  u.ExtraType,
+		ExtraTitle: u.ExtraTitle,
+		Season:     u.Season,
+		Date:       time.Now(),
+	})
+	scheduleLibraryRefresh(info.MediaPath)
+	TrailarrLog(INFO, "Upgrade", "Upgraded %s from %dp to %s", info.OutFile, u.CurrentHeight, info.Values.Resolution)
+}
+
+// upgradeExtras looks for and applies upgrades, returning how many succeeded.
+func upgradeExtras(ctx context.Context) (int, error) {
+	cfg, _ := GetUpgradeConfig()
+	upgrades, err := findExtraUpgrades(ctx, cfg.CutoffHeight)
+	if err != nil {
+		return 0, err
+	}
+	TrailarrLog(INFO, "Upgrade", "Found %d extras with a higher resolution available", len(upgrades))
+	upgraded := 0
+	for _, u := range upgrades {
+		// Upgrades share the pause and per-host limits of the download queue
+		host := downloadHost(DownloadQueueItem{SourceURL: u.SourceURL})
+		if err := reserveHostSlot(ctx, host); err != nil {
+			if errors.Is(err, errDownloadQueuePaused) {
+				TrailarrLog(INFO, "Upgrade", "Download queue paused; stopping after %d upgrades", upgraded)
+				return upgraded, nil
+			}
+			return upgraded, err
+		}
+		err := upgradeExtra(ctx, u)
+		releaseHostSlot(host)
+		if err != nil {
+			TrailarrLog(WARN, "Upgrade", "Not upgrading %s (%s): %v", u.FileName, u.YoutubeId, err)
+			continue
+		}
+		upgraded++
+	}
+	return upgraded, nil
+}
+
+// runExtrasUpgradeTask is the scheduled upgrade pass.
+func runExtrasUpgradeTask() error {
+	if !upgradeExtrasRunning.CompareAndSwap(false, true) {
+		TrailarrLog(INFO, "Upgrade", "Upgrade already running; skipping")
+		return nil
+	}
+	defer upgradeExtrasRunning.Store(false)
+	_, err := upgradeExtras(context.Background())
+	return err
+}
+
+// Handler to list extras that can be upgraded (dry run)
+func GetExtraUpgradesHandler(c *gin.Context) {
+	cfg, _ := GetUpgradeConfig()
+	upgrades, err := findExtraUpgrades(c.Request.Context(), cfg.CutoffHeight)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"upgrades": upgrades})
+}
+
+// Handler to start an upgrade pass now
+func UpgradeExtrasHandler(c *gin.Context) {
+	if !upgradeExtrasRunning.CompareAndSwap(false, true) {
+		respondError(c, http.StatusConflict, "upgrade already running")
+		return
+	}
+	go func() {
+		defer upgradeExtrasRunning.Store(false)
+		wrapWithQueue("upgrade", func() error {
+			_, err := upgradeExtras(context.Background())
+			return err
+		})()
+	}()
+	respondJSON(c, http.StatusAccepted, gin.H{"status": "started"})
+}
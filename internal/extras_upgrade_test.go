@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// upgradeRunner reports 1080p for `yt-dlp -J` and downloads like fakeRunner.
+type upgradeRunner struct {
+	fakeRunner
+	probed []string
+}
+
+func (r *upgradeRunner) CombinedOutput(ctx context.Context, name string, args []string, dir string) ([]byte, error) {
+	if len(args) > 0 && args[0] == "-J" {
+		r.probed = append(r.probed, args[len(args)-1])
+		return []byte(`{"id":"x","height":1080,"requested_formats":[{"height":1080},{"height":null}]}`), nil
+	}
+	return r.fakeRunner.CombinedOutput(ctx, name, args, dir)
+}
+
+func setupUpgradeTest(t *testing.T, probedHeight int) (*upgradeRunner, string) {
+	t.Helper()
+	CreateTempConfig(t)
+	ctx := context.Background()
+	_ = GetStoreClient().Del(ctx, ExtrasStoreKey)
+	_ = GetStoreClient().Del(ctx, HistoryStoreKey)
+	media := filepath.Join(t.TempDir(), "Alien (1979)")
+	if err := SaveMediaToStore(MoviesStoreKey, []map[string]interface{}{{"id": 9401, "title": "Alien", "path": media}}); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+	low := filepath.Join(media, "Trailers", "Teaser.mkv")
+	high := filepath.Join(media, "Trailers", "Trailer.mkv")
+	_ = os.MkdirAll(filepath.Dir(low), 0o755)
+	_ = os.WriteFile(low, []byte("old"), 0o644)
+	_ = os.WriteFile(high, []byte("old"), 0o644)
+	writeMetaFile(&ExtraDownloadMetadata{YouTubeID: "lowlowlow01", FileName: low, Resolution: "480p"}, low)
+	for _, e := range []ExtrasEntry{
+		{MediaType: MediaTypeMovie, MediaId: 9401, ExtraType: "Trailers", ExtraTitle: "Teaser", YoutubeId: "lowlowlow01", FileName: low, Status: "downloaded"},
+		{MediaType: MediaTypeMovie, MediaId: 9401, ExtraType: "Trailers", ExtraTitle: "Trailer", YoutubeId: "highhigh001", FileName: high, Status: "downloaded", Video: &VideoInfo{Height: 1080, VideoCodec: "vp9"}},
+	} {
+		if err := AddOrUpdateExtra(ctx, e); err != nil {
+			t.Fatalf("add extra: %v", err)
+		}
+	}
+
+	runner := &upgradeRunner{}
+	oldRunner, oldProbe := ytDlpRunner, probeVideo
+	ytDlpRunner = runner
+	probeVideo = func(string) (*VideoInfo, error) {
+		return &VideoInfo{Duration: 120, Width: probedHeight * 16 / 9, Height: probedHeight, VideoCodec: "vp9"}, nil
+	}
+	t.Cleanup(func() { ytDlpRunner, probeVideo = oldRunner, oldProbe })
+	return runner, low
+}
+
+func TestFindExtraUpgradesChecksOnlyExtrasBelowCutoff(t *testing.T) {
+	runner, low := setupUpgradeTest(t, 1080)
+	upgrades, err := findExtraUpgrades(context.Background(), 1080)
+	if err != nil {
+		t.Fatalf("find failed: %v", err)
+	}
+	if len(upgrades) != 1 || upgrades[0].FileName != low || upgrades[0].CurrentHeight != 480 || upgrades[0].AvailableHeight != 1080 {
+		t.Fatalf("unexpected upgrades: %+v", upgrades)
+	}
+	if len(runner.probed) != 1 || !strings.HasSuffix(runner.probed[0], "lowlowlow01") {
+		t.Fatalf("expected only the 480p extra probed, got %v", runner.probed)
+	}
+	if none, _ := findExtraUpgrades(context.Background(), 0); len(none) != 0 {
+		t.Fatalf("cutoff 0 must disable upgrades, got %+v", none)
+	}
+}
+
+func TestUpgradeExtrasReplacesFile(t *testing.T) {
+	_, low := setupUpgradeTest(t, 1080)
+	ctx := context.Background()
+	upgraded, err := upgradeExtras(ctx)
+	if err != nil || upgraded != 1 {
+		t.Fatalf("expected 1 upgrade, got %d %v", upgraded, err)
+	}
+	if b, _ := os.ReadFile(low); string(b) != "dummy" {
+		t.Fatalf("expected file replaced by the new download, got %q", b)
+	}
+	entry, _ := GetExtraByYoutubeId(ctx, "lowlowlow01", MediaTypeMovie, 9401)
+	if entry == nil || entry.FileName != low || entry.Video == nil || entry.Video.Height != 1080 {
+		t.Fatalf("unexpected entry after upgrade: %+v", entry)
+	}
+	events, _ := LoadHistoryEvents()
+	if len(events) != 1 || events[0].Action != "upgrade" || events[0].ExtraTitle != "Teaser" {
+		t.Fatalf("expected an upgrade history event, got %+v", events)
+	}
+}
+
+func TestUpgradeExtrasKeepsFileWhenDownloadIsNotBetter(t *testing.T) {
+	_, low := setupUpgradeTest(t, 480)
+	upgraded, err := upgradeExtras(context.Background())
+	if err != nil || upgraded != 0 {
+		t.Fatalf("expected no upgrade, got %d %v", upgraded, err)
+	}
+	if b, _ := os.ReadFile(low); string(b) != "old" {
+		t.Fatalf("existing file must be kept, got %q", b)
+	}
+	if events, _ := LoadHistoryEvents(); len(events) != 0 {
+		t.Fatalf("expected no history, got %+v", events)
+	}
+}
+
+func TestParseSelectedFormatHeight(t *testing.T) {
+	out := []byte("[youtube] abc: Downloading webpage\n" + `{"height":null,"requested_formats":[{"height":720},{"height":null}]}` + "\n")
+	if h, err := parseSelectedFormatHeight(out); err != nil || h != 720 {
+		t.Fatalf("expected 720, got %d %v", h, err)
+	}
+	if _, err := parseSelectedFormatHeight([]byte("ERROR: unavailable")); err == nil {
+		t.Fatalf("expected error without JSON")
+	}
+}
+
+func TestUpgradeExtrasHonoursQueuePause(t *testing.T) {
+	_, low := setupUpgradeTest(t, 1080)
+	setDownloadQueuePaused(true)
+	t.Cleanup(func() { setDownloadQueuePaused(false) })
+	if upgraded, err := upgradeExtras(context.Background()); err != nil || upgraded != 0 {
+		t.Fatalf("expected no upgrade while paused, got %d %v", upgraded, err)
+	}
+	if b, _ := os.ReadFile(low); string(b) != "old" {
+		t.Fatalf("existing file must be kept while paused, got %q", b)
+	}
+	setDownloadQueuePaused(false)
+
+	// A busy host holds the upgrade until a slot frees up
+	if err := SaveDownloadQueueConfig(DownloadQueueConfig{MaxConcurrent: 1, MaxPerHost: 1}); err != nil {
+		t.Fatalf("save queue config: %v", err)
+	}
+	acquireHostSlot("youtube.com")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := upgradeExtras(ctx); err == nil {
+		t.Fatalf("expected the upgrade to wait for a youtube.com slot")
+	}
+	releaseHostSlot("youtube.com")
+	if upgraded, err := upgradeExtras(context.Background()); err != nil || upgraded != 1 {
+		t.Fatalf("expected the upgrade once a slot is free, got %d %v", upgraded, err)
+	}
+}
@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExtraSourceUpload marks extras uploaded from a local file.
+const ExtraSourceUpload = "upload"
+
+// FfmpegCmd is the ffmpeg binary used to remux uploaded videos to mkv.
+var FfmpegCmd = "ffmpeg"
+
+// remuxToMkv copies the streams of a video into an mkv container. Tests override it.
+var remuxToMkv = runFfmpegRemux
+
+// MaxExtraUploadBytes caps the size of an uploaded extra.
+var MaxExtraUploadBytes int64 = 8 << 30
+
+// uploadVideoExts are the containers accepted for upload; all but mkv are remuxed.
+var uploadVideoExts = []string{".mkv", ".mp4", ".m4v", ".mov", ".avi", ".webm", ".ts", ".m2ts", ".mpg", ".mpeg", ".wmv"}
+
+// UploadExtraHandler adds a local video file as an extra. It takes a
+// multipart form with mediaType, mediaId, extraType, an optional extraTitle
+// (the file name by default) and the video as file.
+func UploadExtraHandler(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxExtraUploadBytes)
+	file, err := c.FormFile("file")
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "missing video file: "+err.Error())
+		return
+	}
+	mediaType := MediaType(c.PostForm("mediaType"))
+	mediaId, _ := strconv.Atoi(c.PostForm("mediaId"))
+	extraType := strings.TrimSpace(c.PostForm("extraType"))
+	if (mediaType != MediaTypeMovie && mediaType != MediaTypeTV) || mediaId <= 0 || extraType == "" {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !slices.Contains(uploadVideoExts, ext) {
+		respondError(c, http.StatusBadRequest, "unsupported video file type: "+ext)
+		return
+	}
+	extraTitle := strings.TrimSpace(c.PostForm("extraTitle"))
+	if extraTitle == "" {
+		extraTitle = strings.TrimSuffix(filepath.Base(file.Filename), filepath.Ext(file.Filename))
+	}
+	if getMediaTitleFromCache(mediaType, mediaId) == "" {
+		respondError(c, http.StatusNotFound, "Media not found")
+		return
+	}
+
+	meta, err := importUploadedExtra(c.Request.Context(), mediaType, mediaId, extraType, extraTitle, file)
+	var invalid *VideoValidationError
+	switch {
+	case errors.As(err, &invalid):
+		respondError(c, http.StatusBadRequest, err.Error())
+	case err != nil:
+		respondError(c, http.StatusInternalServerError, err.Error())
+	default:
+		respondJSON(c, http.StatusOK, meta)
+	}
+}
+
+// importUploadedExtra validates an uploaded video and moves it into the
+// library like a downloaded extra. Uploads are keyed by a hash of their
+// content, so uploading the same file again finds the existing extra.
+func importUploadedExtra(ctx context.Context, mediaType MediaType, mediaId int, extraType, extraTitle string, file *multipart.FileHeader) (*ExtraDownloadMetadata, error) {
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	upload, id, err := saveUploadedFile(file, ext)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(upload)
+
+	info, err := prepareDownloadInfo(mediaType, mediaId, extraType, extraTitle, id)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(info.TempDir)
+	info.Source = ExtraSourceUpload
+	if meta, err := checkExistingExtra(info, id); meta != nil || err != nil {
+		return meta, err
+	}
+
+	if ext == ".mkv" {
+		err = os.Rename(upload, info.TempFile)
+	} else {
+		err = remuxToMkv(ctx, upload, info.TempFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare uploaded video: %w", err)
+	}
+	if err := validateUploadedVideo(info); err != nil {
+		TrailarrLog(WARN, "Upload", "Rejected upload %s for %s %d: %v", file.Filename, mediaType, mediaId, err)
+		return nil, err
+	}
+
+	applyDownloadedVideoTokens(info)
+	if err := moveDownloadedFile(info); err != nil {
+		return nil, err
+	}
+	return createSuccessMetadata(info, id)
+}
+
+// saveUploadedFile copies an upload to a temp file and returns its path and
+// the extra ID derived from its content.
+func saveUploadedFile(file *multipart.FileHeader, ext string) (string, string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer src.Close()
+	_ = os.MkdirAll(TrailarrRoot, 0755)
+	dst, err := os.CreateTemp(TrailarrRoot, "upload-*"+ext)
+	if err != nil {
+		return "", "", err
+	}
+	defer dst.Close()
+	hash := sha1.New()
+	if _, err := io.Copy(io.MultiWriter(dst, hash), src); err != nil {
+		os.Remove(dst.Name())
+		return "", "", fmt.Errorf("failed to save upload: %w", err)
+	}
+	return dst.Name(), "upload-" + hex.EncodeToString(hash.Sum(nil)[:8]), nil
+}
+
+// validateUploadedVideo probes an uploaded video. Unlike downloads, uploads
+// must be readable by ffprobe and have a video stream even when validation
+// is disabled; the configured rules apply on top.
+func validateUploadedVideo(info *downloadInfo) error {
+	v, err := probeVideo(info.TempFile)
+	if err != nil {
+		return &VideoValidationError{Reason: "ffprobe could not read the file: " + err.Error()}
+	}
+	if v == nil {
+		TrailarrLog(WARN, "Upload", "ffprobe not available, accepting %s unchecked", info.TempFile)
+		return nil
+	}
+	info.Video = v
+	if v.VideoCodec == "" || v.Height == 0 {
+		return &VideoValidationError{Reason: "file has no video stream"}
+	}
+	if cfg, _ := GetVideoValidationConfig(); cfg.Enabled {
+		return validateVideo(v, cfg)
+	}
+	return nil
+}
+
+// runFfmpegRemux remuxes from into the mkv file to without re-encoding.
+func runFfmpegRemux(ctx context.Context, from, to string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, FfmpegCmd, "-v", "error", "-y", "-i", from, "-map", "0", "-c", "copy", to).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg remux failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
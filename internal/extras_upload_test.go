@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func uploadRequest(t *testing.T, r *gin.Engine, fields map[string]string, fileName, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		_ = mw.WriteField(k, v)
+	}
+	fw, _ := mw.CreateFormFile("file", fileName)
+	_, _ = fw.Write([]byte(content))
+	_ = mw.Close()
+	req := httptest.NewRequest("POST", "/api/extras/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func setupUploadTest(t *testing.T) (*gin.Engine, string) {
+	t.Helper()
+	CreateTempConfig(t)
+	library := t.TempDir()
+	WriteConfig(t, []byte("radarr:\n  pathMappings:\n    - from: /movies\n      to: "+library+"\n"))
+	ctx := context.Background()
+	_ = GetStoreClient().Del(ctx, HistoryStoreKey)
+	if err := SaveMediaToStore(MoviesStoreKey, []map[string]interface{}{{"id": 9501, "title": "Heat", "path": "/movies/Heat (1995)"}}); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+	oldProbe, oldRemux := probeVideo, remuxToMkv
+	probeVideo = func(string) (*VideoInfo, error) {
+		return &VideoInfo{Duration: 600, Width: 1920, Height: 1080, VideoCodec: "h264", AudioCodec: "ac3"}, nil
+	}
+	remuxToMkv = func(ctx context.Context, from, to string) error {
+		b, err := os.ReadFile(from)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(to, append([]byte("mkv:"), b...), 0o644)
+	}
+	t.Cleanup(func() { probeVideo, remuxToMkv = oldProbe, oldRemux })
+	r := NewTestRouter()
+	r.POST("/api/extras/upload", UploadExtraHandler)
+	return r, filepath.Join(library, "Heat (1995)")
+}
+
+func TestUploadExtraPlacesFileLikeADownload(t *testing.T) {
+	r, media := setupUploadTest(t)
+	w := uploadRequest(t, r, map[string]string{"mediaType": "movie", "mediaId": "9501", "extraType": "Deleted Scenes"}, "Bank Robbery Extended.mp4", "rip")
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	file := filepath.Join(media, "Deleted Scenes", "Bank Robbery Extended.mkv")
+	if b, err := os.ReadFile(file); err != nil || string(b) != "mkv:rip" {
+		t.Fatalf("expected remuxed upload at %s, got %q %v", file, b, err)
+	}
+	var meta ExtraDownloadMetadata
+	if err := ReadJSONFile(file+".json", &meta); err != nil || meta.Video == nil || meta.Video.Height != 1080 {
+		t.Fatalf("expected sidecar with probed video, got %+v %v", meta, err)
+	}
+	entry, _ := GetExtraByYoutubeId(context.Background(), meta.YouTubeID, MediaTypeMovie, 9501)
+	if entry == nil || entry.Status != "downloaded" || entry.Source != ExtraSourceUpload || entry.FileName != file {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	events, _ := LoadHistoryEvents()
+	if len(events) != 1 || events[0].Action != "upload" || events[0].ExtraTitle != "Bank Robbery Extended" {
+		t.Fatalf("expected an upload history event, got %+v", events)
+	}
+
+	// The same file again is recognised instead of added twice
+	w = uploadRequest(t, r, map[string]string{"mediaType": "movie", "mediaId": "9501", "extraType": "Deleted Scenes"}, "Bank Robbery Extended.mp4", "rip")
+	if w.Code != 200 || !bytes.Contains(w.Body.Bytes(), []byte(`"exists"`)) {
+		t.Fatalf("expected existing upload, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUploadExtraRejectsInvalidFiles(t *testing.T) {
+	r, media := setupUploadTest(t)
+	fields := map[string]string{"mediaType": "movie", "mediaId": "9501", "extraType": "Featurettes", "extraTitle": "Making Of"}
+	if w := uploadRequest(t, r, fields, "notes.txt", "text"); w.Code != 400 {
+		t.Fatalf("expected 400 for non-video file, got %d", w.Code)
+	}
+	if w := uploadRequest(t, r, map[string]string{"mediaType": "movie", "mediaId": "1", "extraType": "Featurettes"}, "a.mkv", "x"); w.Code != 404 {
+		t.Fatalf("expected 404 for unknown media, got %d", w.Code)
+	}
+	probeVideo = func(string) (*VideoInfo, error) {
+		return &VideoInfo{Duration: 300, AudioCodec: "flac"}, nil
+	}
+	if w := uploadRequest(t, r, fields, "making-of.mkv", "audio"); w.Code != 400 {
+		t.Fatalf("expected 400 for file without video, got %d %s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(media, "Featurettes", "Making Of.mkv")); !os.IsNotExist(err) {
+		t.Fatalf("rejected upload must not reach the library: %v", err)
+	}
+}
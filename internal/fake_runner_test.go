@@ -8,37 +8,71 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// fakeRunner implements YtDlpRunner for tests.
-type fakeRunner struct{}
+// fakeRunner implements YtDlpRunner for tests. Downloads write a dummy file to
+// the --output path and searches stream two results; the optional fields change
+// those answers, and every search and download is recorded.
+type fakeRunner struct {
+	// searchLines replaces the default search results.
+	searchLines []string
+	// videoInfo answers `yt-dlp -J <url>`.
+	videoInfo func(url string) ([]byte, error)
+
+	mu        sync.Mutex
+	searches  int
+	downloads [][]string
+}
 
 const testYtID = "yt-123"
 
-func (f *fakeRunner) StartCommand(ctx context.Context, name string, args []string) (io.ReadCloser, *exec.Cmd, error) {
-	// produce two JSON lines and then EOF
-	lines := []string{
-		`{"id":"vid1","title":"t1","thumbnail":"th1"}` + "\n",
-		`{"id":"vid2","title":"t2","thumbnail":"th2"}` + "\n",
+func (f *fakeRunner) StartCommand(ctx context.Context, name string, args []string, dir string, stderr io.Writer) (io.ReadCloser, *exec.Cmd, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	// Download invocations carry --output: create the file and report progress
+	if output := fakeArgValue(args, "--output"); output != "" {
+		f.mu.Lock()
+		f.downloads = append(f.downloads, args)
+		f.mu.Unlock()
+		if ext := fakeArgValue(args, "--audio-format"); ext != "" {
+			output = strings.Replace(output, "%(ext)s", ext, 1)
+		}
+		_ = os.MkdirAll(filepath.Dir(output), 0o755)
+		_ = os.WriteFile(output, []byte("dummy"), 0o644)
+		out := ytDlpProgressPrefix + `{"downloaded_bytes":50,"total_bytes":100,"speed":10,"eta":5}` + "\n" +
+			ytDlpProgressPrefix + `{"downloaded_bytes":100,"total_bytes":100,"speed":10,"eta":0}` + "\n" +
+			"[info] download complete\n"
+		return io.NopCloser(bytes.NewBufferString(out)), &exec.Cmd{}, nil
+	}
+	f.mu.Lock()
+	f.searches++
+	lines := f.searchLines
+	f.mu.Unlock()
+	if lines == nil {
+		lines = []string{
+			`{"id":"vid1","title":"t1","thumbnail":"th1"}`,
+			`{"id":"vid2","title":"t2","thumbnail":"th2"}`,
+		}
 	}
-	r := bytes.NewBufferString(strings.Join(lines, ""))
 	// return a simple ReadCloser; the cmd is a placeholder (Wait is ignored in callers)
-	return io.NopCloser(r), &exec.Cmd{}, nil
+	return io.NopCloser(bytes.NewBufferString(strings.Join(lines, "\n") + "\n")), &exec.Cmd{}, nil
 }
 
-func (f *fakeRunner) CombinedOutput(name string, args []string, dir string) ([]byte, error) {
-	// Attempt to locate --output arg to create the temp file
-	outPath := ""
-	for i := 0; i < len(args)-1; i++ {
-		if args[i] == "--output" {
-			outPath = args[i+1]
-			break
-		}
+func (f *fakeRunner) CombinedOutput(ctx context.Context, name string, args []string, dir string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+	if f.videoInfo != nil && len(args) > 0 && args[0] == "-J" {
+		return f.videoInfo(args[len(args)-1])
+	}
+	// Attempt to locate --output arg to create the temp file
+	outPath := fakeArgValue(args, "--output")
 	if outPath == "" {
 		// fallback: create a file in dir
 		outPath = filepath.Join(dir, "fake-output.mkv")
@@ -52,6 +86,30 @@ func (f *fakeRunner) CombinedOutput(name string, args []string, dir string) ([]b
 	return []byte("[info] download complete\n"), nil
 }
 
+// searchCount returns how many searches were started.
+func (f *fakeRunner) searchCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.searches
+}
+
+// downloadArgs returns the arguments of every download started.
+func (f *fakeRunner) downloadArgs() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]string(nil), f.downloads...)
+}
+
+// fakeArgValue returns the value following flag in args.
+func fakeArgValue(args []string, flag string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
 func TestRunYtDlpSearchRealWithFakeRunner(t *testing.T) {
 	old := ytDlpRunner
 	ytDlpRunner = &fakeRunner{}
@@ -61,7 +119,7 @@ func TestRunYtDlpSearchRealWithFakeRunner(t *testing.T) {
 	results := &[]gin.H{}
 	vidSet := map[string]bool{}
 	// give a small timeout context
-	err := runYtDlpSearchReal("term trailer", vidSet, results, 10, []string{"-j", "ytsearch:term trailer", "--skip-download"})
+	err := runYtDlpSearchReal("term trailer", vidSet, results, 10, []string{"-j", "ytsearch:term trailer", "--skip-download"}, searchTarget{})
 	if err != nil {
 		t.Fatalf("runYtDlpSearchReal returned error: %v", err)
 	}
@@ -83,8 +141,8 @@ func TestPerformDownloadWithFakeRunner(t *testing.T) {
 	// Ensure temp dir exists
 	_ = os.MkdirAll(info.TempDir, 0o755)
 
-	// Call performDownload which should call CombinedOutput and then move file
-	meta, err := performDownload(info, testYtID)
+	// Call performDownload which should stream the fake output and then move file
+	meta, err := performDownload(context.Background(), info, testYtID)
 	if err != nil {
 		t.Fatalf("performDownload failed: %v", err)
 	}
@@ -98,3 +156,23 @@ func TestPerformDownloadWithFakeRunner(t *testing.T) {
 		t.Fatalf("expected output file at %s, stat error: %v", info.OutFile, err)
 	}
 }
+
+func TestStartCommandRunsInDirAndCapturesStderr(t *testing.T) {
+	dir := t.TempDir()
+	var stderr bytes.Buffer
+	stdout, cmd, err := (&DefaultYtDlpRunner{}).StartCommand(context.Background(), "sh", []string{"-c", "pwd; echo oops >&2"}, dir, &stderr)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	out, _ := io.ReadAll(stdout)
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(dir)
+	if got, _ := filepath.EvalSymlinks(strings.TrimSpace(string(out))); got != want {
+		t.Fatalf("expected the command to run in %s, got %q", want, out)
+	}
+	if stderr.String() != "oops\n" {
+		t.Fatalf("expected stderr captured, got %q", stderr.String())
+	}
+}
@@ -16,6 +16,7 @@ type HistoryEvent struct {
 	MediaId    int       `json:"mediaId"`
 	ExtraType  string    `json:"extraType"`
 	ExtraTitle string    `json:"extraTitle"`
+	Season     int       `json:"season,omitempty"`
 	Date       time.Time `json:"date"`
 }
 
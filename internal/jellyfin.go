@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jellyfinServers are the config sections of Jellyfin-compatible media servers.
+// Emby shares the same API, so both are refreshed the same way.
+var jellyfinServers = []string{"jellyfin", "emby"}
+
+// JellyfinConfig is a Jellyfin or Emby connection used to refresh items after extras change.
+type JellyfinConfig struct {
+	Enabled      bool                     `yaml:"enabled" json:"enabled"`
+	URL          string                   `yaml:"url" json:"url"`
+	APIKey       string                   `yaml:"apiKey" json:"apiKey"`
+	PathMappings []MediaServerPathMapping `yaml:"pathMappings" json:"pathMappings"`
+}
+
+func DefaultJellyfinConfig() JellyfinConfig {
+	return JellyfinConfig{
+		Enabled:      false,
+		URL:          "http://localhost:8096",
+		APIKey:       "",
+		PathMappings: []MediaServerPathMapping{},
+	}
+}
+
+// GetJellyfinConfig loads the "jellyfin" or "emby" section from config.yml
+func GetJellyfinConfig(server string) (JellyfinConfig, error) {
+	cfg := DefaultJellyfinConfig()
+	if err := decodeConfigSection(server, &cfg); err != nil {
+		return DefaultJellyfinConfig(), err
+	}
+	return cfg, nil
+}
+
+// SaveJellyfinConfig saves the "jellyfin" or "emby" section to config.yml
+func SaveJellyfinConfig(server string, cfg JellyfinConfig) error {
+	return saveConfigSection(server, cfg)
+}
+
+func ensureJellyfinDefaults(config map[string]interface{}) bool {
+	changed := false
+	for _, server := range jellyfinServers {
+		if _, ok := config[server].(map[string]interface{}); ok {
+			continue
+		}
+		config[server] = DefaultJellyfinConfig()
+		changed = true
+	}
+	return changed
+}
+
+// Handler to get a Jellyfin/Emby config
+func GetJellyfinConfigHandler(server string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg, _ := GetJellyfinConfig(server)
+		respondJSON(c, http.StatusOK, cfg)
+	}
+}
+
+// Handler to save a Jellyfin/Emby config
+func SaveJellyfinConfigHandler(server string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req JellyfinConfig
+		if err := c.BindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+			return
+		}
+		req.URL = trimTrailingSlash(strings.TrimSpace(req.URL))
+		if req.Enabled && req.URL == "" {
+			respondError(c, http.StatusBadRequest, server+" url is required")
+			return
+		}
+		if err := SaveJellyfinConfig(server, req); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
+	}
+}
+
+// jellyfinItem is a library item from the Jellyfin/Emby /Items endpoint.
+type jellyfinItem struct {
+	ID   string `json:"Id"`
+	Path string `json:"Path"`
+	Type string `json:"Type"`
+}
+
+// refreshJellyfinFolders refreshes the Jellyfin/Emby movie or series items that
+// live in the given media folders.
+func refreshJellyfinFolders(server string, folders []string) {
+	cfg, err := GetJellyfinConfig(server)
+	if err != nil || !cfg.Enabled {
+		return
+	}
+	mappings, _ := getSectionPathMappings(server)
+	items, err := fetchJellyfinItems(cfg)
+	if err != nil {
+		TrailarrLog(WARN, "MediaServer", "Failed to list %s items: %v", server, err)
+		return
+	}
+	for _, folder := range folders {
+		serverPath := applyPathMappings(folder, mappings)
+		ids := jellyfinItemIDsForFolder(items, serverPath)
+		if len(ids) == 0 {
+			TrailarrLog(DEBUG, "MediaServer", "No %s item found for %s; skipping refresh", server, serverPath)
+			continue
+		}
+		for _, id := range ids {
+			if err := refreshJellyfinItem(cfg, id); err != nil {
+				TrailarrLog(WARN, "MediaServer", "Refresh of %s item %s failed: %v", server, id, err)
+			} else {
+				TrailarrLog(INFO, "MediaServer", "Requested %s refresh of item %s (%s)", server, id, serverPath)
+			}
+		}
+	}
+}
+
+// jellyfinItemIDsForFolder returns the items whose folder is folder. Series
+// report their folder as Path; movies report the video file inside it.
+func jellyfinItemIDsForFolder(items []jellyfinItem, folder string) []string {
+	folder = strings.TrimSuffix(folder, "/")
+	var ids []string
+	for _, it := range items {
+		p := strings.TrimSuffix(it.Path, "/")
+		if p == folder || (it.Type == "Movie" && path.Dir(p) == folder) {
+			ids = append(ids, it.ID)
+		}
+	}
+	return ids
+}
+
+// fetchJellyfinItems lists all movies and series with their paths.
+func fetchJellyfinItems(cfg JellyfinConfig) ([]jellyfinItem, error) {
+	q := url.Values{}
+	q.Set("Recursive", "true")
+	q.Set("IncludeItemTypes", "Movie,Series")
+	q.Set("Fields", "Path")
+	resp, err := jellyfinRequest(cfg, "GET", "/Items?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Items []jellyfinItem `json:"Items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Items, nil
+}
+
+// refreshJellyfinItem asks the server to rescan an item and its children.
+func refreshJellyfinItem(cfg JellyfinConfig, id string) error {
+	q := url.Values{}
+	q.Set("Recursive", "true")
+	q.Set("MetadataRefreshMode", "Default")
+	q.Set("ImageRefreshMode", "Default")
+	q.Set("ReplaceAllMetadata", "false")
+	q.Set("ReplaceAllImages", "false")
+	resp, err := jellyfinRequest(cfg, "POST", "/Items/"+url.PathEscape(id)+"/Refresh?"+q.Encode())
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// jellyfinRequest sends an authenticated request and fails on non-2xx responses.
+func jellyfinRequest(cfg JellyfinConfig, method, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequest(method, trimTrailingSlash(cfg.URL)+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(HeaderEmbyToken, cfg.APIKey)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf(ApiReturnedStatusFmt, resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeJellyfin serves a small library and records item refreshes.
+type fakeJellyfin struct {
+	mu        sync.Mutex
+	refreshed []string
+}
+
+func (f *fakeJellyfin) handler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(HeaderEmbyToken) != "jf-key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/System/Info":
+		_ = json.NewEncoder(w).Encode(map[string]string{"ServerName": "fake"})
+	case r.Method == "GET" && r.URL.Path == "/Items":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"Items": []map[string]string{
+			{"Id": "m1", "Type": "Movie", "Path": "/data/movies/Alien (1979)/Alien.mkv"},
+			{"Id": "m2", "Type": "Movie", "Path": "/data/movies/Heat (1995)/Heat.mkv"},
+			{"Id": "s1", "Type": "Series", "Path": "/data/tv/Dark"},
+		}})
+	case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/Refresh"):
+		f.mu.Lock()
+		f.refreshed = append(f.refreshed, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/Items/"), "/Refresh"))
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestJellyfinRefreshesItemsByMappedPath(t *testing.T) {
+	CreateTempConfig(t)
+	fj := &fakeJellyfin{}
+	srv := httptest.NewServer(http.HandlerFunc(fj.handler))
+	defer srv.Close()
+	cfg := map[string]interface{}{
+		"general": DefaultGeneralConfig(),
+		"jellyfin": map[string]interface{}{
+			"enabled": true,
+			"url":     srv.URL,
+			"apiKey":  "jf-key",
+			"pathMappings": []map[string]interface{}{
+				{"from": "/media/movies", "to": "/data/movies"},
+				{"from": "/media/tv", "to": "/data/tv"},
+			},
+		},
+	}
+	data, _ := json.Marshal(cfg)
+	WriteConfig(t, data)
+	scheduleLibraryRefresh("/media/movies/Alien (1979)")
+	scheduleLibraryRefresh("/media/tv/Dark")
+	scheduleLibraryRefresh("/media/movies/Unknown (2000)")
+	flushLibraryRefreshForTest()
+
+	fj.mu.Lock()
+	got := append([]string(nil), fj.refreshed...)
+	fj.mu.Unlock()
+	sort.Strings(got)
+	if len(got) != 2 || got[0] != "m1" || got[1] != "s1" {
+		t.Fatalf("expected refresh of m1 and s1, got %v", got)
+	}
+}
+
+func TestJellyfinItemIDsForFolder(t *testing.T) {
+	items := []jellyfinItem{
+		{ID: "m1", Type: "Movie", Path: "/data/movies/Alien (1979)/Alien.mkv"},
+		{ID: "s1", Type: "Series", Path: "/data/tv/Dark/"},
+		{ID: "s2", Type: "Series", Path: "/data/tv/Dark Matter"},
+	}
+	if ids := jellyfinItemIDsForFolder(items, "/data/tv/Dark"); len(ids) != 1 || ids[0] != "s1" {
+		t.Fatalf("expected only s1, got %v", ids)
+	}
+	if ids := jellyfinItemIDsForFolder(items, "/data/movies/Alien (1979)/"); len(ids) != 1 || ids[0] != "m1" {
+		t.Fatalf("expected m1, got %v", ids)
+	}
+	if ids := jellyfinItemIDsForFolder(items, "/data/movies"); len(ids) != 0 {
+		t.Fatalf("library root must not match items, got %v", ids)
+	}
+}
+
+func TestMediaServerConnectionTestEndpoint(t *testing.T) {
+	fj := &fakeJellyfin{}
+	srv := httptest.NewServer(http.HandlerFunc(fj.handler))
+	defer srv.Close()
+	r := NewTestRouter()
+	RegisterRoutes(r)
+
+	w := DoRequest(r, "GET", "/api/test/jellyfin?url="+url.QueryEscape(srv.URL)+"&apiKey=jf-key", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"success":true`) {
+		t.Fatalf("expected successful jellyfin test, got %d %s", w.Code, w.Body.String())
+	}
+	w = DoRequest(r, "GET", "/api/test/emby?url="+url.QueryEscape(srv.URL)+"&apiKey=wrong", nil)
+	if strings.Contains(w.Body.String(), `"success":true`) {
+		t.Fatalf("expected emby test to fail with wrong key, got %s", w.Body.String())
+	}
+}
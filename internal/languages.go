@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// languageTagRe matches an ISO 639-1 language with an optional ISO 3166-1 region, eg. es or es-MX.
+var languageTagRe = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+// LanguageConfig holds the preferred extra languages per library, best first
+// (eg. es-MX, es, en). An empty list keeps TMDB's default results and order.
+type LanguageConfig struct {
+	Movie []string `yaml:"movie" json:"movie"`
+	TV    []string `yaml:"tv" json:"tv"`
+	// OnePerLanguage downloads only the best trailer in each preferred
+	// language instead of every trailer
+	OnePerLanguage bool `yaml:"onePerLanguage" json:"onePerLanguage"`
+}
+
+func DefaultLanguageConfig() LanguageConfig {
+	return LanguageConfig{Movie: []string{}, TV: []string{}, OnePerLanguage: false}
+}
+
+// GetLanguageConfig loads the languages config from config.yml
+func GetLanguageConfig() (LanguageConfig, error) {
+	cfg := DefaultLanguageConfig()
+	if err := decodeConfigSection("languages", &cfg); err != nil {
+		return DefaultLanguageConfig(), err
+	}
+	return cfg, nil
+}
+
+// SaveLanguageConfig saves the languages config to config.yml
+func SaveLanguageConfig(cfg LanguageConfig) error {
+	return saveConfigSection("languages", cfg)
+}
+
+func ensureLanguageDefaults(config map[string]interface{}) bool {
+	if _, ok := config["languages"].(map[string]interface{}); ok {
+		return false
+	}
+	config["languages"] = DefaultLanguageConfig()
+	return true
+}
+
+// normalizeLanguageList trims and normalizes the case of language tags
+// ("ES-mx" becomes "es-MX"), drops duplicates and reports the first invalid tag.
+func normalizeLanguageList(langs []string) ([]string, string) {
+	out := make([]string, 0, len(langs))
+	seen := map[string]bool{}
+	for _, l := range langs {
+		l = strings.TrimSpace(strings.ReplaceAll(l, "_", "-"))
+		if l == "" {
+			continue
+		}
+		lang, region, _ := strings.Cut(l, "-")
+		l = strings.ToLower(lang)
+		if region != "" {
+			l += "-" + strings.ToUpper(region)
+		}
+		if !languageTagRe.MatchString(l) {
+			return nil, l
+		}
+		if !seen[l] {
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	return out, ""
+}
+
+// Handler to get languages config
+func GetLanguageConfigHandler(c *gin.Context) {
+	cfg, _ := GetLanguageConfig()
+	respondJSON(c, http.StatusOK, cfg)
+}
+
+// Handler to save languages config
+func SaveLanguageConfigHandler(c *gin.Context) {
+	var req LanguageConfig
+	if err := c.BindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	var bad string
+	if req.Movie, bad = normalizeLanguageList(req.Movie); bad == "" {
+		req.TV, bad = normalizeLanguageList(req.TV)
+	}
+	if bad != "" {
+		respondError(c, http.StatusBadRequest, "invalid language: "+bad)
+		return
+	}
+	if err := SaveLanguageConfig(req); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
+}
+
+// forMediaType returns the language preference list for a library.
+func (cfg LanguageConfig) forMediaType(mediaType MediaType) []string {
+	if mediaType == MediaTypeTV {
+		return cfg.TV
+	}
+	return cfg.Movie
+}
+
+// preferredLanguages returns the configured language preferences for a library.
+func preferredLanguages(mediaType MediaType) []string {
+	cfg, _ := GetLanguageConfig()
+	return cfg.forMediaType(mediaType)
+}
+
+// tmdbVideosLanguageQuery returns the TMDB /videos query parameters for the
+// preferences: the first one as language, and every preferred language plus
+// videos without one as include_video_language.
+func tmdbVideosLanguageQuery(prefs []string) string {
+	if len(prefs) == 0 {
+		return ""
+	}
+	include := []string{}
+	seen := map[string]bool{}
+	for _, p := range prefs {
+		lang, _, _ := strings.Cut(p, "-")
+		if !seen[lang] {
+			seen[lang] = true
+			include = append(include, lang)
+		}
+	}
+	include = append(include, "null")
+	q := url.Values{}
+	q.Set("language", prefs[0])
+	q.Set("include_video_language", strings.Join(include, ","))
+	return "&" + q.Encode()
+}
+
+// tmdbLanguageTag builds a language tag from TMDB's iso_639_1 and iso_3166_1 fields.
+func tmdbLanguageTag(iso639, iso3166 string) string {
+	if iso639 == "" {
+		return ""
+	}
+	if iso3166 == "" {
+		return strings.ToLower(iso639)
+	}
+	return strings.ToLower(iso639) + "-" + strings.ToUpper(iso3166)
+}
+
+// languageRank returns the index of the first preference lang satisfies, or
+// len(prefs) if none does. A preference without a region matches any region.
+func languageRank(lang string, prefs []string) int {
+	base, _, _ := strings.Cut(lang, "-")
+	for i, p := range prefs {
+		if strings.EqualFold(p, lang) || (!strings.Contains(p, "-") && strings.EqualFold(p, base)) {
+			return i
+		}
+	}
+	return len(prefs)
+}
+
+// rankExtrasByLanguage orders extras by language preference, keeping TMDB's
+// order for extras of equal rank.
+func rankExtrasByLanguage(extras []Extra, prefs []string) {
+	if len(prefs) == 0 {
+		return
+	}
+	sort.SliceStable(extras, func(i, j int) bool {
+		return languageRank(extras[i].Language, prefs) < languageRank(extras[j].Language, prefs)
+	})
+}
+
+// applyLanguagePreferences drops the trailers that shouldn't be downloaded
+// when only one trailer per preferred language is wanted. A trailer already
+// downloaded in a language fills its slot; if no trailer is in a preferred
+// language the best remaining one is kept so the media still gets a trailer.
+func applyLanguagePreferences(mediaType MediaType, extras []Extra) []Extra {
+	cfg, _ := GetLanguageConfig()
+	prefs := cfg.forMediaType(mediaType)
+	if !cfg.OnePerLanguage || len(prefs) == 0 {
+		return extras
+	}
+	// Pick the trailer for each preferred language slot
+	chosen := map[int]int{}
+	fallback := -1
+	for i, e := range extras {
+		if canonicalizeExtraType(e.ExtraType) != string(Trailers) || e.Status == "rejected" {
+			continue
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+		rank := languageRank(e.Language, prefs)
+		if rank == len(prefs) {
+			continue
+		}
+		if cur, ok := chosen[rank]; !ok || (e.Status == "downloaded" && extras[cur].Status != "downloaded") {
+			chosen[rank] = i
+		}
+	}
+	keep := map[int]bool{}
+	for _, i := range chosen {
+		keep[i] = true
+	}
+	if len(chosen) == 0 && fallback >= 0 {
+		keep[fallback] = true
+	}
+	out := make([]Extra, 0, len(extras))
+	for i, e := range extras {
+		if canonicalizeExtraType(e.ExtraType) == string(Trailers) && e.Status != "rejected" && !keep[i] {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
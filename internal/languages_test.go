@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLanguageConfigHandlerNormalizes(t *testing.T) {
+	CreateTempConfig(t)
+	r := NewTestRouter()
+	r.POST("/api/settings/languages", SaveLanguageConfigHandler)
+	if w := DoRequest(r, "POST", "/api/settings/languages", []byte(`{"movie":["english"]}`)); w.Code != 400 {
+		t.Fatalf("expected 400 for invalid language, got %d", w.Code)
+	}
+	w := DoRequest(r, "POST", "/api/settings/languages", []byte(`{"movie":[" ES-mx","es","es"," ","en"],"tv":["pt_br"],"onePerLanguage":true}`))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	cfg, _ := GetLanguageConfig()
+	if !reflect.DeepEqual(cfg.Movie, []string{"es-MX", "es", "en"}) || !reflect.DeepEqual(cfg.TV, []string{"pt-BR"}) || !cfg.OnePerLanguage {
+		t.Fatalf("unexpected saved config: %+v", cfg)
+	}
+}
+
+func TestTMDBVideosLanguageQuery(t *testing.T) {
+	if q := tmdbVideosLanguageQuery(nil); q != "" {
+		t.Fatalf("expected no query without preferences, got %q", q)
+	}
+	q, err := url.ParseQuery(tmdbVideosLanguageQuery([]string{"es-MX", "es", "en"})[1:])
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if q.Get("language") != "es-MX" || q.Get("include_video_language") != "es,en,null" {
+		t.Fatalf("unexpected query: %v", q)
+	}
+}
+
+func TestRankExtrasByLanguage(t *testing.T) {
+	extras := []Extra{
+		{YoutubeId: "none"},
+		{YoutubeId: "en", Language: "en-US"},
+		{YoutubeId: "es-es", Language: "es-ES"},
+		{YoutubeId: "es-mx", Language: "es-MX"},
+	}
+	rankExtrasByLanguage(extras, []string{"es-MX", "es", "en"})
+	var got []string
+	for _, e := range extras {
+		got = append(got, e.YoutubeId)
+	}
+	if want := []string{"es-mx", "es-es", "en", "none"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestApplyLanguagePreferencesKeepsOneTrailerPerLanguage(t *testing.T) {
+	CreateTempConfig(t)
+	extras := []Extra{
+		{ExtraType: "Trailers", YoutubeId: "es1", Language: "es-MX", Status: "missing"},
+		{ExtraType: "Trailers", YoutubeId: "es2", Language: "es-ES", Status: "missing"},
+		{ExtraType: "Trailers", YoutubeId: "en1", Language: "en-US", Status: "missing"},
+		{ExtraType: "Trailers", YoutubeId: "en2", Language: "en-GB", Status: "downloaded"},
+		{ExtraType: "Trailers", YoutubeId: "fr1", Language: "fr-FR", Status: "missing"},
+		{ExtraType: "Featurettes", YoutubeId: "feat", Language: "fr-FR", Status: "missing"},
+	}
+	if got := applyLanguagePreferences(MediaTypeMovie, extras); len(got) != len(extras) {
+		t.Fatalf("expected no filtering without onePerLanguage, got %d", len(got))
+	}
+	if err := SaveLanguageConfig(LanguageConfig{Movie: []string{"es", "en"}, OnePerLanguage: true}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	var got []string
+	for _, e := range applyLanguagePreferences(MediaTypeMovie, extras) {
+		got = append(got, e.YoutubeId)
+	}
+	if want := []string{"es1", "en2", "feat"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// Without a trailer in a preferred language the best one is still kept
+	other := []Extra{
+		{ExtraType: "Trailers", YoutubeId: "fr1", Language: "fr-FR", Status: "missing"},
+		{ExtraType: "Trailers", YoutubeId: "de1", Language: "de-DE", Status: "missing"},
+	}
+	if got := applyLanguagePreferences(MediaTypeMovie, other); len(got) != 1 || got[0].YoutubeId != "fr1" {
+		t.Fatalf("expected fallback trailer fr1, got %+v", got)
+	}
+}
+
+func TestDownloadRecordsExtraLanguage(t *testing.T) {
+	CreateTempConfig(t)
+	oldRunner := ytDlpRunner
+	ytDlpRunner = &fakeRunner{}
+	defer func() { ytDlpRunner = oldRunner }()
+	ctx := context.Background()
+	_ = GetStoreClient().Del(ctx, ExtrasStoreKey)
+
+	meta, err := DownloadYouTubeExtraContext(ctx, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 1, ExtraType: "Trailers", ExtraTitle: "Trailer Oficial", YouTubeID: testYtID, Language: "es-MX"})
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	if meta.Language != "es-MX" {
+		t.Fatalf("expected language in metadata, got %+v", meta)
+	}
+	entry, _ := GetExtraByYoutubeId(ctx, testYtID, MediaTypeMovie, 1)
+	if entry == nil || entry.Language != "es-MX" {
+		t.Fatalf("expected language on store entry, got %+v", entry)
+	}
+	extras, _ := SearchExtras(MediaTypeMovie, 1)
+	for _, e := range extras {
+		if e.YoutubeId == testYtID && e.Language != "es-MX" {
+			t.Fatalf("expected language on searched extra, got %+v", e)
+		}
+	}
+}
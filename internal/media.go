@@ -21,6 +21,9 @@ type MediaType string
 const (
 	MediaTypeMovie MediaType = "movie"
 	MediaTypeTV    MediaType = "tv"
+	// MediaTypeCollection extras belong to a TMDB movie collection; their
+	// MediaId is the TMDB collection ID, not a Radarr ID
+	MediaTypeCollection MediaType = "collection"
 )
 
 const (
@@ -29,8 +32,18 @@ const (
 	totalTimeLogFormat = "Total time: %v"
 )
 
-// SearchExtras merges extras from the main cache and the persistent extras collection for a media item
+// SearchExtras merges extras from the main cache and the persistent extras collection for a media item.
+// Extras of single seasons of a series are left out; see SearchSeasonExtras.
 func SearchExtras(mediaType MediaType, mediaId int) ([]Extra, error) {
+	return searchExtrasForSeason(mediaType, mediaId, 0)
+}
+
+// SearchSeasonExtras returns the persistent extras of one season of a series.
+func SearchSeasonExtras(seriesId, season int) ([]Extra, error) {
+	return searchExtrasForSeason(MediaTypeTV, seriesId, season)
+}
+
+func searchExtrasForSeason(mediaType MediaType, mediaId, season int) ([]Extra, error) {
 	ctx := context.Background()
 	entries, err := GetExtrasForMedia(ctx, mediaType, mediaId)
 	if err != nil {
@@ -45,11 +58,19 @@ func SearchExtras(mediaType MediaType, mediaId int) ([]Extra, error) {
 	}
 	result := make([]Extra, 0, len(entries))
 	for _, e := range entries {
+		if e.Season != season {
+			continue
+		}
 		result = append(result, Extra{
 			ExtraType:  e.ExtraType,
 			ExtraTitle: e.ExtraTitle,
 			YoutubeId:  e.YoutubeId,
 			Status:     e.Status,
+			Language:   e.Language,
+			Source:     e.Source,
+			SourceURL:  e.SourceURL,
+			SourceSite: e.SourceSite,
+			Season:     e.Season,
 		})
 	}
 	return result, nil
@@ -228,7 +249,10 @@ func SyncMedia(provider, apiPath, cacheFile string, filter func(map[string]inter
 	TrailarrLog(DEBUG, "SyncMedia", "Previous cache size for %s: %d", cacheFile, len(prevItems))
 
 	// Save items to the appropriate backend
-	if err := saveItems(cacheFile, filtered); err != nil {
+	mediaCacheMu.Lock()
+	err = saveItems(cacheFile, filtered)
+	mediaCacheMu.Unlock()
+	if err != nil {
 		TrailarrLog(WARN, "SyncMedia", "Failed to save cache %s: %v", cacheFile, err)
 		return err
 	} else {
@@ -611,12 +635,17 @@ func updateItemPath(item map[string]interface{}, mappings [][]string) {
 	if !ok || p == "" || mappings == nil {
 		return
 	}
+	item["path"] = applyPathMappings(p, mappings)
+}
+
+// applyPathMappings rewrites p using the first mapping whose "from" prefix matches
+func applyPathMappings(p string, mappings [][]string) string {
 	for _, m := range mappings {
 		if strings.HasPrefix(p, m[0]) {
-			item["path"] = m[1] + p[len(m[0]):]
-			break
+			return m[1] + p[len(m[0]):]
 		}
 	}
+	return p
 }
 
 // Helper: Update item title using title map
@@ -701,35 +730,43 @@ func processNewMediaExtras(mediaType MediaType, mediaID int, cfg interface{}) {
 			TrailarrLog(WARN, "processNewMediaExtras", "Invalid extras config type; using defaults")
 		}
 	}
-	filterAndDownloadExtras(mediaType, mediaID, extras, etcfg)
+	filterAndEnqueueExtras(mediaType, mediaID, extras, etcfg, QueueSourceNewMedia)
 }
 
 // Helper: fetch provider items and decode JSON, with logging preserved
 func fetchProviderItems(provider, apiPath string) ([]map[string]interface{}, error) {
+	var allItems []map[string]interface{}
+	if err := fetchProviderJSON(provider, apiPath, &allItems); err != nil {
+		return nil, err
+	}
+	return allItems, nil
+}
+
+// fetchProviderJSON GETs apiPath from Radarr/Sonarr and decodes the JSON response into dst
+func fetchProviderJSON(provider, apiPath string, dst interface{}) error {
 	providerURL, apiKey, err := GetProviderUrlAndApiKey(provider)
 	if err != nil {
-		return nil, fmt.Errorf("%s settings not found: %w", provider, err)
+		return fmt.Errorf("%s settings not found: %w", provider, err)
 	}
 	req, err := http.NewRequest("GET", providerURL+apiPath, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+		return fmt.Errorf("error creating request: %w", err)
 	}
 	req.Header.Set(HeaderApiKey, apiKey)
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching %s: %w", provider, err)
+		return fmt.Errorf("error fetching %s: %w", provider, err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		TrailarrLog(WARN, "fetchProviderItems", "%s API error: %d", provider, resp.StatusCode)
-		return nil, fmt.Errorf("%s API error: %d", provider, resp.StatusCode)
+		return fmt.Errorf("%s API error: %d", provider, resp.StatusCode)
 	}
-	var allItems []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&allItems); err != nil {
-		return nil, fmt.Errorf("failed to decode %s response: %w", provider, err)
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", provider, err)
 	}
-	return allItems, nil
+	return nil
 }
 
 // Generic background sync for Radarr/Sonarr
@@ -858,6 +895,9 @@ func SaveWantedIndex(cacheFile string, items []map[string]interface{}) error {
 }
 
 // In-memory cache for wanted index to avoid store reads under load.
+// mediaCacheMu serialises writes of the movies/series caches between syncs and webhooks
+var mediaCacheMu sync.Mutex
+
 var wantedIndexMu sync.RWMutex
 var wantedIndexMem = map[string][]map[string]interface{}{}
 
@@ -951,7 +991,7 @@ func sharedExtrasHandler(mediaType MediaType) gin.HandlerFunc {
 		MarkDownloadedExtras(finalExtras, mediaPath, "type", "title")
 
 		// 5. Apply rejected extras (preserve reason and include missing rejected entries)
-		rejectedExtras := GetRejectedExtrasForMedia(mediaType, id)
+		rejectedExtras := rejectedInSeason(GetRejectedExtrasForMedia(mediaType, id), 0)
 		TrailarrLog(DEBUG, "sharedExtrasHandler", "Rejected extras: %+v", rejectedExtras)
 		finalExtras = applyRejectedExtras(finalExtras, rejectedExtras)
 
@@ -1012,6 +1052,7 @@ func applyRejectedExtras(finalExtras []Extra, rejectedExtras []RejectedExtra) []
 				YoutubeId:  r.YoutubeId,
 				Status:     "rejected",
 				Reason:     r.Reason,
+				Season:     r.Season,
 			})
 		}
 	}
@@ -1064,6 +1105,9 @@ func updateWantedStatusInStore(cacheFile string) error {
 func computeWantedIndexAndSetWants(items []map[string]interface{}) (int, []map[string]interface{}) {
 	trailerCount := 0
 	logged := 0
+	// With theme music enabled, media without theme.mp3 is wanted too
+	extraTypes, _ := GetExtraTypesConfig()
+	scanCfg := loadExtraScanConfig()
 	for _, item := range items {
 		mediaId, ok := getMediaID(item)
 		if !ok {
@@ -1074,8 +1118,8 @@ func computeWantedIndexAndSetWants(items []map[string]interface{}) (int, []map[s
 		if p, ok := item["path"].(string); ok {
 			mediaPath = p
 		}
-		hasTrailer := hasTrailerFiles(mediaPath)
-		item["wanted"] = !hasTrailer
+		hasTrailer := hasTrailerFilesIn(scanCfg, mediaPath)
+		item["wanted"] = !hasTrailer || (extraTypes.ThemeMusic && !hasThemeMusic(mediaPath))
 		if hasTrailer {
 			trailerCount++
 		}
@@ -1116,24 +1160,36 @@ func getMediaID(item map[string]interface{}) (int, bool) {
 // hasTrailerInExtras returns true if any extra in the slice is a trailer (singular/plural or canonicalized)
 // (removed: extras-based trailer detection — now relies on presence of .mkv files in Trailers folders)
 
-// hasTrailerFiles checks for presence of .mkv files in Trailers or Trailer subdirectory of mediaPath.
+// hasTrailerFiles checks for .mkv trailers under mediaPath in any naming scheme.
 func hasTrailerFiles(mediaPath string) bool {
+	return hasTrailerFilesIn(loadExtraScanConfig(), mediaPath)
+}
+
+// hasTrailerFilesIn is hasTrailerFiles with the settings already loaded. It only
+// reads the trailer folders, and never the sidecars.
+func hasTrailerFilesIn(cfg extraScanConfig, mediaPath string) bool {
 	if mediaPath == "" {
 		return false
 	}
-	// check both common directory names
-	candidates := []string{filepath.Join(mediaPath, "Trailers"), filepath.Join(mediaPath, "Trailer")}
-	for _, dir := range candidates {
-		entries, err := os.ReadDir(dir)
-		if err != nil {
+	entries, err := os.ReadDir(mediaPath)
+	if err != nil {
+		return false
+	}
+	mediaName := filepath.Base(mediaPath)
+	// Trailers/, trailers/, Trailer/ and "<name>-trailer.mkv" all count
+	for _, e := range entries {
+		if !e.IsDir() {
+			if extraType, _, ok := parseSuffixedExtraName(e.Name(), mediaName); ok && extraType == string(Trailers) {
+				return true
+			}
 			continue
 		}
-		for _, e := range entries {
-			if e.IsDir() {
-				continue
-			}
-			name := strings.ToLower(e.Name())
-			if strings.HasSuffix(name, ".mkv") {
+		if extraType, _ := cfg.folderType(e.Name()); extraType != string(Trailers) && !strings.EqualFold(e.Name(), "Trailer") {
+			continue
+		}
+		files, _ := os.ReadDir(filepath.Join(mediaPath, e.Name()))
+		for _, f := range files {
+			if !f.IsDir() && hasMkvExt(f.Name()) {
 				return true
 			}
 		}
@@ -1223,38 +1279,63 @@ func hasFilesystemExtras(mediaType MediaType, mediaId int, enabledTypes []string
 	return false
 }
 
-// SyncMediaType syncs Radarr or Sonarr depending on mediaType
-func SyncMediaType(mediaType MediaType) error {
+// mediaSource describes where a media type's items come from and how they are cached
+type mediaSource struct {
+	Provider       string
+	APIPath        string
+	CacheFile      string
+	Filter         func(map[string]interface{}) bool
+	PosterDir      string
+	PosterSuffixes []string
+}
+
+// mediaSourceFor returns the Radarr or Sonarr source for mediaType
+func mediaSourceFor(mediaType MediaType) (mediaSource, error) {
 	switch mediaType {
 	case MediaTypeMovie:
-		return SyncMedia(
-			"radarr",
-			"/api/v3/movie",
-			MoviesStoreKey,
-			func(m map[string]interface{}) bool {
-				hasFile, ok := m["hasFile"].(bool)
-				return ok && hasFile
-			},
-			MediaCoverPath+"/Movies",
-			[]string{"/poster-500.jpg", "/fanart-1280.jpg"},
-		)
+		return mediaSource{
+			Provider:       "radarr",
+			APIPath:        "/api/v3/movie",
+			CacheFile:      MoviesStoreKey,
+			Filter:         movieHasFile,
+			PosterDir:      MediaCoverPath + "/Movies",
+			PosterSuffixes: []string{"/poster-500.jpg", "/fanart-1280.jpg"},
+		}, nil
 	case MediaTypeTV:
-		return SyncMedia(
-			"sonarr",
-			"/api/v3/series",
-			SeriesStoreKey,
-			func(m map[string]interface{}) bool {
-				stats, ok := m["statistics"].(map[string]interface{})
-				if !ok {
-					return false
-				}
-				episodeFileCount, ok := stats["episodeFileCount"].(float64)
-				return ok && episodeFileCount >= 1
-			},
-			MediaCoverPath+"/Series",
-			[]string{"/poster-500.jpg", "/fanart-1280.jpg"},
-		)
+		return mediaSource{
+			Provider:       "sonarr",
+			APIPath:        "/api/v3/series",
+			CacheFile:      SeriesStoreKey,
+			Filter:         seriesHasEpisodeFiles,
+			PosterDir:      MediaCoverPath + "/Series",
+			PosterSuffixes: []string{"/poster-500.jpg", "/fanart-1280.jpg"},
+		}, nil
 	default:
-		return fmt.Errorf("unknown media type: %v", mediaType)
+		return mediaSource{}, fmt.Errorf("unknown media type: %v", mediaType)
+	}
+}
+
+// movieHasFile keeps Radarr movies that have been downloaded
+func movieHasFile(m map[string]interface{}) bool {
+	hasFile, ok := m["hasFile"].(bool)
+	return ok && hasFile
+}
+
+// seriesHasEpisodeFiles keeps Sonarr series with at least one episode file
+func seriesHasEpisodeFiles(m map[string]interface{}) bool {
+	stats, ok := m["statistics"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	episodeFileCount, ok := stats["episodeFileCount"].(float64)
+	return ok && episodeFileCount >= 1
+}
+
+// SyncMediaType syncs Radarr or Sonarr depending on mediaType
+func SyncMediaType(mediaType MediaType) error {
+	src, err := mediaSourceFor(mediaType)
+	if err != nil {
+		return err
 	}
+	return SyncMedia(src.Provider, src.APIPath, src.CacheFile, src.Filter, src.PosterDir, src.PosterSuffixes)
 }
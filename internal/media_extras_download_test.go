@@ -23,7 +23,9 @@ func TestShouldDownloadExtraAndFilter(t *testing.T) {
 func TestFilterAndDownloadEnqueues(t *testing.T) {
 	ctx := context.Background()
 	// clear queue
+	holdWorkerPool(t)
 	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	t.Cleanup(func() { _ = GetStoreClient().Del(ctx, DownloadQueue) })
 
 	extras := []Extra{{ExtraType: "Trailers", ExtraTitle: "T", YoutubeId: "qz", Status: "missing"}}
 	// call with movie type and ensure enqueue
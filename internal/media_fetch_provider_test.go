@@ -11,9 +11,7 @@ import (
 
 func TestFetchProviderItemsFailureNoConfig(t *testing.T) {
 	// ensure ConfigPath points to non-existent file
-	old := ConfigPath
-	ConfigPath = filepath.Join(t.TempDir(), "nope.yml")
-	defer func() { ConfigPath = old }()
+	setConfigPathForTest(t, filepath.Join(t.TempDir(), "nope.yml"))
 
 	if _, err := fetchProviderItems("radarr", "/api/v3/movie"); err == nil {
 		t.Fatalf("expected error when provider config missing")
@@ -36,9 +34,7 @@ func TestFetchProviderItemsSuccess(t *testing.T) {
 	data, _ := json.Marshal(cfg)
 	_ = os.WriteFile(cfgPath, data, 0644)
 
-	old := ConfigPath
-	ConfigPath = cfgPath
-	defer func() { ConfigPath = old }()
+	setConfigPathForTest(t, cfgPath)
 
 	items, err := fetchProviderItems("radarr", "/api/v3/movie")
 	if err != nil {
@@ -175,10 +175,9 @@ func TestFetchAndCachePosterAndCacheMediaPosters(t *testing.T) {
 	os.WriteFile(cfgPath, data, 0644)
 	// override globals
 	oldRoot := TrailarrRoot
-	oldCfg := ConfigPath
 	TrailarrRoot = tmp
-	ConfigPath = cfgPath
-	defer func() { TrailarrRoot = oldRoot; ConfigPath = oldCfg }()
+	setConfigPathForTest(t, cfgPath)
+	defer func() { TrailarrRoot = oldRoot }()
 
 	baseDir := filepath.Join(tmp, "covers")
 	idList := []map[string]interface{}{{"id": 123}}
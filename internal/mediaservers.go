@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MediaServerPathMapping maps a path prefix as Trailarr sees it to the same
+// folder as the media server sees it.
+type MediaServerPathMapping struct {
+	From string `yaml:"from" json:"from"`
+	To   string `yaml:"to" json:"to"`
+}
+
+var (
+	// Quiet period after the last extras change before media servers are asked
+	// to refresh. Tests can shorten these.
+	LibraryRefreshDebounce = 10 * time.Second
+	// Upper bound on how long changes wait during a long task run.
+	LibraryRefreshMaxWait = 2 * time.Minute
+)
+
+// libraryRefresher collects media folders whose extras changed and asks the
+// configured media servers to refresh them once things go quiet, so a task run
+// downloading hundreds of extras results in a handful of refreshes.
+type libraryRefresher struct {
+	mu       sync.Mutex
+	pending  map[string]struct{}
+	timer    *time.Timer
+	firstAdd time.Time
+}
+
+var libraryRefresh = &libraryRefresher{pending: map[string]struct{}{}}
+
+// scheduleLibraryRefresh queues a refresh of mediaFolder on Plex, Jellyfin and
+// Emby. It is a no-op when no media server is enabled.
+func scheduleLibraryRefresh(mediaFolder string) {
+	if mediaFolder == "" || !mediaServerRefreshEnabled() {
+		return
+	}
+	libraryRefresh.add(filepath.Clean(mediaFolder))
+}
+
+// mediaServerRefreshEnabled reports whether any media server wants refreshes.
+func mediaServerRefreshEnabled() bool {
+	if cfg, err := GetPlexConfig(); err == nil && cfg.Enabled {
+		return true
+	}
+	for _, server := range jellyfinServers {
+		if cfg, err := GetJellyfinConfig(server); err == nil && cfg.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *libraryRefresher) add(folder string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) == 0 {
+		r.firstAdd = time.Now()
+	}
+	r.pending[folder] = struct{}{}
+	delay := LibraryRefreshDebounce
+	if remaining := LibraryRefreshMaxWait - time.Since(r.firstAdd); remaining < delay {
+		delay = max(remaining, 0)
+	}
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.timer = time.AfterFunc(delay, r.flush)
+}
+
+// flush sends the pending refreshes to every enabled media server.
+func (r *libraryRefresher) flush() {
+	r.mu.Lock()
+	folders := make([]string, 0, len(r.pending))
+	for f := range r.pending {
+		folders = append(folders, f)
+	}
+	r.pending = map[string]struct{}{}
+	r.timer = nil
+	r.mu.Unlock()
+	if len(folders) == 0 {
+		return
+	}
+	refreshPlexFolders(folders)
+	for _, server := range jellyfinServers {
+		refreshJellyfinFolders(server, folders)
+	}
+}
+
+// testMediaServerConnection checks a Plex, Jellyfin or Emby URL and token.
+func testMediaServerConnection(provider, serverURL, token string) error {
+	var endpoint, header string
+	switch provider {
+	case "plex":
+		endpoint, header = "/identity", HeaderPlexToken
+	case "jellyfin", "emby":
+		endpoint, header = "/System/Info", HeaderEmbyToken
+	default:
+		return fmt.Errorf("unknown media server: %s", provider)
+	}
+	req, err := http.NewRequest("GET", trimTrailingSlash(serverURL)+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(header, token)
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		TrailarrLog(WARN, "Settings", ApiReturnedStatusFmt, resp.StatusCode)
+		return fmt.Errorf(ApiReturnedStatusFmt, resp.StatusCode)
+	}
+	return nil
+}
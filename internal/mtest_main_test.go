@@ -42,6 +42,9 @@ func TestMain(m *testing.M) {
 	// Use the fake runner for yt-dlp to avoid launching external processes in tests
 	oldRunner := ytDlpRunner
 	ytDlpRunner = &fakeRunner{}
+	// Fake downloads aren't real videos; skip ffprobe unless a test stubs it
+	oldProbe := probeVideo
+	probeVideo = func(string) (*VideoInfo, error) { return nil, nil }
 
 	// Shorten queue-related delays for faster tests
 	QueueItemRemoveDelay = 10 * time.Millisecond
@@ -58,6 +61,7 @@ func TestMain(m *testing.M) {
 
 	// Restore state
 	ytDlpRunner = oldRunner
+	probeVideo = oldProbe
 
 	// If the environment variable TRAILARR_KEEP_TEST_TMP is set to "1" or
 	// "true" we keep the temp directory for post-test inspection. This is
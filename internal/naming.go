@@ -0,0 +1,594 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Naming schemes for downloaded extras.
+const (
+	// <media>/<Canonical Type>/<title>.mkv (Plex local extras folders)
+	NamingSchemePlex = "plex"
+	// <media>/<MediaName>-<title>-<suffix>.mkv next to the movie (Jellyfin/Kodi)
+	NamingSchemeJellyfin = "jellyfin"
+	// <media>/<canonical type, lowercase>/<title>.mkv (eg. trailers/)
+	NamingSchemeLowercase = "lowercase"
+)
+
+// extraTypeSuffixes are the Jellyfin/Kodi filename suffixes per canonical extra type.
+var extraTypeSuffixes = map[string]string{
+	string(Trailers):        "trailer",
+	string(BehindTheScenes): "behindthescenes",
+	string(DeletedScenes):   "deleted",
+	string(Featurettes):     "featurette",
+	string(Interviews):      "interview",
+	string(Scenes):          "scene",
+	string(Shorts):          "short",
+	string(Other):           "other",
+}
+
+// extraSuffixAliases are other suffixes Jellyfin accepts, mapped to our types.
+var extraSuffixAliases = map[string]string{
+	"deletedscene": string(DeletedScenes),
+	"clip":         string(Other),
+	"extra":        string(Other),
+}
+
+// DefaultNamingTemplate keeps the historical "<extra title>.mkv" file names.
+const DefaultNamingTemplate = "{ExtraTitle}"
+
+// Filename template tokens.
+const (
+	TokenMediaTitle = "MediaTitle"
+	TokenYear       = "Year"
+	TokenExtraType  = "ExtraType"
+	TokenExtraTitle = "ExtraTitle"
+	TokenYouTubeID  = "YouTubeId"
+	TokenResolution = "Resolution"
+	TokenLanguage   = "Language"
+)
+
+var namingTokens = []string{TokenMediaTitle, TokenYear, TokenExtraType, TokenExtraTitle, TokenYouTubeID, TokenResolution, TokenLanguage}
+
+var namingTokenRe = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// NamingConfig selects how downloaded extras are laid out on disk. Template
+// renders the file name; Scheme decides the folder and any type suffix.
+type NamingConfig struct {
+	Scheme   string `yaml:"scheme" json:"scheme"`
+	Template string `yaml:"template" json:"template"`
+}
+
+func DefaultNamingConfig() NamingConfig {
+	return NamingConfig{Scheme: NamingSchemePlex, Template: DefaultNamingTemplate}
+}
+
+func isValidNamingScheme(scheme string) bool {
+	switch scheme {
+	case NamingSchemePlex, NamingSchemeJellyfin, NamingSchemeLowercase:
+		return true
+	}
+	return false
+}
+
+// GetNamingConfig loads the naming config from config.yml
+func GetNamingConfig() (NamingConfig, error) {
+	cfg := DefaultNamingConfig()
+	if err := decodeConfigSection("naming", &cfg); err != nil {
+		return DefaultNamingConfig(), err
+	}
+	if !isValidNamingScheme(cfg.Scheme) {
+		cfg.Scheme = NamingSchemePlex
+	}
+	if validateNamingTemplate(cfg.Template) != nil {
+		cfg.Template = DefaultNamingTemplate
+	}
+	return cfg, nil
+}
+
+// SaveNamingConfig saves the naming config to config.yml
+func SaveNamingConfig(cfg NamingConfig) error {
+	return saveConfigSection("naming", cfg)
+}
+
+func ensureNamingDefaults(config map[string]interface{}) bool {
+	if _, ok := config["naming"].(map[string]interface{}); ok {
+		return false
+	}
+	config["naming"] = DefaultNamingConfig()
+	return true
+}
+
+// Handler to get naming config
+func GetNamingConfigHandler(c *gin.Context) {
+	cfg, _ := GetNamingConfig()
+	respondJSON(c, http.StatusOK, cfg)
+}
+
+// Handler to save naming config
+func SaveNamingConfigHandler(c *gin.Context) {
+	req, ok := bindNamingConfig(c)
+	if !ok {
+		return
+	}
+	if err := SaveNamingConfig(req); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
+}
+
+// Handler to preview where an extra would be written with a naming config
+func PreviewNamingConfigHandler(c *gin.Context) {
+	req, ok := bindNamingConfig(c)
+	if !ok {
+		return
+	}
+	values := namingValues{
+		MediaTitle: "Alien",
+		Year:       "1979",
+		ExtraType:  string(Trailers),
+		ExtraTitle: "Official Trailer",
+		YouTubeID:  "LjLamj-b0I8",
+		Resolution: "1080p",
+		Language:   "en",
+	}
+	_, file := extraOutputPath(req.Scheme, "Alien (1979)", values.ExtraType, renderNamingTemplate(req.Template, values), "mkv")
+	respondJSON(c, http.StatusOK, gin.H{"path": filepath.ToSlash(file), "values": values})
+}
+
+// bindNamingConfig reads and validates a naming config from the request body,
+// responding with 400 when it is invalid. An empty template means the default.
+func bindNamingConfig(c *gin.Context) (NamingConfig, bool) {
+	var req NamingConfig
+	if err := c.BindJSON(&req); err != nil || !isValidNamingScheme(req.Scheme) {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return req, false
+	}
+	req.Template = strings.TrimSpace(req.Template)
+	if req.Template == "" {
+		req.Template = DefaultNamingTemplate
+	}
+	if err := validateNamingTemplate(req.Template); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+	return req, true
+}
+
+// validateNamingTemplate checks a filename template only uses known tokens and
+// identifies the extra, so different extras don't render to the same name.
+func validateNamingTemplate(tpl string) error {
+	if strings.TrimSpace(tpl) == "" {
+		return fmt.Errorf("template is empty")
+	}
+	if strings.ContainsAny(tpl, `/\`) {
+		return fmt.Errorf("template must be a file name, not a path")
+	}
+	for _, m := range namingTokenRe.FindAllStringSubmatch(tpl, -1) {
+		if !isNamingToken(m[1]) {
+			return fmt.Errorf("unknown token {%s}", m[1])
+		}
+	}
+	if strings.ContainsAny(namingTokenRe.ReplaceAllString(tpl, ""), "{}") {
+		return fmt.Errorf("template has unbalanced braces")
+	}
+	if !strings.Contains(tpl, "{"+TokenExtraTitle+"}") && !strings.Contains(tpl, "{"+TokenYouTubeID+"}") {
+		return fmt.Errorf("template must contain {%s} or {%s}", TokenExtraTitle, TokenYouTubeID)
+	}
+	return nil
+}
+
+func isNamingToken(name string) bool {
+	for _, t := range namingTokens {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// templateUsesToken reports whether tpl references {token}.
+func templateUsesToken(tpl, token string) bool {
+	return strings.Contains(tpl, "{"+token+"}")
+}
+
+// namingValues are the values filename template tokens render to.
+type namingValues struct {
+	MediaTitle string `json:"mediaTitle"`
+	Year       string `json:"year"`
+	ExtraType  string `json:"extraType"`
+	ExtraTitle string `json:"extraTitle"`
+	YouTubeID  string `json:"youtubeId"`
+	Resolution string `json:"resolution"`
+	Language   string `json:"language"`
+}
+
+func (v namingValues) token(name string) string {
+	switch name {
+	case TokenMediaTitle:
+		return v.MediaTitle
+	case TokenYear:
+		return v.Year
+	case TokenExtraType:
+		return v.ExtraType
+	case TokenExtraTitle:
+		return v.ExtraTitle
+	case TokenYouTubeID:
+		return v.YouTubeID
+	case TokenResolution:
+		return v.Resolution
+	case TokenLanguage:
+		return v.Language
+	}
+	return ""
+}
+
+var (
+	emptyBracketsRe = regexp.MustCompile(`\(\s*\)|\[\s*\]`)
+	repeatedDashRe  = regexp.MustCompile(`(\s+-)+\s+`)
+	repeatedSpaceRe = regexp.MustCompile(`\s{2,}`)
+)
+
+// renderNamingTemplate renders a template into a sanitized file name stem.
+// Separators and brackets left around empty tokens are tidied away, so
+// "{ExtraTitle} [{Resolution}]" without a resolution renders "<title>".
+func renderNamingTemplate(tpl string, v namingValues) string {
+	out := namingTokenRe.ReplaceAllStringFunc(tpl, func(m string) string {
+		return SanitizeFilename(v.token(m[1 : len(m)-1]))
+	})
+	out = emptyBracketsRe.ReplaceAllString(out, "")
+	out = repeatedDashRe.ReplaceAllString(out, " - ")
+	out = repeatedSpaceRe.ReplaceAllString(out, " ")
+	out = strings.Trim(out, " -_")
+	if out == "" {
+		out = SanitizeFilename(v.ExtraTitle)
+	}
+	return out
+}
+
+// parseNamingTemplate recovers the extra title from a file name stem rendered
+// with tpl, including a " (n)" collision suffix. ok is false if it doesn't match.
+func parseNamingTemplate(tpl, stem string) (string, bool) {
+	if !templateUsesToken(tpl, TokenExtraTitle) {
+		return "", false
+	}
+	values, ok := parseNamingTemplateTokens(tpl, stem)
+	return values[TokenExtraTitle], ok
+}
+
+// parseNamingTemplateTokens matches a file name stem against tpl and returns
+// the value of each token in it.
+func parseNamingTemplateTokens(tpl, stem string) (map[string]string, bool) {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range namingTokenRe.FindAllStringSubmatchIndex(tpl, -1) {
+		b.WriteString(regexp.QuoteMeta(tpl[last:loc[0]]))
+		token := tpl[loc[2]:loc[3]]
+		if isNamingToken(token) && !strings.Contains(b.String(), "(?P<"+token+">") {
+			b.WriteString("(?P<" + token + ">.+?)")
+		} else {
+			b.WriteString(".*?")
+		}
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(tpl[last:]))
+	b.WriteString(`(?: \(\d+\))?$`)
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, false
+	}
+	m := re.FindStringSubmatch(stem)
+	if m == nil {
+		return nil, false
+	}
+	values := map[string]string{}
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			values[name] = m[i]
+		}
+	}
+	return values, true
+}
+
+// templateUsesDownloadedVideoTokens reports whether the filename template uses
+// tokens only known once the video is downloaded, so the final name of an
+// extra can't be known while it is queued.
+func templateUsesDownloadedVideoTokens(tpl string) bool {
+	return templateUsesToken(tpl, TokenResolution) || templateUsesToken(tpl, TokenLanguage)
+}
+
+// resolveExtraOutput renders cfg for an extra and returns its directory and a
+// file path that doesn't clash with a different video already on disk.
+func resolveExtraOutput(cfg NamingConfig, mediaPath string, v namingValues) (string, string) {
+	dir, file := extraOutputPath(cfg.Scheme, mediaPath, v.ExtraType, renderNamingTemplate(cfg.Template, v), "mkv")
+	return dir, resolveExtraFileCollision(file, v.YouTubeID)
+}
+
+// resolveExtraFileCollision returns file, or "<name> (n).mkv" when file
+// already belongs to another YouTube video (eg. two TMDB videos with the same
+// name) or is a video without a sidecar, which must not be overwritten.
+func resolveExtraFileCollision(file, youtubeID string) string {
+	candidate := file
+	for n := 2; ; n++ {
+		if extraFileFree(candidate, youtubeID) {
+			return candidate
+		}
+		candidate = numberedExtraFile(file, n)
+	}
+}
+
+// extraFileFree reports whether the extra youtubeID can be written to file:
+// file is its own, or neither file nor a sidecar for it exists.
+func extraFileFree(file, youtubeID string) bool {
+	if owner := extraFileYouTubeID(file); owner != "" {
+		return owner == youtubeID
+	}
+	_, err := os.Stat(file)
+	return os.IsNotExist(err)
+}
+
+// numberedExtraFile returns "<name> (n).mkv" for "<name>.mkv".
+func numberedExtraFile(file string, n int) string {
+	return fmt.Sprintf("%s (%d).mkv", strings.TrimSuffix(file, ".mkv"), n)
+}
+
+// extraSidecar is the part of an extra's .mkv.json sidecar used to identify it.
+type extraSidecar struct {
+	ExtraTitle string `json:"extraTitle"`
+	YouTubeID  string `json:"youtubeId"`
+	Resolution string `json:"resolution"`
+	Language   string `json:"language"`
+}
+
+func readExtraSidecar(file string) (extraSidecar, bool) {
+	var meta extraSidecar
+	err := ReadJSONFile(strings.TrimSuffix(file, ".mkv")+mkvJSONSuffix, &meta)
+	return meta, err == nil
+}
+
+// extraFileYouTubeID returns the YouTube ID recorded for an extra file, if any.
+func extraFileYouTubeID(file string) string {
+	meta, _ := readExtraSidecar(file)
+	return meta.YouTubeID
+}
+
+// extraOutputPath returns the directory and file an extra is written to under
+// mediaPath for the given scheme. safeTitle must already be sanitized.
+func extraOutputPath(scheme, mediaPath, canonicalType, safeTitle, ext string) (string, string) {
+	switch scheme {
+	case NamingSchemeJellyfin:
+		suffix, ok := extraTypeSuffixes[canonicalType]
+		if !ok {
+			suffix = extraTypeSuffixes[string(Other)]
+		}
+		name := filepath.Base(mediaPath) + "-" + safeTitle + "-" + suffix + "." + ext
+		return mediaPath, filepath.Join(mediaPath, name)
+	case NamingSchemeLowercase:
+		dir := filepath.Join(mediaPath, strings.ToLower(canonicalType))
+		return dir, filepath.Join(dir, safeTitle+"."+ext)
+	default:
+		dir := filepath.Join(mediaPath, canonicalType)
+		return dir, filepath.Join(dir, safeTitle+"."+ext)
+	}
+}
+
+// extraFileCandidates returns where an extra could be stored under every
+// naming scheme, so callers can find it regardless of the current setting.
+func extraFileCandidates(mediaPath, extraType, safeTitle string) []string {
+	canonicalType := canonicalTypeForFolder(extraType)
+	seen := map[string]bool{}
+	var out []string
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	// The type as given first, for folders that aren't a known canonical type
+	add(filepath.Join(mediaPath, extraType, safeTitle+".mkv"))
+	for _, scheme := range []string{NamingSchemePlex, NamingSchemeLowercase, NamingSchemeJellyfin} {
+		_, file := extraOutputPath(scheme, mediaPath, canonicalType, safeTitle, "mkv")
+		add(file)
+	}
+	// Files named by a filename template
+	for _, f := range listExtraFiles(mediaPath) {
+		if f.ExtraType == canonicalType && f.Title == safeTitle {
+			add(f.Path)
+		}
+	}
+	return out
+}
+
+// extraFileOnDisk is an extra video found under a media folder.
+type extraFileOnDisk struct {
+	ExtraType string // canonical type, or the folder name for unknown folders
+	Title     string // sanitized extra title
+	YouTubeID string // from the sidecar, if recorded
+	Path      string
+}
+
+// extraScanConfig is the configuration listing extras on disk needs. Load it
+// once with loadExtraScanConfig when scanning many media folders.
+type extraScanConfig struct {
+	// Template is the filename template titles are parsed with
+	Template string
+	// folderTypes maps lower-cased extra type folder names to their canonical type
+	folderTypes map[string]string
+	// Seasons also lists the extra type folders inside season folders
+	Seasons bool
+}
+
+// loadExtraScanConfig reads the naming and extra type mapping settings once.
+func loadExtraScanConfig() extraScanConfig {
+	naming, _ := GetNamingConfig()
+	cfg := extraScanConfig{Template: naming.Template, folderTypes: map[string]string{}}
+	if mapping, err := GetCanonicalizeExtraTypeConfig(); err == nil {
+		for _, t := range mapping.Mapping {
+			cfg.folderTypes[strings.ToLower(t)] = t
+		}
+	}
+	for t := range extraTypeSuffixes {
+		cfg.folderTypes[strings.ToLower(t)] = t
+	}
+	return cfg
+}
+
+// folderType returns the canonical extra type of a folder name in any case.
+func (cfg extraScanConfig) folderType(name string) (string, bool) {
+	t, ok := cfg.folderTypes[strings.ToLower(name)]
+	return t, ok
+}
+
+// listExtraFiles finds .mkv extras under mediaPath in any naming scheme:
+// extra type folders (any case) and Jellyfin-style suffixed files in the media folder.
+// Titles come from the sidecar when present, else from the file name.
+func listExtraFiles(mediaPath string) []extraFileOnDisk {
+	return scanExtraFiles(loadExtraScanConfig(), mediaPath)
+}
+
+// scanExtraFiles is listExtraFiles with the settings already loaded. Only extra
+// type folders are read, plus season folders when cfg.Seasons is set.
+func scanExtraFiles(cfg extraScanConfig, mediaPath string) []extraFileOnDisk {
+	var out []extraFileOnDisk
+	if mediaPath == "" {
+		return out
+	}
+	entries, err := os.ReadDir(mediaPath)
+	if err != nil {
+		return out
+	}
+	mediaName := filepath.Base(mediaPath)
+	for _, e := range entries {
+		if e.IsDir() {
+			if extraType, ok := cfg.folderType(e.Name()); ok {
+				out = append(out, listExtraFilesInFolder(filepath.Join(mediaPath, e.Name()), extraType, cfg.Template)...)
+			} else if cfg.Seasons && seasonFolderRe.MatchString(e.Name()) {
+				out = append(out, scanExtraFiles(extraScanConfig{Template: cfg.Template, folderTypes: cfg.folderTypes}, filepath.Join(mediaPath, e.Name()))...)
+			}
+			continue
+		}
+		if extraType, stem, ok := parseSuffixedExtraName(e.Name(), mediaName); ok {
+			out = append(out, newExtraFileOnDisk(extraType, stem, filepath.Join(mediaPath, e.Name()), cfg.Template))
+		}
+	}
+	return out
+}
+
+func listExtraFilesInFolder(dir, extraType, tpl string) []extraFileOnDisk {
+	var out []extraFileOnDisk
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		if f.IsDir() || !hasMkvExt(f.Name()) {
+			continue
+		}
+		stem := f.Name()[:len(f.Name())-len(".mkv")]
+		out = append(out, newExtraFileOnDisk(extraType, stem, filepath.Join(dir, f.Name()), tpl))
+	}
+	return out
+}
+
+func newExtraFileOnDisk(extraType, stem, path, tpl string) extraFileOnDisk {
+	f := extraFileOnDisk{ExtraType: extraType, Title: stem, Path: path}
+	if meta, ok := readExtraSidecar(path); ok && meta.ExtraTitle != "" {
+		f.Title = SanitizeFilename(meta.ExtraTitle)
+		f.YouTubeID = meta.YouTubeID
+	} else if tpl == DefaultNamingTemplate {
+		return f
+	} else if title, ok := parseNamingTemplate(tpl, stem); ok {
+		f.Title = title
+	}
+	return f
+}
+
+// currentNamingTemplate returns the configured filename template.
+func currentNamingTemplate() string {
+	cfg, _ := GetNamingConfig()
+	return cfg.Template
+}
+
+// parseSuffixedExtraName parses "<MediaName>-<title>-<suffix>.mkv" or
+// "<MediaName>-<suffix>.mkv" into its canonical type and title. Files in the
+// media folder without the media name prefix, such as a movie whose title
+// happens to end in "-short", are not extras.
+func parseSuffixedExtraName(name, mediaName string) (string, string, bool) {
+	if !hasMkvExt(name) {
+		return "", "", false
+	}
+	base := name[:len(name)-len(".mkv")]
+	idx := strings.LastIndex(base, "-")
+	if idx < 0 {
+		return "", "", false
+	}
+	extraType, ok := extraTypeForSuffix(strings.ToLower(base[idx+1:]))
+	if !ok {
+		return "", "", false
+	}
+	title := base[:idx]
+	if title != mediaName {
+		trimmed, ok := strings.CutPrefix(title, mediaName+"-")
+		if !ok || trimmed == "" {
+			return "", "", false
+		}
+		title = trimmed
+	}
+	return extraType, title, true
+}
+
+func extraTypeForSuffix(suffix string) (string, bool) {
+	for t, s := range extraTypeSuffixes {
+		if s == suffix {
+			return t, true
+		}
+	}
+	t, ok := extraSuffixAliases[suffix]
+	return t, ok
+}
+
+// canonicalTypeForFolder maps a folder name in any case (eg. "trailers") to its
+// canonical extra type. Unknown folders are returned unchanged.
+func canonicalTypeForFolder(name string) string {
+	for t := range extraTypeSuffixes {
+		if strings.EqualFold(name, t) {
+			return t
+		}
+	}
+	if cfg, err := GetCanonicalizeExtraTypeConfig(); err == nil {
+		for _, t := range cfg.Mapping {
+			if strings.EqualFold(name, t) {
+				return t
+			}
+		}
+	}
+	return name
+}
+
+// isKnownExtraType reports whether t is a canonical extra type, as opposed to
+// an unrelated folder such as "Season 1".
+func isKnownExtraType(t string) bool {
+	return extraTypeSuffixes[t] != "" || isMappedExtraType(t)
+}
+
+func isMappedExtraType(t string) bool {
+	cfg, err := GetCanonicalizeExtraTypeConfig()
+	if err != nil {
+		return false
+	}
+	for _, v := range cfg.Mapping {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func hasMkvExt(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".mkv")
+}
@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateNamingTemplate(t *testing.T) {
+	for _, tpl := range []string{"{ExtraTitle}", "{MediaTitle} ({Year}) - {ExtraTitle} [{Resolution}]", "{YouTubeId}"} {
+		if err := validateNamingTemplate(tpl); err != nil {
+			t.Fatalf("expected %q valid, got %v", tpl, err)
+		}
+	}
+	for _, tpl := range []string{"", "{Bogus} {ExtraTitle}", "{MediaTitle}", "{ExtraTitle", "sub/{ExtraTitle}"} {
+		if err := validateNamingTemplate(tpl); err == nil {
+			t.Fatalf("expected %q to be rejected", tpl)
+		}
+	}
+}
+
+func TestRenderNamingTemplateTidiesEmptyTokens(t *testing.T) {
+	v := namingValues{MediaTitle: "Alien", Year: "1979", ExtraType: "Trailers", ExtraTitle: "Official: Trailer", YouTubeID: "abc"}
+	got := renderNamingTemplate("{MediaTitle} ({Year}) - {ExtraTitle} [{Resolution}] - {Language}", v)
+	if got != "Alien (1979) - Official_ Trailer" {
+		t.Fatalf("unexpected render: %q", got)
+	}
+	v.Resolution, v.Language = "1080p", "en"
+	got = renderNamingTemplate("{ExtraTitle} [{Resolution}] - {Language} - {YouTubeId}", v)
+	if got != "Official_ Trailer [1080p] - en - abc" {
+		t.Fatalf("unexpected render: %q", got)
+	}
+}
+
+func TestParseNamingTemplate(t *testing.T) {
+	tpl := "{MediaTitle} - {ExtraTitle} [{YouTubeId}]"
+	if title, ok := parseNamingTemplate(tpl, "Alien - Teaser [abc] (2)"); !ok || title != "Teaser" {
+		t.Fatalf("expected Teaser, got %q %v", title, ok)
+	}
+	if _, ok := parseNamingTemplate(tpl, "Something else"); ok {
+		t.Fatalf("expected no match")
+	}
+}
+
+func TestResolveExtraFileCollision(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Trailer.mkv")
+	if got := resolveExtraFileCollision(file, "one"); got != file {
+		t.Fatalf("expected free path, got %s", got)
+	}
+	_ = os.WriteFile(file, []byte("x"), 0o644)
+	_ = WriteJSONFile(strings.TrimSuffix(file, ".mkv")+mkvJSONSuffix, map[string]string{"extraTitle": "Trailer", "youtubeId": "one"})
+	if got := resolveExtraFileCollision(file, "one"); got != file {
+		t.Fatalf("same video should reuse its path, got %s", got)
+	}
+	if got := resolveExtraFileCollision(file, "two"); got != filepath.Join(dir, "Trailer (2).mkv") {
+		t.Fatalf("expected numbered path for a different video, got %s", got)
+	}
+	unmanaged := filepath.Join(dir, "Teaser.mkv")
+	_ = os.WriteFile(unmanaged, []byte("x"), 0o644)
+	if got := resolveExtraFileCollision(unmanaged, "one"); got != filepath.Join(dir, "Teaser (2).mkv") {
+		t.Fatalf("expected numbered path next to a video without sidecar, got %s", got)
+	}
+}
+
+func TestQueuedSidecarSkippedForDownloadedVideoTokens(t *testing.T) {
+	CreateTempConfig(t)
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	t.Cleanup(func() { _ = GetStoreClient().Del(ctx, DownloadQueue) })
+	movieDir := t.TempDir()
+	if err := SaveMediaToStore(MoviesStoreKey, []map[string]interface{}{{"id": 5611, "title": "Z", "path": movieDir}}); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+	if err := SaveNamingConfig(NamingConfig{Scheme: NamingSchemePlex, Template: "{ExtraTitle} [{Resolution}]"}); err != nil {
+		t.Fatalf("save naming: %v", err)
+	}
+	r := NewTestRouter()
+	r.POST("/api/extras/download", downloadExtraHandler)
+	w := DoRequest(r, "POST", "/api/extras/download", []byte(`{"mediaType":"movie","mediaId":5611,"extraType":"Trailers","extraTitle":"ET","youtubeId":"ytres"}`))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	if matches, _ := filepath.Glob(filepath.Join(movieDir, "Trailers", "*.json")); len(matches) != 0 {
+		t.Fatalf("expected no queued sidecar while the file name is unknown, got %v", matches)
+	}
+}
+
+func TestScanExistingExtrasUnderstandsTemplates(t *testing.T) {
+	CreateTempConfig(t)
+	if err := SaveNamingConfig(NamingConfig{Scheme: NamingSchemePlex, Template: "{ExtraTitle} [{YouTubeId}]"}); err != nil {
+		t.Fatalf("save naming config: %v", err)
+	}
+	media := t.TempDir()
+	dir := filepath.Join(media, "Trailers")
+	_ = os.MkdirAll(dir, 0o755)
+	// Named by the template, no sidecar
+	_ = os.WriteFile(filepath.Join(dir, "Teaser [abc].mkv"), []byte("x"), 0o644)
+	// Collision-numbered file identified by its sidecar
+	second := filepath.Join(dir, "Teaser [abc] (2).mkv")
+	_ = os.WriteFile(second, []byte("x"), 0o644)
+	_ = WriteJSONFile(strings.TrimSuffix(second, ".mkv")+mkvJSONSuffix, map[string]string{"extraTitle": "Final Trailer", "youtubeId": "def"})
+
+	got := ScanExistingExtras(media)
+	if !got["Trailers|Teaser"] || !got["Trailers|Final Trailer"] || len(got) != 2 {
+		t.Fatalf("unexpected scan result: %v", got)
+	}
+}
+
+func TestPrepareDownloadInfoRendersTemplateAndAvoidsCollision(t *testing.T) {
+	CreateTempConfig(t)
+	if err := SaveNamingConfig(NamingConfig{Scheme: NamingSchemePlex, Template: "{ExtraType} - {ExtraTitle}"}); err != nil {
+		t.Fatalf("save naming config: %v", err)
+	}
+	info, err := prepareDownloadInfo(MediaTypeMovie, 1, "Trailers", "Teaser", "vidOne")
+	if err != nil {
+		t.Fatalf("prepareDownloadInfo failed: %v", err)
+	}
+	os.RemoveAll(info.TempDir)
+	if filepath.Base(info.OutFile) != "Trailers - Teaser.mkv" {
+		t.Fatalf("unexpected file name %s", info.OutFile)
+	}
+	_ = os.MkdirAll(info.OutDir, 0o755)
+	_ = os.WriteFile(info.OutFile, []byte("x"), 0o644)
+	writeMetaFile(NewExtraDownloadMetadata(info, "vidOne", "downloaded"), info.OutFile)
+	defer os.Remove(info.OutFile)
+	defer os.Remove(info.OutFile + ".json")
+
+	other, err := prepareDownloadInfo(MediaTypeMovie, 1, "Trailers", "Teaser", "vidTwo")
+	if err != nil {
+		t.Fatalf("prepareDownloadInfo failed: %v", err)
+	}
+	os.RemoveAll(other.TempDir)
+	if filepath.Base(other.OutFile) != "Trailers - Teaser (2).mkv" {
+		t.Fatalf("expected collision suffix, got %s", other.OutFile)
+	}
+}
+
+func TestApplyDownloadedVideoTokens(t *testing.T) {
+	media := t.TempDir()
+	tmp := t.TempDir()
+	info := &downloadInfo{
+		MediaPath: media,
+		TempFile:  filepath.Join(tmp, "Teaser.mkv"),
+		Naming:    NamingConfig{Scheme: NamingSchemePlex, Template: "{ExtraTitle} [{Resolution}] {Language}"},
+		Values:    namingValues{ExtraType: "Trailers", ExtraTitle: "Teaser", YouTubeID: "abc"},
+	}
+	data, _ := json.Marshal(map[string]interface{}{"height": 2160, "language": "fr"})
+	_ = os.WriteFile(filepath.Join(tmp, "Teaser.info.json"), data, 0o644)
+
+	applyDownloadedVideoTokens(info)
+	if want := filepath.Join(media, "Trailers", "Teaser [2160p] fr.mkv"); info.OutFile != want {
+		t.Fatalf("expected %s, got %s", want, info.OutFile)
+	}
+	if args := strings.Join(buildYtDlpArgs(info, "abc", false), " "); !strings.Contains(args, "--write-info-json") {
+		t.Fatalf("expected --write-info-json in args: %s", args)
+	}
+}
+
+func TestPreviewNamingConfigHandler(t *testing.T) {
+	r := NewTestRouter()
+	r.POST("/api/settings/naming/preview", PreviewNamingConfigHandler)
+	w := DoRequest(r, "POST", "/api/settings/naming/preview", []byte(`{"scheme":"plex","template":"{MediaTitle} ({Year}) - {ExtraTitle} [{Resolution}]"}`))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"path":"Alien (1979)/Trailers/Alien (1979) - Official Trailer [1080p].mkv"`) {
+		t.Fatalf("unexpected preview: %d %s", w.Code, w.Body.String())
+	}
+	w = DoRequest(r, "POST", "/api/settings/naming/preview", []byte(`{"scheme":"plex","template":"{Nope}"}`))
+	if w.Code != 400 || !strings.Contains(w.Body.String(), "unknown token") {
+		t.Fatalf("expected 400 for unknown token, got %d %s", w.Code, w.Body.String())
+	}
+}
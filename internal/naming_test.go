@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtraOutputPathSchemes(t *testing.T) {
+	media := filepath.Join("/movies", "Alien (1979)")
+	cases := []struct {
+		scheme, wantDir, wantFile string
+	}{
+		{NamingSchemePlex, "/movies/Alien (1979)/Behind The Scenes", "/movies/Alien (1979)/Behind The Scenes/Making Of.mkv"},
+		{NamingSchemeLowercase, "/movies/Alien (1979)/behind the scenes", "/movies/Alien (1979)/behind the scenes/Making Of.mkv"},
+		{NamingSchemeJellyfin, "/movies/Alien (1979)", "/movies/Alien (1979)/Alien (1979)-Making Of-behindthescenes.mkv"},
+	}
+	for _, tc := range cases {
+		dir, file := extraOutputPath(tc.scheme, media, string(BehindTheScenes), "Making Of", "mkv")
+		if dir != tc.wantDir || file != tc.wantFile {
+			t.Fatalf("%s: got (%s, %s), want (%s, %s)", tc.scheme, dir, file, tc.wantDir, tc.wantFile)
+		}
+	}
+}
+
+func TestScanExistingExtrasUnderstandsEveryScheme(t *testing.T) {
+	media := filepath.Join(t.TempDir(), "Heat (1995)")
+	for _, p := range []string{
+		"Trailers/Official.mkv",
+		"featurettes/Cast.mkv",
+		"Heat (1995)-Teaser-trailer.mkv",
+		"Heat (1995)-Bank Scene-scene.mkv",
+		"Heat (1995).mkv",        // the movie itself
+		"Other Film-trailer.mkv", // not named after this movie
+	} {
+		full := filepath.Join(media, p)
+		_ = os.MkdirAll(filepath.Dir(full), 0o755)
+		_ = os.WriteFile(full, []byte("x"), 0o644)
+	}
+
+	got := ScanExistingExtras(media)
+	for _, key := range []string{"Trailers|Official", "Featurettes|Cast", "Trailers|Teaser", "Scenes|Bank Scene"} {
+		if !got[key] {
+			t.Fatalf("expected %s detected, got %v", key, got)
+		}
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected exactly 4 extras (movie file excluded), got %v", got)
+	}
+}
+
+func TestScanExtraFilesOnlyReadsExtraFolders(t *testing.T) {
+	media := filepath.Join(t.TempDir(), "Severance")
+	for _, p := range []string{
+		"Trailers/Official.mkv",
+		"Backups/Old.mkv",
+		"Season 01/Trailers/S1 Trailer.mkv",
+		"Season 01/Episode 1.mkv",
+	} {
+		full := filepath.Join(media, p)
+		_ = os.MkdirAll(filepath.Dir(full), 0o755)
+		_ = os.WriteFile(full, []byte("x"), 0o644)
+	}
+
+	cfg := loadExtraScanConfig()
+	names := func() []string {
+		var out []string
+		for _, f := range scanExtraFiles(cfg, media) {
+			out = append(out, f.ExtraType+"|"+f.Title)
+		}
+		return out
+	}
+	if got := names(); len(got) != 1 || got[0] != "Trailers|Official" {
+		t.Fatalf("expected only the Trailers folder read, got %v", got)
+	}
+	cfg.Seasons = true
+	if got := names(); len(got) != 2 || got[0] != "Trailers|S1 Trailer" {
+		t.Fatalf("expected season extras listed too, got %v", got)
+	}
+}
+
+func TestHasTrailerFilesJellyfinSuffix(t *testing.T) {
+	media := filepath.Join(t.TempDir(), "Movie")
+	_ = os.MkdirAll(media, 0o755)
+	_ = os.WriteFile(filepath.Join(media, "Movie-featurette.mkv"), []byte("x"), 0o644)
+	if hasTrailerFiles(media) {
+		t.Fatalf("featurette must not count as trailer")
+	}
+	_ = os.WriteFile(filepath.Join(media, "Sequel-trailer.mkv"), []byte("x"), 0o644)
+	if hasTrailerFiles(media) {
+		t.Fatalf("a trailer named after another movie must not count")
+	}
+	_ = os.WriteFile(filepath.Join(media, "Movie-trailer.mkv"), []byte("x"), 0o644)
+	if !hasTrailerFiles(media) {
+		t.Fatalf("expected -trailer.mkv to count as trailer")
+	}
+}
+
+func TestDeleteExtraFilesFindsAnyScheme(t *testing.T) {
+	media := filepath.Join(t.TempDir(), "Dune (2021)")
+	_ = os.MkdirAll(media, 0o755)
+	file := filepath.Join(media, "Dune (2021)-Teaser-trailer.mkv")
+	meta := strings.TrimSuffix(file, ".mkv") + mkvJSONSuffix
+	_ = os.WriteFile(file, []byte("x"), 0o644)
+	_ = os.WriteFile(meta, []byte("{}"), 0o644)
+
+	if err := deleteExtraFiles(media, "Trailers", "Teaser"); err != nil {
+		t.Fatalf("deleteExtraFiles failed: %v", err)
+	}
+	for _, p := range []string{file, meta} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("expected %s removed", filepath.Base(p))
+		}
+	}
+}
+
+func TestPrepareDownloadInfoUsesNamingScheme(t *testing.T) {
+	CreateTempConfig(t)
+	if err := SaveNamingConfig(NamingConfig{Scheme: NamingSchemeJellyfin}); err != nil {
+		t.Fatalf("save naming config: %v", err)
+	}
+	info, err := prepareDownloadInfo(MediaTypeMovie, 1, "Trailers", "Teaser", "namingYt")
+	if err != nil {
+		t.Fatalf("prepareDownloadInfo failed: %v", err)
+	}
+	defer os.RemoveAll(info.TempDir)
+	if info.OutDir != info.MediaPath || !strings.HasSuffix(info.OutFile, "-Teaser-trailer.mkv") {
+		t.Fatalf("expected jellyfin naming next to media, got dir=%s file=%s media=%s", info.OutDir, info.OutFile, info.MediaPath)
+	}
+}
+
+func TestSaveNamingConfigHandlerValidatesScheme(t *testing.T) {
+	CreateTempConfig(t)
+	r := NewTestRouter()
+	r.POST("/api/settings/naming", SaveNamingConfigHandler)
+	if w := DoRequest(r, "POST", "/api/settings/naming", []byte(`{"scheme":"bogus"}`)); w.Code != 400 {
+		t.Fatalf("expected 400 for unknown scheme, got %d", w.Code)
+	}
+	if w := DoRequest(r, "POST", "/api/settings/naming", []byte(`{"scheme":"lowercase"}`)); w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if cfg, _ := GetNamingConfig(); cfg.Scheme != NamingSchemeLowercase {
+		t.Fatalf("expected lowercase scheme saved, got %+v", cfg)
+	}
+}
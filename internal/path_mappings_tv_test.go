@@ -9,17 +9,13 @@ import (
 func TestGetPathMappingsTVAndProviderMissing(t *testing.T) {
 	tmp := t.TempDir()
 	oldRoot := TrailarrRoot
-	oldConfigPath := ConfigPath
-	defer func() {
-		TrailarrRoot = oldRoot
-		ConfigPath = oldConfigPath
-	}()
+	defer func() { TrailarrRoot = oldRoot }()
 	TrailarrRoot = tmp
 	cfgDir := filepath.Join(TrailarrRoot, "config")
 	if err := os.MkdirAll(cfgDir, 0755); err != nil {
 		t.Fatalf("failed to create config dir: %v", err)
 	}
-	ConfigPath = filepath.Join(cfgDir, "config.yml")
+	setConfigPathForTest(t, filepath.Join(cfgDir, "config.yml"))
 
 	content := []byte("sonarr:\n  url: http://sonarr.local\n  apiKey: SKEY\n  pathMappings:\n    - from: /mnt/tv\n      to: /media/tv\n")
 	WriteConfig(t, content)
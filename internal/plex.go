@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Above this many folders in one library section, the whole section is scanned instead.
+var PlexRefreshSectionThreshold = 25
+
+// PlexConfig is the Plex connection used to refresh libraries after extras change.
+type PlexConfig struct {
+	Enabled      bool                     `yaml:"enabled" json:"enabled"`
+	URL          string                   `yaml:"url" json:"url"`
+	Token        string                   `yaml:"token" json:"token"`
+	PathMappings []MediaServerPathMapping `yaml:"pathMappings" json:"pathMappings"`
+}
+
+func DefaultPlexConfig() PlexConfig {
+	return PlexConfig{
+		Enabled:      false,
+		URL:          "http://localhost:32400",
+		Token:        "",
+		PathMappings: []MediaServerPathMapping{},
+	}
+}
+
+// GetPlexConfig loads the Plex config from config.yml
+func GetPlexConfig() (PlexConfig, error) {
+	cfg := DefaultPlexConfig()
+	if err := decodeConfigSection("plex", &cfg); err != nil {
+		return DefaultPlexConfig(), err
+	}
+	return cfg, nil
+}
+
+// SavePlexConfig saves the Plex config to config.yml
+func SavePlexConfig(cfg PlexConfig) error {
+	return saveConfigSection("plex", cfg)
+}
+
+func ensurePlexDefaults(config map[string]interface{}) bool {
+	if _, ok := config["plex"].(map[string]interface{}); ok {
+		return false
+	}
+	config["plex"] = DefaultPlexConfig()
+	return true
+}
+
+// Handler to get Plex config
+func GetPlexConfigHandler(c *gin.Context) {
+	cfg, _ := GetPlexConfig()
+	respondJSON(c, http.StatusOK, cfg)
+}
+
+// Handler to save Plex config
+func SavePlexConfigHandler(c *gin.Context) {
+	var req PlexConfig
+	if err := c.BindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	req.URL = trimTrailingSlash(strings.TrimSpace(req.URL))
+	if req.Enabled && req.URL == "" {
+		respondError(c, http.StatusBadRequest, "plex url is required")
+		return
+	}
+	if err := SavePlexConfig(req); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
+}
+
+// plexSection is a Plex library section and the folders it scans.
+type plexSection struct {
+	Key      string `json:"key"`
+	Location []struct {
+		Path string `json:"path"`
+	} `json:"Location"`
+}
+
+// refreshPlexFolders asks Plex to scan the library folders of the given media
+// folders, falling back to a full section scan for large batches.
+func refreshPlexFolders(folders []string) {
+	cfg, err := GetPlexConfig()
+	if err != nil || !cfg.Enabled {
+		return
+	}
+	mappings, _ := getSectionPathMappings("plex")
+	sections, err := fetchPlexSections(cfg)
+	if err != nil {
+		TrailarrLog(WARN, "Plex", "Failed to list library sections: %v", err)
+		return
+	}
+	for section, paths := range groupPlexScanPaths(sections, mappings, folders) {
+		if len(paths) > PlexRefreshSectionThreshold {
+			paths = []string{""}
+		}
+		for _, path := range paths {
+			if err := plexRefreshSection(cfg, section, path); err != nil {
+				TrailarrLog(WARN, "Plex", "Refresh of section %s path %q failed: %v", section, path, err)
+			}
+		}
+	}
+}
+
+// groupPlexScanPaths maps local folders to Plex paths grouped by the library
+// section whose location contains them. Folders outside every section are skipped.
+func groupPlexScanPaths(sections []plexSection, mappings [][]string, folders []string) map[string][]string {
+	bySection := map[string][]string{}
+	for _, folder := range folders {
+		plexPath := applyPathMappings(folder, mappings)
+		section, ok := plexSectionForPath(sections, plexPath)
+		if !ok {
+			TrailarrLog(DEBUG, "Plex", "No Plex library section contains %s; skipping refresh", plexPath)
+			continue
+		}
+		bySection[section] = append(bySection[section], plexPath)
+	}
+	return bySection
+}
+
+// plexSectionForPath returns the key of the section with a location containing p.
+func plexSectionForPath(sections []plexSection, p string) (string, bool) {
+	for _, sec := range sections {
+		for _, loc := range sec.Location {
+			root := strings.TrimSuffix(loc.Path, "/")
+			if root != "" && (p == root || strings.HasPrefix(p, root+"/")) {
+				return sec.Key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// fetchPlexSections lists the library sections with their folder locations.
+func fetchPlexSections(cfg PlexConfig) ([]plexSection, error) {
+	resp, err := plexRequest(cfg, "/library/sections")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		MediaContainer struct {
+			Directory []plexSection `json:"Directory"`
+		} `json:"MediaContainer"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.MediaContainer.Directory, nil
+}
+
+// plexRefreshSection asks Plex to scan path in a library section, or the whole
+// section when path is empty.
+func plexRefreshSection(cfg PlexConfig, section, path string) error {
+	endpoint := fmt.Sprintf("/library/sections/%s/refresh", url.PathEscape(section))
+	if path != "" {
+		endpoint += "?path=" + url.QueryEscape(path)
+	}
+	resp, err := plexRequest(cfg, endpoint)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	TrailarrLog(INFO, "Plex", "Requested refresh of section %s path %q", section, path)
+	return nil
+}
+
+// plexRequest sends an authenticated GET asking for JSON and fails on non-200 responses.
+func plexRequest(cfg PlexConfig, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", trimTrailingSlash(cfg.URL)+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(HeaderPlexToken, cfg.Token)
+	req.Header.Set("Accept", "application/json")
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf(ApiReturnedStatusFmt, resp.StatusCode)
+	}
+	return resp, nil
+}
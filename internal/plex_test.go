@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// fakePlex lists two library sections and records library refresh requests.
+type fakePlex struct {
+	mu       sync.Mutex
+	requests []string
+	tokens   []string
+}
+
+func (f *fakePlex) handler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/library/sections" {
+		_, _ = w.Write([]byte(`{"MediaContainer":{"Directory":[{"key":"1","Location":[{"path":"/data/movies"}]},{"key":"2","Location":[{"path":"/data/tv/"}]}]}}`))
+		return
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, r.URL.Path+"?"+r.URL.Query().Get("path"))
+	f.tokens = append(f.tokens, r.Header.Get(HeaderPlexToken))
+	f.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+// flush sends the pending refreshes and returns the requests Plex got.
+func (f *fakePlex) flush() []string {
+	flushLibraryRefreshForTest()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := append([]string(nil), f.requests...)
+	sort.Strings(out)
+	return out
+}
+
+func setupFakePlex(t *testing.T) *fakePlex {
+	t.Helper()
+	CreateTempConfig(t)
+	fp := &fakePlex{}
+	srv := httptest.NewServer(http.HandlerFunc(fp.handler))
+	t.Cleanup(srv.Close)
+	cfg := map[string]interface{}{
+		"general": DefaultGeneralConfig(),
+		"plex": map[string]interface{}{
+			"enabled": true,
+			"url":     srv.URL + "/",
+			"token":   "tok",
+			"pathMappings": []map[string]interface{}{
+				{"from": "/media/movies", "to": "/data/movies"},
+				{"from": "/media/tv/", "to": "/data/tv/"},
+			},
+		},
+	}
+	data, _ := json.Marshal(cfg)
+	WriteConfig(t, data)
+
+	oldThreshold := PlexRefreshSectionThreshold
+	t.Cleanup(func() {
+		flushLibraryRefreshForTest()
+		PlexRefreshSectionThreshold = oldThreshold
+	})
+	return fp
+}
+
+func TestPlexRefreshBatchesFoldersPerSection(t *testing.T) {
+	fp := setupFakePlex(t)
+
+	scheduleLibraryRefresh("/media/movies/Alien (1979)")
+	scheduleLibraryRefresh("/media/movies/Alien (1979)/") // duplicate
+	scheduleLibraryRefresh("/media/tv/Dark")
+	scheduleLibraryRefresh("/elsewhere/Unmapped")
+
+	got := fp.flush()
+	want := []string{
+		"/library/sections/1/refresh?/data/movies/Alien (1979)",
+		"/library/sections/2/refresh?/data/tv/Dark",
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("unexpected refresh requests: %v", got)
+	}
+	for _, tok := range fp.tokens {
+		if tok != "tok" {
+			t.Fatalf("expected plex token header, got %q", tok)
+		}
+	}
+}
+
+func TestPlexRefreshScansWholeSectionForLargeBatches(t *testing.T) {
+	fp := setupFakePlex(t)
+	PlexRefreshSectionThreshold = 2
+
+	for _, title := range []string{"A", "B", "C"} {
+		scheduleLibraryRefresh("/media/movies/" + title)
+	}
+	got := fp.flush()
+	if len(got) != 1 || got[0] != "/library/sections/1/refresh?" {
+		t.Fatalf("expected a single full-section refresh, got %v", got)
+	}
+}
+
+func TestPlexRefreshDisabledIsNoop(t *testing.T) {
+	CreateTempConfig(t)
+	scheduleLibraryRefresh("/media/movies/Anything")
+	libraryRefresh.mu.Lock()
+	pending := len(libraryRefresh.pending)
+	libraryRefresh.mu.Unlock()
+	if pending != 0 {
+		t.Fatalf("expected nothing queued while plex is disabled, got %d", pending)
+	}
+}
+
+func TestSavePlexConfigKeepsPathMappings(t *testing.T) {
+	CreateTempConfig(t)
+	cfg := PlexConfig{Enabled: true, URL: "http://plex:32400", Token: "tok", PathMappings: []MediaServerPathMapping{{From: "/media/movies", To: "/data/movies"}}}
+	if err := SavePlexConfig(cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got, _ := GetPlexConfig(); !reflect.DeepEqual(got, cfg) {
+		t.Fatalf("expected %+v saved, got %+v", cfg, got)
+	}
+	if mappings, _ := getSectionPathMappings("plex"); !reflect.DeepEqual(mappings, [][]string{{"/media/movies", "/data/movies"}}) {
+		t.Fatalf("expected the shared mapping reader to see the mapping, got %v", mappings)
+	}
+}
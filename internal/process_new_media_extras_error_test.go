@@ -22,7 +22,7 @@ func TestProcessNewMediaExtrasHandlesTMDBError(t *testing.T) {
 	defer func() { http.DefaultTransport = oldTransport }()
 
 	// seed config and movies cache
-	Config = map[string]interface{}{"general": map[string]interface{}{"tmdbKey": "dummy"}}
+	setLoadedConfig(map[string]interface{}{"general": map[string]interface{}{"tmdbKey": "dummy"}})
 	mediaPath := t.TempDir()
 	items := []map[string]interface{}{{"id": 7, "tmdbId": 2001, "path": mediaPath}}
 	if err := SaveMediaToStore(MoviesStoreKey, items); err != nil {
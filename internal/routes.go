@@ -97,10 +97,35 @@ func RegisterRoutes(r *gin.Engine) {
 
 	// Extras and history endpoints
 	r.POST("/api/extras/download", downloadExtraHandler)
+	r.POST("/api/extras/upload", UploadExtraHandler)
 	r.DELETE("/api/extras", deleteExtraHandler)
 	r.GET("/api/extras/existing", existingExtrasHandler)
+	r.GET("/api/extras/rename", GetExtraRenamesHandler)
+	r.POST("/api/extras/rename", RenameExtrasHandler)
+	r.POST("/api/extras/import", ImportExtrasHandler)
+	r.GET("/api/extras/cleanup", GetExtrasCleanupHandler)
+	r.POST("/api/extras/cleanup", RunExtrasCleanupHandler)
+	r.GET("/api/extras/upgrade", GetExtraUpgradesHandler)
+	r.POST("/api/extras/upgrade", UpgradeExtrasHandler)
+	r.GET("/api/collections", GetCollectionsHandler)
 	r.GET("/api/history", historyHandler)
 
+	r.GET("/api/settings/naming", GetNamingConfigHandler)
+	r.POST("/api/settings/naming", SaveNamingConfigHandler)
+	r.POST("/api/settings/naming/preview", PreviewNamingConfigHandler)
+	r.GET("/api/settings/cleanup", GetCleanupConfigHandler)
+	r.POST("/api/settings/cleanup", SaveCleanupConfigHandler)
+	r.GET("/api/settings/videovalidation", GetVideoValidationConfigHandler)
+	r.POST("/api/settings/videovalidation", SaveVideoValidationConfigHandler)
+	r.GET("/api/settings/upgrade", GetUpgradeConfigHandler)
+	r.POST("/api/settings/upgrade", SaveUpgradeConfigHandler)
+	r.GET("/api/settings/languages", GetLanguageConfigHandler)
+	r.POST("/api/settings/languages", SaveLanguageConfigHandler)
+	r.GET("/api/settings/channels", GetChannelsConfigHandler)
+	r.POST("/api/settings/channels", SaveChannelsConfigHandler)
+	r.GET("/api/settings/collections", GetCollectionsConfigHandler)
+	r.POST("/api/settings/collections", SaveCollectionsConfigHandler)
+
 	// Extra types and canonicalize config endpoints
 	r.GET("/api/settings/extratypes", GetExtraTypesConfigHandler)
 	r.POST("/api/settings/extratypes", SaveExtraTypesConfigHandler)
@@ -191,7 +216,23 @@ func registerDownloadAndBlacklistRoutes(r *gin.Engine) {
 	// Download status endpoints
 	r.GET("/api/extras/status/:youtubeId", GetDownloadStatusHandler)
 	r.POST("/api/extras/status/batch", GetBatchDownloadStatusHandler)
-	// Start the download queue worker
+	r.POST("/api/queue/pause", PauseDownloadQueueHandler)
+	r.POST("/api/queue/resume", ResumeDownloadQueueHandler)
+	r.DELETE("/api/queue/:youtubeId", CancelDownloadQueueItemHandler)
+	r.GET("/api/settings/downloadqueue", GetDownloadQueueConfigHandler)
+	r.POST("/api/settings/downloadqueue", SaveDownloadQueueConfigHandler)
+	// Radarr/Sonarr webhooks, authenticated with the shared secret from /api/settings/webhooks
+	r.POST("/api/webhook/radarr", ArrWebhookHandler(MediaTypeMovie))
+	r.POST("/api/webhook/sonarr", ArrWebhookHandler(MediaTypeTV))
+	r.GET("/api/settings/webhooks", GetWebhookConfigHandler)
+	r.POST("/api/settings/webhooks", SaveWebhookConfigHandler)
+	r.GET("/api/settings/plex", GetPlexConfigHandler)
+	r.POST("/api/settings/plex", SavePlexConfigHandler)
+	for _, server := range jellyfinServers {
+		r.GET("/api/settings/"+server, GetJellyfinConfigHandler(server))
+		r.POST("/api/settings/"+server, SaveJellyfinConfigHandler(server))
+	}
+	// Start the download worker pool
 	StartDownloadQueueWorker()
 	r.GET("/api/blacklist/extras", BlacklistExtrasHandler)
 	r.POST("/api/blacklist/extras/remove", RemoveBlacklistExtraHandler)
@@ -570,6 +611,7 @@ func registerMediaAndSettingsRoutes(r *gin.Engine) {
 		r.GET("/api/"+media.section+"/:id", GetMediaByIdHandler(media.cacheStoreKey, "id"))
 		r.GET("/api/"+media.section+"/:id/extras", sharedExtrasHandler(media.extrasType))
 	}
+	r.GET("/api/series/:id/seasons/:season/extras", seasonExtrasHandler)
 	// Group settings endpoints for Radarr/Sonarr
 	for _, provider := range []string{"radarr", "sonarr"} {
 		r.GET("/api/settings/"+provider, GetSettingsHandler(provider))
@@ -20,7 +20,7 @@ func TestExtraTypesSaveAndGet(t *testing.T) {
 	if cfgMap, err := readConfigFile(); err != nil {
 		t.Fatalf("failed to reload config: %v", err)
 	} else {
-		Config = cfgMap
+		setLoadedConfig(cfgMap)
 	}
 
 	// GET and verify
@@ -62,7 +62,7 @@ func TestCanonicalizeSaveAndGet(t *testing.T) {
 	if cfgMap, err := readConfigFile(); err != nil {
 		t.Fatalf("failed to reload config: %v", err)
 	} else {
-		Config = cfgMap
+		setLoadedConfig(cfgMap)
 	}
 
 	// GET and verify
@@ -24,6 +24,8 @@ sonarr:
 
 	r := NewTestRouter()
 	RegisterRoutes(r)
+	// the queued download must not run after this test ends
+	holdWorkerPool(t)
 
 	// Use fake yt-dlp runner for this test to avoid spawning external process
 	oldRunner := ytDlpRunner
@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExtraSourceSearch marks extras found by the YouTube fallback search rather
+// than listed by TMDB.
+const ExtraSourceSearch = "search"
+
+// SearchFallbackMinScore is the lowest search score a result needs to be
+// downloaded as the trailer of media TMDB has no videos for.
+var SearchFallbackMinScore = 50
+
+// searchFallbackResults is how many search results are scored per media.
+const searchFallbackResults = 10
+
+// Searches that found nothing confident are not repeated for
+// SearchMissBackoff, doubling with every further miss up to SearchMissMaxBackoff.
+var (
+	SearchMissBackoff    = 24 * time.Hour
+	SearchMissMaxBackoff = 30 * 24 * time.Hour
+)
+
+// searchKindTrailer is the kind of search misses of the trailer search fallback.
+const searchKindTrailer = "trailer"
+
+// searchMiss records the searches of one kind that found nothing for a media.
+type searchMiss struct {
+	At     time.Time `json:"at"`
+	Misses int       `json:"misses"`
+}
+
+func searchMissField(kind string, mediaType MediaType, mediaId int) string {
+	return fmt.Sprintf("%s:%s:%d", kind, mediaType, mediaId)
+}
+
+func loadSearchMiss(kind string, mediaType MediaType, mediaId int) (searchMiss, bool) {
+	var miss searchMiss
+	val, err := GetStoreClient().HGet(context.Background(), SearchMissesStoreKey, searchMissField(kind, mediaType, mediaId))
+	if err != nil || json.Unmarshal([]byte(val), &miss) != nil {
+		return searchMiss{}, false
+	}
+	return miss, true
+}
+
+// searchBackedOff reports whether a search of kind for media found nothing
+// too recently to be repeated yet.
+func searchBackedOff(kind string, mediaType MediaType, mediaId int) bool {
+	miss, ok := loadSearchMiss(kind, mediaType, mediaId)
+	if !ok || miss.Misses < 1 {
+		return false
+	}
+	backoff := SearchMissBackoff
+	for i := 1; i < miss.Misses && backoff < SearchMissMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return time.Since(miss.At) < min(backoff, SearchMissMaxBackoff)
+}
+
+// recordSearchMiss records that a search of kind for media found nothing.
+func recordSearchMiss(kind string, mediaType MediaType, mediaId int) {
+	miss, _ := loadSearchMiss(kind, mediaType, mediaId)
+	miss.At, miss.Misses = time.Now(), miss.Misses+1
+	data, _ := json.Marshal(miss)
+	if err := GetStoreClient().HSet(context.Background(), SearchMissesStoreKey, searchMissField(kind, mediaType, mediaId), data); err != nil {
+		TrailarrLog(WARN, "Tasks", "Failed to record %s search miss for %s %d: %v", kind, mediaType, mediaId, err)
+	}
+}
+
+// clearSearchMiss forgets the misses of a search of kind for media once it found something.
+func clearSearchMiss(kind string, mediaType MediaType, mediaId int) {
+	_ = GetStoreClient().HDel(context.Background(), SearchMissesStoreKey, searchMissField(kind, mediaType, mediaId))
+}
+
+// searchFallbackTerms returns the search terms for media: the title and the
+// original title if different, each with the year when known.
+func searchFallbackTerms(target searchTarget) []string {
+	terms := make([]string, 0, len(target.Titles))
+	for _, t := range target.Titles {
+		if target.Year > 0 {
+			t += " " + strconv.Itoa(target.Year)
+		}
+		if !slices.Contains(terms, t) {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// bestSearchFallback returns the best scoring search result that hasn't been
+// rejected for the media, or "" if none scores at least SearchFallbackMinScore.
+func bestSearchFallback(results []gin.H, rejected map[string]struct{}) (string, int) {
+	for _, r := range results {
+		score, _ := r["score"].(int)
+		if score < SearchFallbackMinScore {
+			break
+		}
+		id, _ := r["id"].(gin.H)
+		videoId, _ := id["videoId"].(string)
+		if _, ok := rejected[videoId]; videoId != "" && !ok {
+			return videoId, score
+		}
+	}
+	return "", 0
+}
+
+// searchFallbackTrailer searches YouTube for the trailer of media TMDB lists
+// no videos for. It returns the best confident match as a Trailers extra, or
+// nil if there is none.
+func searchFallbackTrailer(mediaType MediaType, mediaId int) []Extra {
+	if searchBackedOff(searchKindTrailer, mediaType, mediaId) {
+		TrailarrLog(DEBUG, "Tasks", "Skipping search fallback for %s %d: nothing found recently", mediaType, mediaId)
+		return nil
+	}
+	title, originalTitle, err := getTitlesFromCache(mediaType, mediaId)
+	if err != nil || (title == "" && originalTitle == "") {
+		TrailarrLog(DEBUG, "Tasks", "No title for search fallback of %s %d: %v", mediaType, mediaId, err)
+		return nil
+	}
+	target := newSearchTarget(mediaType, mediaId, title, originalTitle)
+	results, _ := searchYtDlpForTerms(searchFallbackTerms(target), searchFallbackResults, target)
+
+	rejected := map[string]struct{}{}
+	for _, r := range GetRejectedExtrasForMedia(mediaType, mediaId) {
+		rejected[r.YoutubeId] = struct{}{}
+	}
+	videoId, score := bestSearchFallback(results, rejected)
+	if videoId == "" {
+		TrailarrLog(INFO, "Tasks", "Search fallback found no confident trailer for %s %d (%q) in %d results", mediaType, mediaId, title, len(results))
+		recordSearchMiss(searchKindTrailer, mediaType, mediaId)
+		return nil
+	}
+	clearSearchMiss(searchKindTrailer, mediaType, mediaId)
+	TrailarrLog(INFO, "Tasks", "Search fallback picked %s (score %d) as trailer for %s %d (%q)", videoId, score, mediaType, mediaId, title)
+	return []Extra{{
+		ExtraType:  string(Trailers),
+		ExtraTitle: "Trailer",
+		YoutubeId:  videoId,
+		Status:     "missing",
+		Source:     ExtraSourceSearch,
+	}}
+}
@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSearchFallbackTerms(t *testing.T) {
+	got := searchFallbackTerms(searchTarget{Titles: []string{"Ringu", "Ringu"}, Year: 1998})
+	if want := []string{"Ringu 1998"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	got = searchFallbackTerms(searchTarget{Titles: []string{"The Ring", "Ringu"}})
+	if want := []string{"The Ring", "Ringu"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestBestSearchFallbackNeedsConfidence(t *testing.T) {
+	result := func(id string, score int) gin.H {
+		return gin.H{"id": gin.H{"videoId": id}, "score": score}
+	}
+	results := []gin.H{result("rejected", 90), result("good", 70), result("weak", 20)}
+	if id, score := bestSearchFallback(results, map[string]struct{}{"rejected": {}}); id != "good" || score != 70 {
+		t.Fatalf("expected best non-rejected result, got %q %d", id, score)
+	}
+	if id, _ := bestSearchFallback(results[2:], nil); id != "" {
+		t.Fatalf("expected no pick below the threshold, got %q", id)
+	}
+}
+
+func TestFetchExtrasOrTMDBFallsBackToSearch(t *testing.T) {
+	CreateTempConfig(t)
+	ctx := context.Background()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[]}`))
+	}))
+	defer ts.Close()
+	oldTransport := http.DefaultTransport
+	http.DefaultTransport = &rewriteTransport{base: oldTransport, target: ts.Listener.Addr().String()}
+	defer func() { http.DefaultTransport = oldTransport }()
+	setConfigForTest(t, map[string]interface{}{"general": map[string]interface{}{"tmdbKey": "dummy"}})
+
+	oldRunner := ytDlpRunner
+	ytDlpRunner = &fakeRunner{searchLines: []string{
+		`{"id":"react1","title":"Obscure Film 1979 trailer reaction","channel_id":"UCaaaaaaaaaaaaaaaaaaaaaa"}`,
+		`{"id":"found1","title":"Obscure Film (1979) Official Trailer","duration":120}`,
+	}}
+	defer func() { ytDlpRunner = oldRunner }()
+
+	media := []map[string]interface{}{{"id": 7171, "tmdbId": 717171, "title": "Obscure Film", "year": 1979, "path": t.TempDir()}}
+	if err := SaveMediaToStore(MoviesStoreKey, media); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+
+	if extras, _, err := fetchExtrasOrTMDB(ExtraTypesConfig{Scenes: true}, MediaTypeMovie, 7171, "Obscure Film", nil); err != nil || len(extras) != 0 {
+		t.Fatalf("expected no search without trailers enabled, got %+v %v", extras, err)
+	}
+	extras, usedTMDB, err := fetchExtrasOrTMDB(ExtraTypesConfig{Trailers: true}, MediaTypeMovie, 7171, "Obscure Film", nil)
+	if err != nil || !usedTMDB || len(extras) != 1 {
+		t.Fatalf("expected one fallback trailer, got %+v %v %v", extras, usedTMDB, err)
+	}
+	if e := extras[0]; e.YoutubeId != "found1" || e.ExtraType != "Trailers" || e.Source != ExtraSourceSearch {
+		t.Fatalf("unexpected fallback extra %+v", e)
+	}
+
+	// The source is kept through the queue onto the downloaded entry
+	ytDlpRunner = &fakeRunner{}
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+	pushQueueItemForTest(t, DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 7171, ExtraType: "Trailers", ExtraTitle: "Trailer", YouTubeID: "found1", ExtraSource: ExtraSourceSearch})
+	runQueuedItemForTest(t)
+	if e, _ := GetExtraByYoutubeId(ctx, "found1", MediaTypeMovie, 7171); e == nil || e.Status != "downloaded" || e.Source != ExtraSourceSearch {
+		t.Fatalf("expected downloaded entry with search source, got %+v", e)
+	}
+}
+
+func TestSearchFallbackBacksOffAfterMiss(t *testing.T) {
+	CreateTempConfig(t)
+	_ = GetStoreClient().Del(context.Background(), SearchMissesStoreKey)
+	media := []map[string]interface{}{{"id": 7172, "title": "Lost Film", "year": 1931}}
+	if err := SaveMediaToStore(MoviesStoreKey, media); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+	runner := &fakeRunner{searchLines: []string{`{"id":"weak1","title":"Unrelated video","duration":4000}`}}
+	oldRunner := ytDlpRunner
+	ytDlpRunner = runner
+	defer func() { ytDlpRunner = oldRunner }()
+	if extras := searchFallbackTrailer(MediaTypeMovie, 7172); extras != nil || runner.searchCount() == 0 {
+		t.Fatalf("expected a search without a confident match, got %+v after %d searches", extras, runner.searchCount())
+	}
+	searches := runner.searchCount()
+	if extras := searchFallbackTrailer(MediaTypeMovie, 7172); extras != nil || runner.searchCount() != searches {
+		t.Fatalf("expected the search to back off after a miss, got %d more searches", runner.searchCount()-searches)
+	}
+
+	// Once the backoff has passed the search runs again and doubles the backoff on another miss
+	miss, _ := loadSearchMiss(searchKindTrailer, MediaTypeMovie, 7172)
+	miss.At = miss.At.Add(-SearchMissBackoff - time.Minute)
+	data, _ := json.Marshal(miss)
+	_ = GetStoreClient().HSet(context.Background(), SearchMissesStoreKey, searchMissField(searchKindTrailer, MediaTypeMovie, 7172), data)
+	searchFallbackTrailer(MediaTypeMovie, 7172)
+	if runner.searchCount() == searches {
+		t.Fatalf("expected the search to run again after the backoff")
+	}
+	if miss, _ := loadSearchMiss(searchKindTrailer, MediaTypeMovie, 7172); miss.Misses != 2 || !searchBackedOff(searchKindTrailer, MediaTypeMovie, 7172) {
+		t.Fatalf("expected a second recorded miss, got %+v", miss)
+	}
+}
@@ -0,0 +1,235 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// searchKeywordPenalties lower the score of results that are rarely real
+// trailers. Keywords are matched as whole words on the normalized title.
+var searchKeywordPenalties = []struct {
+	Keyword string
+	Penalty int
+}{
+	{"reaction", 40},
+	{"reacts", 40},
+	{"reacting", 40},
+	{"fan made", 40},
+	{"fanmade", 40},
+	{"concept", 30},
+	{"parody", 30},
+	{"review", 20},
+}
+
+var (
+	nonAlnumRe = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+	yearRe     = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+)
+
+// SearchScore is how well a YouTube search result matches the media, with
+// the reasons that added up to it (eg. "+15 verified channel").
+type SearchScore struct {
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+func (s *SearchScore) add(points int, format string, args ...interface{}) {
+	if points == 0 {
+		return
+	}
+	s.Score += points
+	s.Reasons = append(s.Reasons, fmt.Sprintf("%+d ", points)+fmt.Sprintf(format, args...))
+}
+
+// searchTarget is the media search results are scored against.
+type searchTarget struct {
+	Titles    []string
+	Year      int
+	Channels  ChannelsConfig
+	MediaType MediaType
+	// ThemeMusic scores results as theme songs instead of trailers
+	ThemeMusic bool
+}
+
+// searchSuffix is appended to every search term.
+func (t searchTarget) searchSuffix() string {
+	switch {
+	case !t.ThemeMusic:
+		return " trailer"
+	case t.MediaType == MediaTypeTV:
+		return " opening theme"
+	default:
+		return " soundtrack main theme"
+	}
+}
+
+// newSearchTarget builds the search target for a movie or series.
+func newSearchTarget(mediaType MediaType, mediaId int, title, originalTitle string) searchTarget {
+	t := searchTarget{MediaType: mediaType}
+	for _, s := range []string{title, originalTitle} {
+		if s != "" {
+			t.Titles = append(t.Titles, s)
+		}
+	}
+	cacheFile, _ := resolveCachePath(mediaType)
+	t.Year, _ = strconv.Atoi(lookupMediaYear(cacheFile, mediaId))
+	t.Channels, _ = GetChannelsConfig()
+	return t
+}
+
+// searchCandidate is a yt-dlp search result as printed by `yt-dlp -j`.
+type searchCandidate struct {
+	ID                string  `json:"id"`
+	Title             string  `json:"title"`
+	Description       string  `json:"description"`
+	Thumbnail         string  `json:"thumbnail"`
+	Channel           string  `json:"channel"`
+	ChannelID         string  `json:"channel_id"`
+	Duration          float64 `json:"duration"`
+	ViewCount         int64   `json:"view_count"`
+	ChannelIsVerified bool    `json:"channel_is_verified"`
+}
+
+func parseSearchCandidate(line []byte) (searchCandidate, error) {
+	var c searchCandidate
+	err := json.Unmarshal(bytes.TrimSpace(line), &c)
+	return c, err
+}
+
+// hidden reports whether the result comes from a channel that may not be used.
+func (c searchCandidate) hidden(target searchTarget) bool {
+	return target.Channels.rejectReason(c.ChannelID, c.Channel) != ""
+}
+
+// result returns the search result sent to the UI.
+func (c searchCandidate) result(score SearchScore) gin.H {
+	return gin.H{
+		"id": gin.H{"videoId": c.ID},
+		"snippet": gin.H{
+			"title":       c.Title,
+			"description": c.Description,
+			"thumbnails": gin.H{
+				"default": gin.H{"url": c.Thumbnail},
+			},
+			"channelTitle":    c.Channel,
+			"channelId":       c.ChannelID,
+			"channelVerified": c.ChannelIsVerified,
+			"duration":        c.Duration,
+			"viewCount":       c.ViewCount,
+		},
+		"score":   score.Score,
+		"reasons": score.Reasons,
+	}
+}
+
+// normalizeSearchText lowercases s and reduces it to space separated words.
+func normalizeSearchText(s string) string {
+	return strings.TrimSpace(nonAlnumRe.ReplaceAllString(strings.ToLower(s), " "))
+}
+
+// titleCoverage returns the share of the words of title found in videoTitle.
+func titleCoverage(title, videoTitle string) float64 {
+	words := strings.Fields(normalizeSearchText(title))
+	if len(words) == 0 {
+		return 0
+	}
+	have := map[string]bool{}
+	for _, w := range strings.Fields(normalizeSearchText(videoTitle)) {
+		have[w] = true
+	}
+	found := 0
+	for _, w := range words {
+		if have[w] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(words))
+}
+
+// scoreSearchCandidate scores a search result on title similarity, year,
+// duration, views, channel verification and allowlist, and keyword penalties.
+func scoreSearchCandidate(c searchCandidate, target searchTarget) SearchScore {
+	score := SearchScore{Reasons: []string{}}
+	title := " " + normalizeSearchText(c.Title) + " "
+
+	best := 0.0
+	for _, t := range target.Titles {
+		best = math.Max(best, titleCoverage(t, c.Title))
+	}
+	score.add(int(math.Round(best*40)), "title match %d%%", int(math.Round(best*100)))
+
+	if target.Year > 0 {
+		year := strconv.Itoa(target.Year)
+		if strings.Contains(title, " "+year+" ") {
+			score.add(10, "year %s", year)
+		} else {
+			for _, y := range yearRe.FindAllString(c.Title, -1) {
+				if n, _ := strconv.Atoi(y); n < target.Year-1 || n > target.Year+1 {
+					score.add(-20, "different year %s", y)
+					break
+				}
+			}
+		}
+	}
+
+	if target.ThemeMusic {
+		scoreThemeMusicFit(&score, title, c.Duration)
+	} else {
+		if strings.Contains(title, " trailer ") || strings.Contains(title, " teaser ") {
+			score.add(10, "trailer in title")
+		}
+		if strings.Contains(title, " official ") {
+			score.add(5, "official in title")
+		}
+		scoreTrailerLength(&score, c.Duration)
+	}
+
+	if c.ViewCount > 0 {
+		points := int(math.Log10(float64(c.ViewCount))) - 2
+		score.add(max(0, min(points, 8)), "%d views", c.ViewCount)
+	}
+	if c.ChannelIsVerified {
+		score.add(15, "verified channel")
+	}
+	if target.Channels.allowed(c.ChannelID) {
+		score.add(30, "allowed channel")
+	}
+
+	for _, p := range searchKeywordPenalties {
+		if strings.Contains(title, " "+p.Keyword+" ") {
+			score.add(-p.Penalty, "%q in title", p.Keyword)
+		}
+	}
+	return score
+}
+
+// scoreTrailerLength scores how close a result is to the length of a trailer.
+func scoreTrailerLength(score *SearchScore, duration float64) {
+	switch d := duration; {
+	case d <= 0:
+	case d < 30:
+		score.add(-10, "too short (%.0fs)", d)
+	case d <= 240:
+		score.add(10, "trailer length (%.0fs)", d)
+	case d > 600:
+		score.add(-20, "too long (%.0fs)", d)
+	}
+}
+
+// sortSearchResults orders search results by score, best first, keeping
+// yt-dlp's order for equal scores.
+func sortSearchResults(results []gin.H) {
+	sort.SliceStable(results, func(i, j int) bool {
+		si, _ := results[i]["score"].(int)
+		sj, _ := results[j]["score"].(int)
+		return si > sj
+	})
+}
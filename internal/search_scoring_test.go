@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var alienTarget = searchTarget{Titles: []string{"Alien"}, Year: 1979}
+
+func TestScoreSearchCandidatePrefersOfficialTrailer(t *testing.T) {
+	official := searchCandidate{ID: "a", Title: "Alien (1979) Official Trailer", Duration: 130, ViewCount: 2_500_000, ChannelIsVerified: true}
+	reaction := searchCandidate{ID: "b", Title: "Alien Trailer REACTION!", Duration: 900, ViewCount: 40_000}
+	concept := searchCandidate{ID: "c", Title: "Alien 2026 Concept Trailer (Fan-Made)", Duration: 120, ViewCount: 300_000}
+
+	so := scoreSearchCandidate(official, alienTarget)
+	sr := scoreSearchCandidate(reaction, alienTarget)
+	sc := scoreSearchCandidate(concept, alienTarget)
+	if so.Score <= sr.Score || so.Score <= sc.Score {
+		t.Fatalf("expected official trailer to win: official=%+v reaction=%+v concept=%+v", so, sr, sc)
+	}
+	want := []string{"+40 title match 100%", "+10 year 1979", "+10 trailer in title", "+5 official in title", "+10 trailer length (130s)", "+4 2500000 views", "+15 verified channel"}
+	if strings.Join(so.Reasons, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected reasons:\n got %v\nwant %v", so.Reasons, want)
+	}
+	for _, r := range []string{`-40 "reaction" in title`, "-20 too long (900s)"} {
+		if !strings.Contains(strings.Join(sr.Reasons, "|"), r) {
+			t.Fatalf("expected %q in reaction reasons %v", r, sr.Reasons)
+		}
+	}
+	for _, r := range []string{`-30 "concept" in title`, `-40 "fan made" in title`, "-20 different year 2026"} {
+		if !strings.Contains(strings.Join(sc.Reasons, "|"), r) {
+			t.Fatalf("expected %q in concept reasons %v", r, sc.Reasons)
+		}
+	}
+}
+
+func TestScoreSearchCandidateUsesBestTitle(t *testing.T) {
+	target := searchTarget{Titles: []string{"The Lord of the Rings", "Der Herr der Ringe"}}
+	s := scoreSearchCandidate(searchCandidate{Title: "Der Herr der Ringe - Trailer"}, target)
+	if s.Reasons[0] != "+40 title match 100%" {
+		t.Fatalf("expected original title to match fully, got %v", s.Reasons)
+	}
+}
+
+func TestParseYtDlpLineSortsByScore(t *testing.T) {
+	lines := []searchCandidate{
+		{ID: "fan", Title: "Alien fan made trailer"},
+		{ID: "real", Title: "Alien Official Trailer", ChannelIsVerified: true},
+	}
+	results := []gin.H{}
+	seen := map[string]bool{}
+	for _, l := range lines {
+		b, _ := json.Marshal(l)
+		parseYtDlpLine(b, seen, &results, alienTarget)
+	}
+	sortSearchResults(results)
+	if id := results[0]["id"].(gin.H)["videoId"]; id != "real" {
+		t.Fatalf("expected best result first, got %v", id)
+	}
+	if _, ok := results[0]["reasons"].([]string); !ok {
+		t.Fatalf("expected reasons in result, got %+v", results[0])
+	}
+}
+
+func TestHandleYtDlpJSONLineStreamsScore(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	line, _ := json.Marshal(searchCandidate{ID: "real", Title: "Alien Official Trailer"})
+	if n, ok := handleYtDlpJSONLine(line, map[string]bool{}, alienTarget, c); !ok || n != 1 {
+		t.Fatalf("expected one event, got %d %v", n, ok)
+	}
+	event, _ := bufio.NewReader(w.Body).ReadString('\n')
+	var got struct {
+		Score   int      `json:"score"`
+		Reasons []string `json:"reasons"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(event, "data: ")), &got); err != nil || got.Score != 55 || len(got.Reasons) != 3 {
+		t.Fatalf("unexpected event %q: %+v %v", event, got, err)
+	}
+}
@@ -0,0 +1,239 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// seasonFolderRe matches the folder of a season inside a series folder, eg.
+// "Season 1", "Season 01" or "S01".
+var seasonFolderRe = regexp.MustCompile(`(?i)^(?:season|s)[ ._-]*0*(\d+)$`)
+
+// seasonsWithFiles returns the seasons of a Sonarr series that have episode
+// files on disk, from the statistics Sonarr reports for each season.
+// Specials (season 0) are left out.
+func seasonsWithFiles(series map[string]interface{}) []int {
+	seasons, _ := series["seasons"].([]interface{})
+	out := make([]int, 0, len(seasons))
+	for _, raw := range seasons {
+		s, _ := raw.(map[string]interface{})
+		number, _ := s["seasonNumber"].(float64)
+		stats, _ := s["statistics"].(map[string]interface{})
+		files, _ := stats["episodeFileCount"].(float64)
+		if number > 0 && files > 0 {
+			out = append(out, int(number))
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+// findCachedSeries returns the Sonarr series with the given ID from the series cache.
+func findCachedSeries(seriesId int) (map[string]interface{}, error) {
+	items, err := loadCache(SeriesStoreKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if id, ok := parseMediaID(item["id"]); ok && id == seriesId {
+			return item, nil
+		}
+	}
+	return nil, fmt.Errorf("series %d not found", seriesId)
+}
+
+// seasonFolder returns the folder of a season inside a series folder: the
+// existing folder of the season, or "Season 01" as Sonarr names them by default.
+func seasonFolder(seriesPath string, season int) string {
+	entries, _ := os.ReadDir(seriesPath)
+	for _, e := range entries {
+		if m := seasonFolderRe.FindStringSubmatch(e.Name()); e.IsDir() && m != nil {
+			if n, _ := strconv.Atoi(m[1]); n == season {
+				return filepath.Join(seriesPath, e.Name())
+			}
+		}
+	}
+	return filepath.Join(seriesPath, fmt.Sprintf("Season %02d", season))
+}
+
+// useSeasonFolder places a download in the folder of a season of the series
+// instead of the series folder.
+func useSeasonFolder(info *downloadInfo, season int) {
+	info.Season = season
+	info.MediaPath = seasonFolder(info.MediaPath, season)
+	info.OutDir, info.OutFile = resolveExtraOutput(info.Naming, info.MediaPath, info.Values)
+}
+
+// FetchTMDBSeasonExtrasForSeries fetches the TMDB videos of one season of a Sonarr series.
+func FetchTMDBSeasonExtrasForSeries(seriesId, season int) ([]Extra, error) {
+	tmdbKey, err := GetTMDBKey()
+	if err != nil {
+		return nil, err
+	}
+	tmdbId, err := GetTMDBId(MediaTypeTV, seriesId)
+	if err != nil {
+		return nil, err
+	}
+	extras, err := FetchTMDBSeasonExtras(tmdbId, season, tmdbKey, preferredLanguages(MediaTypeTV))
+	if err != nil {
+		return nil, err
+	}
+	for i := range extras {
+		extras[i].ExtraType = canonicalizeExtraType(extras[i].ExtraType)
+	}
+	return extras, nil
+}
+
+// rejectedInSeason keeps the rejected extras of one season, or of the whole
+// series for season 0.
+func rejectedInSeason(rejected []RejectedExtra, season int) []RejectedExtra {
+	out := make([]RejectedExtra, 0, len(rejected))
+	for _, r := range rejected {
+		if r.Season == season {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// seasonExtrasHandler lists the extras of one season of a series, like
+// sharedExtrasHandler does for the whole series.
+func seasonExtrasHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	season, seasonErr := strconv.Atoi(c.Param("season"))
+	if err != nil || seasonErr != nil || season < 1 {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	series, err := findCachedSeries(id)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if !slices.Contains(seasonsWithFiles(series), season) {
+		respondError(c, http.StatusNotFound, fmt.Sprintf("season %d has no episodes on disk", season))
+		return
+	}
+
+	extras, err := SearchSeasonExtras(id, season)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	tmdbExtras, err := FetchTMDBSeasonExtrasForSeries(id, season)
+	if err != nil {
+		TrailarrLog(WARN, "seasonExtrasHandler", "Failed to fetch TMDB season extras: %v", err)
+		tmdbExtras = nil
+	}
+	finalExtras := mergeExtrasPrioritizePersistent(extras, tmdbExtras)
+
+	seriesPath, _ := series["path"].(string)
+	MarkDownloadedExtras(finalExtras, seasonFolder(seriesPath, season), "type", "title")
+	finalExtras = applyRejectedExtras(finalExtras, rejectedInSeason(GetRejectedExtrasForMedia(MediaTypeTV, id), season))
+
+	respondJSON(c, http.StatusOK, gin.H{"extras": finalExtras})
+}
+
+// seasonCheckedField is the field of SeasonsCheckedStoreKey recording that
+// the TMDB extras of a season were fetched.
+func seasonCheckedField(seriesId, season int) string {
+	return fmt.Sprintf("%d:%d", seriesId, season)
+}
+
+// seasonExtrasChecked reports whether the TMDB extras of a season were fetched before.
+func seasonExtrasChecked(ctx context.Context, seriesId, season int) bool {
+	_, err := GetStoreClient().HGet(ctx, SeasonsCheckedStoreKey, seasonCheckedField(seriesId, season))
+	return err == nil
+}
+
+// markSeasonExtrasChecked records that the TMDB extras of a season were
+// fetched, so later runs don't fetch them again.
+func markSeasonExtrasChecked(ctx context.Context, seriesId, season int) {
+	at := []byte(time.Now().Format(time.RFC3339))
+	if err := GetStoreClient().HSet(ctx, SeasonsCheckedStoreKey, seasonCheckedField(seriesId, season), at); err != nil {
+		TrailarrLog(WARN, "Tasks", "Failed to record season %d of series %d as checked: %v", season, seriesId, err)
+	}
+}
+
+// processAllSeasonExtras queues the season extras of every cached series.
+// Series are checked whether or not they are wanted: a series with extras can
+// still get a new season, and a series with only season extras stays wanted.
+func processAllSeasonExtras(ctx context.Context, cfg ExtraTypesConfig) {
+	items, err := loadCache(SeriesStoreKey)
+	if err != nil {
+		TrailarrLog(WARN, "Tasks", "processAllSeasonExtras: %v", err)
+		return
+	}
+	for _, series := range items {
+		if ctx != nil && ctx.Err() != nil {
+			return
+		}
+		processSeasonExtras(ctx, cfg, series)
+	}
+}
+
+// processSeasonExtras queues the missing TMDB extras of each season of a
+// series that has episodes on disk. A season is marked checked, and not fetched
+// from TMDB again, once all its extras were queued.
+func processSeasonExtras(ctx context.Context, cfg ExtraTypesConfig, series map[string]interface{}) {
+	seriesId, ok := parseMediaID(series["id"])
+	if !ok {
+		return
+	}
+	seriesPath, _ := series["path"].(string)
+	var rejected []RejectedExtra
+	for _, season := range seasonsWithFiles(series) {
+		if ctx != nil && ctx.Err() != nil {
+			return
+		}
+		if seasonExtrasChecked(context.Background(), seriesId, season) {
+			continue
+		}
+		extras, err := FetchTMDBSeasonExtrasForSeries(seriesId, season)
+		if err != nil {
+			TrailarrLog(WARN, "Tasks", "TMDB season extras failed for seriesId=%d season=%d: %v", seriesId, season, err)
+			continue
+		}
+		if len(extras) > 0 && rejected == nil {
+			rejected = GetRejectedExtrasForMedia(MediaTypeTV, seriesId)
+		}
+		if queueSeasonExtras(ctx, cfg, seriesId, season, seasonFolder(seriesPath, season), extras, rejected) {
+			markSeasonExtrasChecked(context.Background(), seriesId, season)
+		}
+	}
+}
+
+// queueSeasonExtras queues the missing extras of one season. It reports
+// whether every extra was handled, ie. the run wasn't cancelled and no enqueue failed.
+func queueSeasonExtras(ctx context.Context, cfg ExtraTypesConfig, seriesId, season int, folder string, extras []Extra, rejected []RejectedExtra) bool {
+	if len(extras) == 0 {
+		return true
+	}
+	TrailarrLog(INFO, "Tasks", "Searching extras for tv %d season %d", seriesId, season)
+	MarkDownloadedExtras(extras, folder, "type", "title")
+	rejectedYoutubeIds := map[string]struct{}{}
+	for _, r := range rejectedInSeason(rejected, season) {
+		rejectedYoutubeIds[r.YoutubeId] = struct{}{}
+	}
+	MarkRejectedExtrasInMemory(extras, rejectedYoutubeIds)
+	complete := true
+	for _, extra := range applyLanguagePreferences(MediaTypeTV, extras) {
+		if ctx != nil && ctx.Err() != nil {
+			return false
+		}
+		if err := processExtraDownload(cfg, MediaTypeTV, seriesId, extra, false); err != nil {
+			complete = false
+		}
+	}
+	return complete
+}
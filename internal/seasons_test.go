@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setupSeasonSeries caches a Sonarr series with seasons 1 and 2 on disk and
+// a mapped series folder holding an existing "Season 1" folder.
+func setupSeasonSeries(t *testing.T) string {
+	t.Helper()
+	CreateTempConfig(t)
+	library := t.TempDir()
+	WriteConfig(t, []byte("sonarr:\n  pathMappings:\n    - from: /tv\n      to: "+library+"\n"))
+	setConfigForTest(t, map[string]interface{}{"general": map[string]interface{}{"tmdbKey": "dummy"}})
+	seriesPath := filepath.Join(library, "Severance")
+	if err := os.MkdirAll(filepath.Join(seriesPath, "Season 1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	season := func(n, files int) map[string]interface{} {
+		return map[string]interface{}{"seasonNumber": n, "statistics": map[string]interface{}{"episodeFileCount": files}}
+	}
+	series := []map[string]interface{}{{
+		"id": 6601, "tmdbId": 95396, "title": "Severance", "path": "/tv/Severance",
+		"seasons": []interface{}{season(0, 2), season(1, 9), season(2, 10), season(3, 0)},
+	}}
+	if err := SaveMediaToStore(SeriesStoreKey, series); err != nil {
+		t.Fatalf("save series: %v", err)
+	}
+	return seriesPath
+}
+
+func TestSeasonsWithFilesAndFolders(t *testing.T) {
+	seriesPath := setupSeasonSeries(t)
+	series, err := findCachedSeries(6601)
+	if err != nil {
+		t.Fatalf("find series: %v", err)
+	}
+	if got := seasonsWithFiles(series); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected seasons [1 2] on disk, got %v", got)
+	}
+	if got := seasonFolder(seriesPath, 1); got != filepath.Join(seriesPath, "Season 1") {
+		t.Fatalf("expected existing season folder, got %s", got)
+	}
+	if got := seasonFolder(seriesPath, 2); got != filepath.Join(seriesPath, "Season 02") {
+		t.Fatalf("expected default season folder, got %s", got)
+	}
+}
+
+func TestSeasonExtrasHandler(t *testing.T) {
+	setupSeasonSeries(t)
+	var mu sync.Mutex
+	var paths []string
+	fetched := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		out := paths
+		paths = nil
+		return out
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"results":[{"id":"v1","name":"Season 2 Official Trailer","key":"AAAAAAAAAAA","site":"YouTube","type":"Trailer"},{"id":"v2","name":"Teaser","key":"x","site":"Vimeo","type":"Teaser"}]}`))
+	}))
+	defer ts.Close()
+	oldTransport := http.DefaultTransport
+	http.DefaultTransport = &rewriteTransport{base: oldTransport, target: ts.Listener.Addr().String()}
+	defer func() { http.DefaultTransport = oldTransport }()
+
+	r := NewTestRouter()
+	r.GET("/api/series/:id/seasons/:season/extras", seasonExtrasHandler)
+	w := DoRequest(r, "GET", "/api/series/6601/seasons/2/extras", nil)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	if got := fetched(); len(got) != 1 || got[0] != "/3/tv/95396/season/2/videos" {
+		t.Fatalf("expected the TMDB season videos to be fetched, got %v", got)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"YoutubeId":"AAAAAAAAAAA"`) || !strings.Contains(body, `"Season":2`) || strings.Contains(body, "Vimeo") {
+		t.Fatalf("unexpected season extras %s", body)
+	}
+	for path, code := range map[string]int{
+		"/api/series/6601/seasons/3/extras": 404, // no episodes on disk
+		"/api/series/6601/seasons/0/extras": 400,
+		"/api/series/1/seasons/1/extras":    404,
+	} {
+		if w := DoRequest(r, "GET", path, nil); w.Code != code {
+			t.Fatalf("%s: expected %d, got %d", path, code, w.Code)
+		}
+	}
+}
+
+func TestSeasonExtraDownloadsIntoSeasonFolder(t *testing.T) {
+	seriesPath := setupSeasonSeries(t)
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+	oldRunner := ytDlpRunner
+	ytDlpRunner = &fakeRunner{}
+	defer func() { ytDlpRunner = oldRunner }()
+
+	AddToDownloadQueue(DownloadQueueItem{MediaType: MediaTypeTV, MediaId: 6601, ExtraType: "Trailers", ExtraTitle: "Season 1 Trailer", YouTubeID: "BBBBBBBBBBB", Season: 1, QueuedAt: time.Now()}, QueueSourceAPI)
+	runQueuedItemForTest(t)
+
+	file := filepath.Join(seriesPath, "Season 1", "Trailers", "Season 1 Trailer.mkv")
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("expected season extra in the season folder: %v", err)
+	}
+	e, _ := GetExtraByYoutubeId(ctx, "BBBBBBBBBBB", MediaTypeTV, 6601)
+	if e == nil || e.Status != "downloaded" || e.Season != 1 || e.FileName != file {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if extras, _ := SearchExtras(MediaTypeTV, 6601); len(extras) != 0 {
+		t.Fatalf("season extras must not be listed for the series, got %+v", extras)
+	}
+	if extras, _ := SearchSeasonExtras(6601, 1); len(extras) != 1 || extras[0].Season != 1 {
+		t.Fatalf("expected the season extra, got %+v", extras)
+	}
+}
+
+func TestSeasonExtrasFetchedOncePerSeason(t *testing.T) {
+	setupSeasonSeries(t)
+	ctx := context.Background()
+	holdWorkerPool(t)
+	for _, key := range []string{DownloadQueue, SeasonsCheckedStoreKey} {
+		_ = GetStoreClient().Del(ctx, key)
+	}
+	t.Cleanup(func() { _ = GetStoreClient().Del(ctx, DownloadQueue) })
+	var mu sync.Mutex
+	var paths []string
+	fetched := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		out := paths
+		paths = nil
+		return out
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/season/1/videos") {
+			_, _ = w.Write([]byte(`{"results":[{"id":"v1","name":"Season 1 Trailer","key":"CCCCCCCCCCC","site":"YouTube","type":"Trailer"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"results":[]}`))
+	}))
+	defer ts.Close()
+	oldTransport := http.DefaultTransport
+	http.DefaultTransport = &rewriteTransport{base: oldTransport, target: ts.Listener.Addr().String()}
+	defer func() { http.DefaultTransport = oldTransport }()
+
+	cfg := ExtraTypesConfig{Trailers: true}
+	want := []string{"/3/tv/95396/season/1/videos", "/3/tv/95396/season/2/videos"}
+
+	// A run cancelled while queueing leaves the season unchecked for the next run
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	extras := []Extra{{ExtraType: "Trailers", ExtraTitle: "Season 1 Trailer", YoutubeId: "CCCCCCCCCCC", Season: 1}}
+	if queueSeasonExtras(cancelled, cfg, 6601, 1, t.TempDir(), extras, nil) || len(GetCurrentDownloadQueue()) != 0 {
+		t.Fatalf("expected a cancelled season to be reported incomplete with nothing queued")
+	}
+
+	processAllSeasonExtras(ctx, cfg)
+	if got := fetched(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the seasons on disk to be fetched, got %v", got)
+	}
+	if q := GetCurrentDownloadQueue(); len(q) != 1 || q[0].YouTubeID != "CCCCCCCCCCC" || q[0].Season != 1 {
+		t.Fatalf("expected the season 1 trailer queued, got %+v", q)
+	}
+
+	processAllSeasonExtras(ctx, cfg)
+	if got := fetched(); len(got) != 0 {
+		t.Fatalf("expected checked seasons not to be fetched again, got %v", got)
+	}
+}
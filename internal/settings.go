@@ -1,11 +1,15 @@
 package internal
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -25,6 +29,8 @@ const (
 	SeriesWantedStoreKey     = "trailarr:series:wanted"
 	ExtrasStoreKey           = "trailarr:extras"
 	RejectedExtrasStoreKey   = "trailarr:extras:rejected"
+	SeasonsCheckedStoreKey   = "trailarr:extras:seasons_checked"
+	SearchMissesStoreKey     = "trailarr:extras:search_misses"
 	DownloadQueue            = "trailarr:download_queue"
 	TaskTimesStoreKey        = "trailarr:task_times"
 	HealthIssuesStoreKey     = "trailarr:health_issues"
@@ -34,6 +40,9 @@ const (
 	TaskQueueMaxLen          = 1000
 	RemoteMediaCoverPath     = "/MediaCover/"
 	HeaderApiKey             = "X-Api-Key"
+	HeaderWebhookSecret      = "X-Webhook-Secret"
+	HeaderPlexToken          = "X-Plex-Token"
+	HeaderEmbyToken          = "X-Emby-Token"
 	HeaderContentType        = "Content-Type"
 	ErrInvalidSonarrSettings = "Invalid Sonarr settings"
 	ErrInvalidRequest        = "invalid request"
@@ -54,9 +63,13 @@ var (
 // Global in-memory config
 var Config map[string]interface{}
 
+// configMu guards ConfigPath, Config and config.yml itself: background tasks
+// read the config while handlers save it.
+var configMu sync.RWMutex
+
 // LoadConfig reads config.yml into the global Config variable
 func LoadConfig() error {
-	data, err := os.ReadFile(ConfigPath)
+	data, err := readConfigBytes()
 	if err != nil {
 		return err
 	}
@@ -65,10 +78,69 @@ func LoadConfig() error {
 	if err != nil {
 		return err
 	}
-	Config = cfg
+	setLoadedConfig(cfg)
 	return nil
 }
 
+// loadedConfig returns the in-memory config, nil until it was loaded. Updates
+// replace the map instead of modifying it, so callers may keep reading it.
+func loadedConfig() map[string]interface{} {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return Config
+}
+
+// setLoadedConfig replaces the in-memory config.
+func setLoadedConfig(cfg map[string]interface{}) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	Config = cfg
+}
+
+// setLoadedConfigSection replaces one section of the in-memory config, once
+// it was loaded.
+func setLoadedConfigSection(section string, value interface{}) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	if Config == nil {
+		return
+	}
+	cfg := make(map[string]interface{}, len(Config)+1)
+	for k, v := range Config {
+		cfg[k] = v
+	}
+	cfg[section] = value
+	Config = cfg
+}
+
+// readConfigBytes reads config.yml as is.
+func readConfigBytes() ([]byte, error) {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return os.ReadFile(ConfigPath)
+}
+
+// writeConfigBytes replaces config.yml, creating its folder if needed.
+func writeConfigBytes(data []byte) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	// Ensure parent directory exists to avoid errors when background
+	// goroutines attempt to persist merged settings and the temp test
+	// environment's config dir hasn't been created or was removed.
+	if dir := filepath.Dir(ConfigPath); dir != "" {
+		_ = os.MkdirAll(dir, 0755)
+	}
+	return os.WriteFile(ConfigPath, data, 0644)
+}
+
+// configFileExists reports whether config.yml exists.
+func configFileExists() bool {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	_, err := os.Stat(ConfigPath)
+	return !os.IsNotExist(err)
+}
+
 type PlexType string
 
 const (
@@ -80,6 +152,8 @@ const (
 	Shorts          PlexType = "Shorts"
 	Trailers        PlexType = "Trailers"
 	Other           PlexType = "Other"
+	// ThemeMusic is downloaded as theme.mp3 in the media folder, not as a video extra
+	ThemeMusic PlexType = "Theme Music"
 )
 
 var defaultExtraTypes = ExtraTypesConfig{
@@ -89,6 +163,7 @@ var defaultExtraTypes = ExtraTypesConfig{
 	Interviews:      false,
 	Featurettes:     false,
 	DeletedScenes:   false,
+	ThemeMusic:      false,
 	Other:           false,
 }
 
@@ -105,7 +180,7 @@ func GetCanonicalizeExtraTypeConfig() (CanonicalizeExtraTypeConfig, error) {
 	// overwritten between a test write and this read. To reduce flakiness,
 	// retry a few times when the mapping isn't present.
 	for attempt := 0; attempt < 5; attempt++ {
-		data, err := os.ReadFile(ConfigPath)
+		data, err := readConfigBytes()
 		if err != nil {
 			// If file read failed, retry briefly
 			time.Sleep(10 * time.Millisecond)
@@ -118,7 +193,7 @@ func GetCanonicalizeExtraTypeConfig() (CanonicalizeExtraTypeConfig, error) {
 		time.Sleep(10 * time.Millisecond)
 	}
 	// Final fallback: attempt one last read and return whatever we have.
-	data, err := os.ReadFile(ConfigPath)
+	data, err := readConfigBytes()
 	if err != nil {
 		return CanonicalizeExtraTypeConfig{Mapping: map[string]string{}}, err
 	}
@@ -242,6 +317,39 @@ func EnsureConfigDefaults() error {
 	if ensureCanonicalizeExtraTypeDefaults(config) {
 		changed = true
 	}
+	if ensureDownloadQueueDefaults(config) {
+		changed = true
+	}
+	if ensureWebhookDefaults(config) {
+		changed = true
+	}
+	if ensurePlexDefaults(config) {
+		changed = true
+	}
+	if ensureJellyfinDefaults(config) {
+		changed = true
+	}
+	if ensureNamingDefaults(config) {
+		changed = true
+	}
+	if ensureCleanupDefaults(config) {
+		changed = true
+	}
+	if ensureVideoValidationDefaults(config) {
+		changed = true
+	}
+	if ensureUpgradeDefaults(config) {
+		changed = true
+	}
+	if ensureLanguageDefaults(config) {
+		changed = true
+	}
+	if ensureChannelsDefaults(config) {
+		changed = true
+	}
+	if ensureCollectionsDefaults(config) {
+		changed = true
+	}
 	if changed {
 		return writeConfigFile(config)
 	}
@@ -361,6 +469,7 @@ func ensureExtraTypesDefaults(config map[string]interface{}) bool {
 			"interviews":      defaultExtraTypes.Interviews,
 			"featurettes":     defaultExtraTypes.Featurettes,
 			"deletedScenes":   defaultExtraTypes.DeletedScenes,
+			"themeMusic":      defaultExtraTypes.ThemeMusic,
 			"other":           defaultExtraTypes.Other,
 		}
 		return true
@@ -378,6 +487,7 @@ func ensureExtraTypesDefaults(config map[string]interface{}) bool {
 		"interviews":      defaultExtraTypes.Interviews,
 		"featurettes":     defaultExtraTypes.Featurettes,
 		"deletedScenes":   defaultExtraTypes.DeletedScenes,
+		"themeMusic":      defaultExtraTypes.ThemeMusic,
 		"other":           defaultExtraTypes.Other,
 	}
 	for k, v := range defaults {
@@ -410,9 +520,30 @@ func ensureCanonicalizeExtraTypeDefaults(config map[string]interface{}) bool {
 	return false
 }
 
+func ensureWebhookDefaults(config map[string]interface{}) bool {
+	sec, ok := config["webhooks"].(map[string]interface{})
+	if !ok {
+		sec = map[string]interface{}{}
+	}
+	if secret, _ := sec["secret"].(string); secret != "" {
+		return false
+	}
+	sec["secret"] = generateWebhookSecret()
+	config["webhooks"] = sec
+	return true
+}
+
+func ensureDownloadQueueDefaults(config map[string]interface{}) bool {
+	if _, ok := config["downloadQueue"].(map[string]interface{}); ok {
+		return false
+	}
+	config["downloadQueue"] = DefaultDownloadQueueConfig()
+	return true
+}
+
 // Raw config file reader (no defaults)
 func readConfigFileRaw() (map[string]interface{}, error) {
-	data, err := os.ReadFile(ConfigPath)
+	data, err := readConfigBytes()
 	if err != nil {
 		return nil, err
 	}
@@ -431,7 +562,7 @@ func readConfigFileRaw() (map[string]interface{}, error) {
 // Helper to read config file and unmarshal into map[string]interface{}
 func readConfigFile() (map[string]interface{}, error) {
 	_ = EnsureConfigDefaults()
-	data, err := os.ReadFile(ConfigPath)
+	data, err := readConfigBytes()
 	if err != nil {
 		return nil, err
 	}
@@ -449,14 +580,7 @@ func writeConfigFile(config map[string]interface{}) error {
 	if err != nil {
 		return err
 	}
-	// Ensure parent directory exists to avoid errors when background
-	// goroutines attempt to persist merged settings and the temp test
-	// environment's config dir hasn't been created or was removed.
-	dir := filepath.Dir(ConfigPath)
-	if dir != "" {
-		_ = os.MkdirAll(dir, 0755)
-	}
-	return os.WriteFile(ConfigPath, out, 0644)
+	return writeConfigBytes(out)
 }
 
 // EnsureYtdlpFlagsConfigExists checks config.yml and writes defaults if missing
@@ -485,7 +609,7 @@ func GetYtdlpFlagsConfig() (YtdlpFlagsConfig, error) {
 	// 'ytdlpFlags' section is not present to avoid flakiness.
 	cfg := DefaultYtdlpFlagsConfig()
 	for attempt := 0; attempt < 20; attempt++ {
-		data, err := os.ReadFile(ConfigPath)
+		data, err := readConfigBytes()
 		if err != nil {
 			// If read failed, retry briefly
 			time.Sleep(20 * time.Millisecond)
@@ -674,6 +798,160 @@ func SaveYtdlpFlagsConfigHandler(c *gin.Context) {
 	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
 }
 
+// --- DOWNLOAD QUEUE CONFIG ---
+
+// DownloadQueueConfig controls how many downloads run at the same time.
+// MaxPerHost caps parallel downloads against a single site (0 disables the cap).
+// MaxAttempts is how many times a transient failure is tried before giving up.
+type DownloadQueueConfig struct {
+	MaxConcurrent int `yaml:"maxConcurrent" json:"maxConcurrent"`
+	MaxPerHost    int `yaml:"maxPerHost" json:"maxPerHost"`
+	MaxAttempts   int `yaml:"maxAttempts" json:"maxAttempts"`
+}
+
+func DefaultDownloadQueueConfig() DownloadQueueConfig {
+	return DownloadQueueConfig{
+		MaxConcurrent: 2,
+		MaxPerHost:    2,
+		MaxAttempts:   3,
+	}
+}
+
+// GetDownloadQueueConfig loads the download queue config from config.yml
+func GetDownloadQueueConfig() (DownloadQueueConfig, error) {
+	cfg := DefaultDownloadQueueConfig()
+	if err := decodeConfigSection("downloadQueue", &cfg); err != nil {
+		return DefaultDownloadQueueConfig(), err
+	}
+	if cfg.MaxConcurrent < 1 {
+		cfg.MaxConcurrent = 1
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = DefaultDownloadQueueConfig().MaxAttempts
+	}
+	return cfg, nil
+}
+
+// SaveDownloadQueueConfig saves the download queue config to config.yml
+func SaveDownloadQueueConfig(cfg DownloadQueueConfig) error {
+	return saveConfigSection("downloadQueue", cfg)
+}
+
+// Handler to get download queue config
+func GetDownloadQueueConfigHandler(c *gin.Context) {
+	cfg, _ := GetDownloadQueueConfig()
+	respondJSON(c, http.StatusOK, cfg)
+}
+
+// Handler to save download queue config. Pool size changes apply after a restart.
+func SaveDownloadQueueConfigHandler(c *gin.Context) {
+	var req DownloadQueueConfig
+	if err := c.BindJSON(&req); err != nil || req.MaxConcurrent < 1 || req.MaxPerHost < 0 || req.MaxAttempts < 0 {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	if err := SaveDownloadQueueConfig(req); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
+}
+
+// --- WEBHOOK CONFIG ---
+
+// WebhookConfig holds the shared secret Radarr/Sonarr webhook connections must
+// send, either as ?secret= in the URL, the X-Webhook-Secret header or the basic
+// auth password.
+type WebhookConfig struct {
+	Secret string `yaml:"secret" json:"secret"`
+}
+
+// GetWebhookConfig loads the webhook config from config.yml
+func GetWebhookConfig() (WebhookConfig, error) {
+	var cfg WebhookConfig
+	err := decodeConfigSection("webhooks", &cfg)
+	return cfg, err
+}
+
+// SaveWebhookConfig saves the webhook config to config.yml
+func SaveWebhookConfig(cfg WebhookConfig) error {
+	return saveConfigSection("webhooks", cfg)
+}
+
+// Handler to get webhook config
+func GetWebhookConfigHandler(c *gin.Context) {
+	cfg, _ := GetWebhookConfig()
+	respondJSON(c, http.StatusOK, cfg)
+}
+
+// Handler to save webhook config. An empty secret generates a new one.
+func SaveWebhookConfigHandler(c *gin.Context) {
+	var req WebhookConfig
+	if err := c.BindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	req.Secret = strings.TrimSpace(req.Secret)
+	if req.Secret == "" {
+		req.Secret = generateWebhookSecret()
+	}
+	if err := SaveWebhookConfig(req); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "saved", "secret": req.Secret})
+}
+
+// generateWebhookSecret returns a random hex secret for webhook authentication
+func generateWebhookSecret() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// decodeConfigSection decodes a config.yml section into dst. Keys missing from
+// the file keep whatever value dst already holds, so callers pass defaults in.
+func decodeConfigSection(section string, dst interface{}) error {
+	config, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	sec, ok := config[section]
+	if !ok || sec == nil {
+		return nil
+	}
+	raw, err := yamlv3.Marshal(sec)
+	if err != nil {
+		return err
+	}
+	return yamlv3.Unmarshal(raw, dst)
+}
+
+// saveConfigSection replaces a single section in config.yml with value, a
+// config struct encoded through its yaml tags.
+func saveConfigSection(section string, value interface{}) error {
+	raw, err := yamlv3.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var sec map[string]interface{}
+	if err := yamlv3.Unmarshal(raw, &sec); err != nil {
+		return err
+	}
+	config, err := readConfigFile()
+	if err != nil {
+		config = map[string]interface{}{}
+	}
+	config[section] = sec
+	if err := writeConfigFile(config); err != nil {
+		return err
+	}
+	setLoadedConfigSection(section, sec)
+	return nil
+}
+
 var Timings map[string]int
 
 // ExtraTypesConfig holds config for enabling/disabling specific extra types
@@ -685,21 +963,24 @@ type ExtraTypesConfig struct {
 	Featurettes     bool `yaml:"featurettes" json:"featurettes"`
 	DeletedScenes   bool `yaml:"deletedScenes" json:"deletedScenes"`
 	Shorts          bool `yaml:"shorts" json:"shorts"`
+	ThemeMusic      bool `yaml:"themeMusic" json:"themeMusic"`
 	Other           bool `yaml:"other" json:"other"`
 }
 
 // GetExtraTypesConfig loads extra types config from config.yml
 func GetExtraTypesConfig() (ExtraTypesConfig, error) {
 	// If we don't have an in-memory config, try to read from disk so callers see persisted values.
-	if Config == nil {
-		if cfgMap, err := readConfigFile(); err == nil {
-			Config = cfgMap
-		} else {
+	config := loadedConfig()
+	if config == nil {
+		cfgMap, err := readConfigFile()
+		if err != nil {
 			// If read failed, fall back to defaults
 			return defaultExtraTypes, nil
 		}
+		setLoadedConfig(cfgMap)
+		config = cfgMap
 	}
-	sec, _ := Config["extraTypes"].(map[string]interface{})
+	sec, _ := config["extraTypes"].(map[string]interface{})
 	cfg := ExtraTypesConfig{}
 
 	// helper returns the boolean value from the section or the provided default
@@ -720,6 +1001,7 @@ func GetExtraTypesConfig() (ExtraTypesConfig, error) {
 	cfg.Featurettes = getBool("featurettes", defaultExtraTypes.Featurettes)
 	cfg.DeletedScenes = getBool("deletedScenes", defaultExtraTypes.DeletedScenes)
 	cfg.Shorts = getBool("shorts", defaultExtraTypes.Shorts)
+	cfg.ThemeMusic = getBool("themeMusic", defaultExtraTypes.ThemeMusic)
 	cfg.Other = getBool("other", defaultExtraTypes.Other)
 
 	return cfg, nil
@@ -738,15 +1020,16 @@ func SaveExtraTypesConfig(cfg ExtraTypesConfig) error {
 		"interviews":      cfg.Interviews,
 		"featurettes":     cfg.Featurettes,
 		"deletedScenes":   cfg.DeletedScenes,
+		"themeMusic":      cfg.ThemeMusic,
 		"other":           cfg.Other,
 	}
 	err = writeConfigFile(config)
 	if err == nil {
 		// Update in-memory config to reflect persisted changes so future GETs return them.
-		if Config == nil {
-			Config = config
+		if loadedConfig() == nil {
+			setLoadedConfig(config)
 		} else {
-			Config["extraTypes"] = config["extraTypes"]
+			setLoadedConfigSection("extraTypes", config["extraTypes"])
 		}
 	}
 	return err
@@ -774,6 +1057,7 @@ func GetExtraTypesConfigHandler(c *gin.Context) {
 		{"key": "featurettes", "label": string(Featurettes), "value": cfg.Featurettes},
 		{"key": "deletedScenes", "label": string(DeletedScenes), "value": cfg.DeletedScenes},
 		{"key": "shorts", "label": string(Shorts), "value": cfg.Shorts},
+		{"key": "themeMusic", "label": string(ThemeMusic), "value": cfg.ThemeMusic},
 		{"key": "other", "label": string(Other), "value": cfg.Other},
 	}
 
@@ -802,15 +1086,17 @@ func EnsureSyncTimingsConfig() (map[string]int, error) {
 		"radarr":      15,
 		"sonarr":      15,
 		"extras":      360,
+		"cleanup":     1440,
+		"upgrade":     10080,
 	}
 
 	// If the file doesn't exist create it with defaults
-	if _, err := os.Stat(ConfigPath); os.IsNotExist(err) {
+	if !configFileExists() {
 		return createConfigWithTimings(defaultTimings)
 	}
 
 	// Load existing config
-	data, err := os.ReadFile(ConfigPath)
+	data, err := readConfigBytes()
 	if err != nil {
 		return defaultTimings, err
 	}
@@ -827,24 +1113,21 @@ func EnsureSyncTimingsConfig() (map[string]int, error) {
 		return defaultTimings, nil
 	}
 
-	// Ensure extras key exists
-	if _, hasExtras := timings["extras"]; !hasExtras {
-		timings["extras"] = 360
-		cfg["syncTimings"] = timings
-		out, err := yamlv3.Marshal(cfg)
-		if err == nil {
-			_ = os.WriteFile(ConfigPath, out, 0644)
+	// Ensure timings added after the first release exist (extras and healthcheck
+	// every 6 hours, cleanup daily, upgrade weekly). If missing, persist them so the default
+	// schedule is present in config.yml.
+	missing := false
+	for _, key := range []string{"extras", "healthcheck", "cleanup", "upgrade"} {
+		if _, ok := timings[key]; !ok {
+			timings[key] = defaultTimings[key]
+			missing = true
 		}
 	}
-
-	// Ensure healthcheck key exists (6 hours). If missing, persist it so the
-	// default schedule is present in config.yml.
-	if _, hasHealth := timings["healthcheck"]; !hasHealth {
-		timings["healthcheck"] = 360
+	if missing {
 		cfg["syncTimings"] = timings
 		out, err := yamlv3.Marshal(cfg)
 		if err == nil {
-			_ = os.WriteFile(ConfigPath, out, 0644)
+			_ = writeConfigBytes(out)
 		}
 	}
 
@@ -859,11 +1142,7 @@ func createConfigWithTimings(timings map[string]int) (map[string]int, error) {
 	if err != nil {
 		return timings, err
 	}
-	// Ensure parent dir exists
-	if err := os.MkdirAll(TrailarrRoot+"/config", 0775); err != nil {
-		return timings, err
-	}
-	if err := os.WriteFile(ConfigPath, out, 0644); err != nil {
+	if err := writeConfigBytes(out); err != nil {
 		return timings, err
 	}
 	return timings, nil
@@ -877,7 +1156,7 @@ func writeSyncTimingsToConfig(cfg map[string]interface{}, timings map[string]int
 	if err != nil {
 		return err
 	}
-	_ = os.WriteFile(ConfigPath, out, 0644)
+	_ = writeConfigBytes(out)
 	return nil
 }
 
@@ -915,7 +1194,7 @@ func convertTimings(timings map[string]interface{}) map[string]int {
 
 // Loads settings for a given section ("radarr" or "sonarr")
 func loadMediaSettings(section string) (MediaSettings, error) {
-	data, err := os.ReadFile(ConfigPath)
+	data, err := readConfigBytes()
 	if err != nil {
 		TrailarrLog(WARN, "Settings", "settings not found: %v", err)
 		return MediaSettings{}, fmt.Errorf("settings not found: %w", err)
@@ -953,7 +1232,12 @@ func GetPathMappings(mediaType MediaType) ([][]string, error) {
 	if mediaType == MediaTypeTV {
 		section = "sonarr"
 	}
-	data, err := os.ReadFile(ConfigPath)
+	return getSectionPathMappings(section)
+}
+
+// getSectionPathMappings reads pathMappings for any config.yml section as [][]string
+func getSectionPathMappings(section string) ([][]string, error) {
+	data, err := readConfigBytes()
 	if err != nil {
 		return nil, err
 	}
@@ -994,7 +1278,7 @@ func extractPathMappings(sec map[string]interface{}) [][]string {
 // Returns a Gin handler for settings (url, apiKey, pathMappings) for a given section ("radarr" or "sonarr")
 // Returns url and apiKey for a given section (radarr/sonarr) from config.yml
 func GetProviderUrlAndApiKey(provider string) (string, string, error) {
-	data, err := os.ReadFile(ConfigPath)
+	data, err := readConfigBytes()
 	if err != nil {
 		return "", "", err
 	}
@@ -1042,7 +1326,7 @@ func GetProviderUrlAndApiKey(provider string) (string, string, error) {
 
 func GetSettingsHandler(section string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		data, err := os.ReadFile(ConfigPath)
+		data, err := readConfigBytes()
 		if err != nil {
 			respondJSON(c, http.StatusOK, gin.H{"providerURL": "", "apiKey": ""})
 			return
@@ -1209,9 +1493,7 @@ func SaveSettingsHandler(section string) gin.HandlerFunc {
 		}
 
 		// Update in-memory config
-		if Config != nil {
-			Config[section] = sectionData
-		}
+		setLoadedConfigSection(section, sectionData)
 
 		// Trigger an immediate healthcheck task run so UI reflects new provider settings
 		triggerHealthcheckTaskAsync()
@@ -1231,7 +1513,7 @@ func triggerHealthcheckTaskAsync() {
 }
 
 func getGeneralSettingsHandler(c *gin.Context) {
-	data, err := os.ReadFile(ConfigPath)
+	data, err := readConfigBytes()
 	if err != nil {
 		respondJSON(c, http.StatusOK, gin.H{"tmdbKey": "", "autoDownloadExtras": true})
 		return
@@ -1310,7 +1592,7 @@ func saveGeneralSettingsHandler(c *gin.Context) {
 	// races where other background writers may interleave. This ensures the
 	// immediate read after this handler sees the persisted values.
 	if cfgMap, rerr := readConfigFile(); rerr == nil {
-		Config = cfgMap
+		setLoadedConfig(cfgMap)
 	}
 
 	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
@@ -1348,7 +1630,11 @@ func FetchRootFolders(apiURL, apiKey string) ([]map[string]interface{}, error) {
 }
 
 // Test connection to Radarr/Sonarr by calling /api/v3/system/status
-func testMediaConnection(providerURL, apiKey, _ string) error {
+func testMediaConnection(providerURL, apiKey, provider string) error {
+	switch provider {
+	case "plex", "jellyfin", "emby":
+		return testMediaServerConnection(provider, providerURL, apiKey)
+	}
 	endpoint := "/api/v3/system/status"
 	req, err := http.NewRequest("GET", providerURL+endpoint, nil)
 	if err != nil {
@@ -1368,7 +1654,8 @@ func testMediaConnection(providerURL, apiKey, _ string) error {
 	return nil
 }
 
-// Returns a slice of canonical extra types enabled in config
+// Returns a slice of canonical extra types enabled in config. Theme music is
+// not a video extra and is left out; check cfg.ThemeMusic for it.
 func GetEnabledCanonicalExtraTypes(cfg ExtraTypesConfig) []string {
 	types := make([]string, 0)
 	if cfg.Trailers {
@@ -11,25 +11,21 @@ func TestGetExtraTypesConfigReadsFromDiskWhenConfigNil(t *testing.T) {
 	tmp := t.TempDir()
 	// point trails root and config path to temp dir
 	oldRoot := TrailarrRoot
-	oldConfigPath := ConfigPath
-	defer func() {
-		TrailarrRoot = oldRoot
-		ConfigPath = oldConfigPath
-	}()
+	defer func() { TrailarrRoot = oldRoot }()
 	TrailarrRoot = tmp
 	// ensure config dir exists
 	cfgDir := filepath.Join(TrailarrRoot, "config")
 	if err := os.MkdirAll(cfgDir, 0755); err != nil {
 		t.Fatalf("failed to create config dir: %v", err)
 	}
-	ConfigPath = filepath.Join(cfgDir, "config.yml")
+	setConfigPathForTest(t, filepath.Join(cfgDir, "config.yml"))
 
 	// write a config file with extraTypes disabling trailers
 	content := []byte("extraTypes:\n  trailers: false\n  scenes: true\n")
 	WriteConfig(t, content)
 
 	// ensure in-memory Config is nil
-	Config = nil
+	setConfigForTest(t, nil)
 
 	cfg, err := GetExtraTypesConfig()
 	if err != nil {
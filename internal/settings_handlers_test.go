@@ -19,20 +19,16 @@ const radarrBaseURL = "http://radarr.test"
 func TestSaveSettingsHandlerWritesFileAndUpdatesConfig(t *testing.T) {
 	tmp := t.TempDir()
 	oldRoot := TrailarrRoot
-	oldConfigPath := ConfigPath
-	defer func() {
-		TrailarrRoot = oldRoot
-		ConfigPath = oldConfigPath
-	}()
+	defer func() { TrailarrRoot = oldRoot }()
 	TrailarrRoot = tmp
 	cfgDir := filepath.Join(TrailarrRoot, "config")
 	if err := os.MkdirAll(cfgDir, 0755); err != nil {
 		t.Fatalf("failed to create config dir: %v", err)
 	}
-	ConfigPath = filepath.Join(cfgDir, "config.yml")
+	setConfigPathForTest(t, filepath.Join(cfgDir, "config.yml"))
 
 	// ensure in-memory Config exists so handler updates it
-	Config = map[string]interface{}{}
+	setConfigForTest(t, map[string]interface{}{})
 
 	// prepare router
 	gin.SetMode(gin.ReleaseMode)
@@ -74,8 +70,8 @@ func TestSaveSettingsHandlerWritesFileAndUpdatesConfig(t *testing.T) {
 	}
 
 	// verify in-memory Config updated
-	if inSec, ok := Config["radarr"].(map[string]interface{}); !ok {
-		t.Fatalf("Config radarr section not updated in memory: %v", Config)
+	if inSec, ok := loadedConfig()["radarr"].(map[string]interface{}); !ok {
+		t.Fatalf("Config radarr section not updated in memory: %v", loadedConfig())
 	} else {
 		if inSec["url"] != radarrBaseURL {
 			t.Fatalf("in-memory radarr.url mismatch: %v", inSec["url"])
@@ -86,17 +82,13 @@ func TestSaveSettingsHandlerWritesFileAndUpdatesConfig(t *testing.T) {
 func TestGetSettingsHandlerMergesRootFolders(t *testing.T) {
 	tmp := t.TempDir()
 	oldRoot := TrailarrRoot
-	oldConfigPath := ConfigPath
-	defer func() {
-		TrailarrRoot = oldRoot
-		ConfigPath = oldConfigPath
-	}()
+	defer func() { TrailarrRoot = oldRoot }()
 	TrailarrRoot = tmp
 	cfgDir := filepath.Join(TrailarrRoot, "config")
 	if err := os.MkdirAll(cfgDir, 0755); err != nil {
 		t.Fatalf("failed to create config dir: %v", err)
 	}
-	ConfigPath = filepath.Join(cfgDir, "config.yml")
+	setConfigPathForTest(t, filepath.Join(cfgDir, "config.yml"))
 
 	// start test server to simulate Radarr rootfolder API
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -18,7 +18,7 @@ func TestGetYtdlpFlagsConfigReadsFromDiskWhenConfigNil(t *testing.T) {
 	} else {
 		t.Logf("failed to read back config %s: %v", ConfigPath, err)
 	}
-	Config = nil
+	setLoadedConfig(nil)
 	cfg, err := GetYtdlpFlagsConfig()
 	if err != nil {
 		t.Fatalf("GetYtdlpFlagsConfig returned error: %v", err)
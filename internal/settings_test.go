@@ -65,4 +65,8 @@ func TestGetEnabledCanonicalExtraTypes(t *testing.T) {
 	if len(got) != 1 {
 		t.Fatalf("expected single trailer type when trailers enabled, got %v", got)
 	}
+	cfg.ThemeMusic = true
+	if got = GetEnabledCanonicalExtraTypes(cfg); len(got) != 1 || got[0] == string(ThemeMusic) {
+		t.Fatalf("expected theme music left out of the video extra types, got %v", got)
+	}
 }
@@ -204,7 +204,7 @@ func getModuleVersion() string {
 }
 
 func getYtdlpVersion() string {
-	if out, err := ytDlpRunner.CombinedOutput(YtDlpCmd, []string{"--version"}, ""); err == nil {
+	if out, err := ytDlpRunner.CombinedOutput(context.Background(), YtDlpCmd, []string{"--version"}, ""); err == nil {
 		return strings.TrimSpace(string(out))
 	}
 	return ""
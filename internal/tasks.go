@@ -131,6 +131,11 @@ func init() {
 		"radarr":      {ID: "radarr", Name: "Sync with Radarr", Function: wrapWithQueue("radarr", func() error { return SyncMediaType(MediaTypeMovie) }), Order: 1},
 		"sonarr":      {ID: "sonarr", Name: "Sync with Sonarr", Function: wrapWithQueue("sonarr", func() error { return SyncMediaType(MediaTypeTV) }), Order: 2},
 		"extras":      {ID: "extras", Name: "Search for Missing Extras", Function: wrapWithQueue("extras", func() error { processExtras(context.Background()); return nil }), Order: 3},
+		"cleanup":     {ID: "cleanup", Name: "Clean Up Extras", Function: wrapWithQueue("cleanup", runExtrasCleanupTask), Order: 4},
+		"upgrade":     {ID: "upgrade", Name: "Upgrade Extras", Function: wrapWithQueue("upgrade", runExtrasUpgradeTask), Order: 5},
+		// On-demand tasks: no entry in Timings, so they are never scheduled.
+		"renameExtras": {ID: "renameExtras", Name: "Rename Extras", Function: wrapWithQueue("renameExtras", runRenameExtrasTask), Order: 6},
+		"importExtras": {ID: "importExtras", Name: "Import Extras", Function: wrapWithQueue("importExtras", runImportExtrasTask), Order: 7},
 	}
 }
 
@@ -513,8 +518,8 @@ func buildBgTasks(states TaskStates) []bgTask {
 	for id, meta := range tasksMeta {
 		intervalVal, ok := Timings[string(id)]
 		if !ok {
-			TrailarrLog(WARN, "Tasks", "No interval found in Timings for %s", id)
-			intervalVal = 0
+			TrailarrLog(DEBUG, "Tasks", "No interval found in Timings for %s; task runs on demand only", id)
+			continue
 		}
 		interval := time.Duration(intervalVal) * time.Minute
 		lastExec := states[id].LastExecution
@@ -583,6 +588,7 @@ func processExtras(ctx context.Context) {
 	}
 	TrailarrLog(INFO, "Tasks", "[TASK] Searching for missing movie extras...")
 	downloadMissingExtrasWithTypeFilter(ctx, extraTypesCfg, MediaTypeMovie, MoviesStoreKey)
+	processCollectionExtras(ctx)
 	TrailarrLog(INFO, "Tasks", "[TASK] Searching for missing series extras...")
 	downloadMissingExtrasWithTypeFilter(ctx, extraTypesCfg, MediaTypeTV, SeriesStoreKey)
 }
@@ -644,6 +650,12 @@ func downloadMissingExtrasWithTypeFilter(ctx context.Context, cfg ExtraTypesConf
 		}
 		processWantedItem(ctx, cfg, mediaType, cacheFile, item, enabledTypes)
 	}
+	if cfg.ThemeMusic {
+		processAllThemeMusic(ctx, mediaType)
+	}
+	if mediaType == MediaTypeTV {
+		processAllSeasonExtras(ctx, cfg)
+	}
 }
 
 // Helper: determine whether an item should be included in wantedItems
@@ -662,8 +674,8 @@ func shouldIncludeWantedItem(item map[string]interface{}, useWantedIndex bool, m
 			return false, mediaId
 		}
 	}
-	hasAny := HasAnyEnabledExtras(mediaType, mediaId, enabledTypes)
-	if hasAny {
+	// Theme music is searched for separately; see processAllThemeMusic
+	if HasAnyEnabledExtras(mediaType, mediaId, enabledTypes) {
 		TrailarrLog(DEBUG, "Tasks", "downloadMissingExtrasWithTypeFilter: mediaId=%d already has enabled extras, skipping", mediaId)
 		return false, mediaId
 	}
@@ -678,7 +690,7 @@ func processWantedItem(ctx context.Context, cfg ExtraTypesConfig, mediaType Medi
 
 	TrailarrLog(DEBUG, "Tasks", "processWantedItem: processing mediaType=%v mediaId=%d title=%q cache=%s enabledTypes=%v", mediaType, mediaId, title, cacheFile, enabledTypes)
 
-	extras, usedTMDB, err := fetchExtrasOrTMDB(mediaType, mediaId, title, enabledTypes)
+	extras, usedTMDB, err := fetchExtrasOrTMDB(cfg, mediaType, mediaId, title, enabledTypes)
 	if err != nil {
 		TrailarrLog(WARN, "Tasks", "SearchExtras/TMDB failed for mediaId=%v, title=%q: %v", mediaId, title, err)
 		return
@@ -711,6 +723,7 @@ func processWantedItem(ctx context.Context, cfg ExtraTypesConfig, mediaType Medi
 		MarkRejectedExtrasInMemory(extras, rejectedYoutubeIds)
 		toDownload = extras
 	}
+	toDownload = applyLanguagePreferences(mediaType, toDownload)
 	TrailarrLog(DEBUG, "Tasks", "processWantedItem: mediaId=%d toDownload count=%d usedTMDB=%v mediaPath=%s", mediaId, len(toDownload), usedTMDB, mediaPath)
 
 	// For each extra, download sequentially using a helper to reduce nesting.
@@ -719,12 +732,13 @@ func processWantedItem(ctx context.Context, cfg ExtraTypesConfig, mediaType Medi
 			TrailarrLog(INFO, "Tasks", "Extras download cancelled before processing extra.")
 			break
 		}
-		processExtraDownload(cfg, mediaType, mediaId, extra, usedTMDB)
+		_ = processExtraDownload(cfg, mediaType, mediaId, extra, usedTMDB)
 	}
 }
 
 // fetchExtrasOrTMDB centralizes SearchExtras + TMDB fallback and reduces branching in the caller.
-func fetchExtrasOrTMDB(mediaType MediaType, mediaId int, title string, enabledTypes interface{}) ([]Extra, bool, error) {
+// When TMDB has no videos either and trailers are enabled, YouTube is searched for a trailer.
+func fetchExtrasOrTMDB(cfg ExtraTypesConfig, mediaType MediaType, mediaId int, title string, enabledTypes interface{}) ([]Extra, bool, error) {
 	extras, err := SearchExtras(mediaType, mediaId)
 	if err != nil {
 		return nil, false, err
@@ -735,6 +749,10 @@ func fetchExtrasOrTMDB(mediaType MediaType, mediaId int, title string, enabledTy
 		if err != nil {
 			return nil, false, err
 		}
+		if len(tmdbExtras) == 0 && cfg.Trailers {
+			TrailarrLog(INFO, "Tasks", "No TMDB videos for mediaId=%v, title=%q, searching YouTube for a trailer...", mediaId, title)
+			tmdbExtras = searchFallbackTrailer(mediaType, mediaId)
+		}
 		if len(tmdbExtras) == 0 {
 			TrailarrLog(INFO, "Tasks", "Still no extras after TMDB fetch for mediaId=%v, title=%q", mediaId, title)
 			return nil, false, nil
@@ -745,44 +763,41 @@ func fetchExtrasOrTMDB(mediaType MediaType, mediaId int, title string, enabledTy
 }
 
 // processExtraDownload handles the per-extra checks and enqueues downloads when appropriate.
-func processExtraDownload(cfg ExtraTypesConfig, mediaType MediaType, mediaId int, extra Extra, usedTMDB bool) {
+func processExtraDownload(cfg ExtraTypesConfig, mediaType MediaType, mediaId int, extra Extra, usedTMDB bool) error {
 	typ := canonicalizeExtraType(extra.ExtraType)
 	TrailarrLog(DEBUG, "Tasks", "processExtraDownload: mediaId=%d extraType=%s status=%s youtubeId=%s usedTMDB=%v", mediaId, extra.ExtraType, extra.Status, extra.YoutubeId, usedTMDB)
 	if !isExtraTypeEnabled(cfg, typ) {
 		TrailarrLog(DEBUG, "Tasks", "processExtraDownload: extra type %s disabled by config, skipping mediaId=%d", typ, mediaId)
-		return
+		return nil
 	}
 	// Only check rejection for local extras, not TMDB-fetched
 	if !usedTMDB && extra.Status == "rejected" {
 		TrailarrLog(DEBUG, "Tasks", "processExtraDownload: extra rejected locally, skipping mediaId=%d youtubeId=%s", mediaId, extra.YoutubeId)
-		return
+		return nil
 	}
 	// For TMDB-fetched, always treat as missing if not present locally
 	if (usedTMDB && extra.YoutubeId != "") || (!usedTMDB && extra.Status == "missing" && extra.YoutubeId != "") {
 		TrailarrLog(INFO, "Tasks", "processExtraDownload: queuing extra mediaId=%d type=%s title=%q youtubeId=%s usedTMDB=%v", mediaId, extra.ExtraType, extra.ExtraTitle, extra.YoutubeId, usedTMDB)
 		if err := handleTypeFilteredExtraDownload(mediaType, mediaId, extra); err != nil {
 			TrailarrLog(WARN, "Tasks", "[SEQ] Download failed: %v", err)
+			return err
 		}
 	} else {
 		TrailarrLog(DEBUG, "Tasks", "processExtraDownload: extra does not meet download criteria for mediaId=%d youtubeId=%s status=%s usedTMDB=%v", mediaId, extra.YoutubeId, extra.Status, usedTMDB)
 	}
+	return nil
 }
 
 // Handles downloading a single extra and appending to history if successful
 func handleTypeFilteredExtraDownload(mediaType MediaType, mediaId int, extra Extra) error {
 	// Enqueue the extra for download using the queue system
-	item := DownloadQueueItem{
-		MediaType:  mediaType,
-		MediaId:    mediaId,
-		ExtraType:  extra.ExtraType,
-		ExtraTitle: extra.ExtraTitle,
-		YouTubeID:  extra.YoutubeId,
-		QueuedAt:   time.Now(),
-	}
+	item := newExtraQueueItem(mediaType, mediaId, extra)
 	// Wait for any currently queued download items to drain before enqueuing
 	// to avoid flooding the queue when many extras are discovered by the task.
 	waitForDownloadQueueDrain(mediaId, extra.YoutubeId)
-	AddToDownloadQueue(item, "task")
+	if err := AddToDownloadQueue(item, QueueSourceTask); err != nil {
+		return err
+	}
 	TrailarrLog(INFO, "QUEUE", "[handleTypeFilteredExtraDownload] Enqueued extra: mediaType=%v, mediaId=%v, type=%s, title=%s, youtubeId=%s", mediaType, mediaId, extra.ExtraType, extra.ExtraTitle, extra.YoutubeId)
 
 	// Do not record a "queued" history event here. The downloader will record
@@ -815,7 +830,8 @@ func isDownloadQueueQueuedPresent() bool {
 	for _, v := range vals {
 		var q DownloadQueueItem
 		if err := json.Unmarshal([]byte(v), &q); err == nil {
-			if q.Status == "queued" {
+			// items waiting out a retry backoff don't hold up the task
+			if q.Status == "queued" && !q.NextAttemptAt.After(time.Now()) {
 				return true
 			}
 		}
@@ -840,6 +856,8 @@ func isExtraTypeEnabled(cfg ExtraTypesConfig, typ string) bool {
 		return cfg.DeletedScenes
 	case "Other":
 		return cfg.Other
+	case string(ThemeMusic):
+		return cfg.ThemeMusic
 	default:
 		return false
 	}
@@ -18,11 +18,13 @@ func CreateTempConfig(t *testing.T) string {
 	// TestMain-created temp root. CreateTempConfig now only ensures a minimal
 	// config file exists at the package `ConfigPath` and returns the current
 	// TrailarrRoot.
+	// Index refreshes left running by earlier tests read ConfigPath
+	indexUpdates.Wait()
 	oldRoot := TrailarrRoot
-	oldConfig := ConfigPath
+	setConfigPathForTest(t, ConfigPath)
 	t.Cleanup(func() {
+		indexUpdates.Wait()
 		TrailarrRoot = oldRoot
-		ConfigPath = oldConfig
 	})
 	cfgDir := filepath.Join(TrailarrRoot, "config")
 	if err := os.MkdirAll(cfgDir, 0755); err != nil {
@@ -32,7 +34,7 @@ func CreateTempConfig(t *testing.T) string {
 	// and helpers that expect the default name operate on the same file.
 	// This reduces CI flakiness caused by other code reading/writing the
 	// canonical `config.yml` name in the same directory.
-	ConfigPath = filepath.Join(cfgDir, "config.yml")
+	setConfigPathForTest(t, filepath.Join(cfgDir, "config.yml"))
 	// Write a minimal config file so code that expects sections won't panic.
 	// Use the same defaults as production helpers.
 	minimal := map[string]interface{}{
@@ -45,6 +47,27 @@ func CreateTempConfig(t *testing.T) string {
 	return TrailarrRoot
 }
 
+// setConfigPathForTest points ConfigPath at p until the test ends. Background
+// tasks read it, so it only changes under the config lock.
+func setConfigPathForTest(t *testing.T, p string) {
+	configMu.Lock()
+	old := ConfigPath
+	ConfigPath = p
+	configMu.Unlock()
+	t.Cleanup(func() {
+		configMu.Lock()
+		ConfigPath = old
+		configMu.Unlock()
+	})
+}
+
+// setConfigForTest replaces the in-memory config until the test ends.
+func setConfigForTest(t *testing.T, cfg map[string]interface{}) {
+	old := loadedConfig()
+	setLoadedConfig(cfg)
+	t.Cleanup(func() { setLoadedConfig(old) })
+}
+
 // WriteConfig writes content to the current ConfigPath.
 func WriteConfig(t *testing.T, content []byte) {
 	// Write atomically to avoid races where other goroutines/readers may
@@ -100,3 +123,14 @@ func DoRequest(r http.Handler, method, path string, body []byte) *httptest.Respo
 	r.ServeHTTP(w, req)
 	return w
 }
+
+// flushLibraryRefreshForTest sends the pending media server refreshes now
+// instead of from the debounce timer, which would outlive the test.
+func flushLibraryRefreshForTest() {
+	libraryRefresh.mu.Lock()
+	if libraryRefresh.timer != nil {
+		libraryRefresh.timer.Stop()
+	}
+	libraryRefresh.mu.Unlock()
+	libraryRefresh.flush()
+}
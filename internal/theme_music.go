@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// themeMusicFile is the file media servers play as the theme of a movie or series.
+const themeMusicFile = "theme.mp3"
+
+// searchKindTheme is the kind of search misses of theme music searches.
+const searchKindTheme = "theme"
+
+// themeMusicKeywordPenalties lower the score of results that are rarely the
+// original theme. Keywords are matched like searchKeywordPenalties.
+var themeMusicKeywordPenalties = []struct {
+	Keyword string
+	Penalty int
+}{
+	{"cover", 30},
+	{"remix", 30},
+	{"piano", 20},
+	{"1 hour", 20},
+	{"trailer", 20},
+}
+
+// isThemeMusic reports whether extraType is the theme music type.
+func isThemeMusic(extraType string) bool {
+	return strings.EqualFold(canonicalizeExtraType(extraType), string(ThemeMusic))
+}
+
+// hasThemeMusic reports whether the media folder holds theme music.
+func hasThemeMusic(mediaPath string) bool {
+	if mediaPath == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(mediaPath, themeMusicFile))
+	return err == nil
+}
+
+// useThemeMusicOutput makes a download extract the audio to theme.mp3 in
+// the media folder instead of saving a video in an extras folder.
+func useThemeMusicOutput(info *downloadInfo) {
+	info.AudioOnly = true
+	info.OutDir = info.MediaPath
+	info.OutFile = filepath.Join(info.MediaPath, themeMusicFile)
+	info.TempFile = filepath.Join(info.TempDir, themeMusicFile)
+}
+
+// audioOnlyYtDlpArgs makes yt-dlp download the best audio and convert it
+// to mp3 at info.TempFile.
+func audioOnlyYtDlpArgs(info *downloadInfo) []string {
+	return []string{
+		"--format", "bestaudio/best",
+		"--extract-audio",
+		"--audio-format", "mp3",
+		"--audio-quality", "0",
+		"--output", strings.TrimSuffix(info.TempFile, filepath.Ext(info.TempFile)) + ".%(ext)s",
+	}
+}
+
+// scoreThemeMusicFit scores how much a result looks like the theme song.
+func scoreThemeMusicFit(score *SearchScore, title string, duration float64) {
+	for _, k := range []string{"theme", "soundtrack", "ost", "main title", "opening", "intro"} {
+		if strings.Contains(title, " "+k+" ") {
+			score.add(10, "%s in title", k)
+			break
+		}
+	}
+	if strings.Contains(title, " official ") {
+		score.add(5, "official in title")
+	}
+	switch d := duration; {
+	case d <= 0:
+	case d < 20:
+		score.add(-10, "too short (%.0fs)", d)
+	case d <= 420:
+		score.add(10, "theme length (%.0fs)", d)
+	case d > 900:
+		score.add(-20, "too long (%.0fs)", d)
+	}
+	for _, p := range themeMusicKeywordPenalties {
+		if strings.Contains(title, " "+p.Keyword+" ") {
+			score.add(-p.Penalty, "%q in title", p.Keyword)
+		}
+	}
+}
+
+// searchThemeMusic searches YouTube for the theme song or main soundtrack
+// theme of media. It returns the best confident match as a Theme Music extra,
+// or nil if there is none.
+func searchThemeMusic(mediaType MediaType, mediaId int) *Extra {
+	title, originalTitle, err := getTitlesFromCache(mediaType, mediaId)
+	if err != nil || (title == "" && originalTitle == "") {
+		TrailarrLog(DEBUG, "Tasks", "No title for theme music search of %s %d: %v", mediaType, mediaId, err)
+		return nil
+	}
+	target := newSearchTarget(mediaType, mediaId, title, originalTitle)
+	target.ThemeMusic = true
+	results, _ := searchYtDlpForTerms(searchFallbackTerms(target), searchFallbackResults, target)
+
+	rejected := map[string]struct{}{}
+	for _, r := range GetRejectedExtrasForMedia(mediaType, mediaId) {
+		rejected[r.YoutubeId] = struct{}{}
+	}
+	videoId, score := bestSearchFallback(results, rejected)
+	if videoId == "" {
+		TrailarrLog(INFO, "Tasks", "Found no confident theme music for %s %d (%q) in %d results", mediaType, mediaId, title, len(results))
+		recordSearchMiss(searchKindTheme, mediaType, mediaId)
+		return nil
+	}
+	clearSearchMiss(searchKindTheme, mediaType, mediaId)
+	TrailarrLog(INFO, "Tasks", "Picked %s (score %d) as theme music for %s %d (%q)", videoId, score, mediaType, mediaId, title)
+	return &Extra{
+		ExtraType:  string(ThemeMusic),
+		ExtraTitle: "Theme",
+		YoutubeId:  videoId,
+		Status:     "missing",
+		Source:     ExtraSourceSearch,
+	}
+}
+
+// processAllThemeMusic queues theme music for the media of a library that
+// has none. Media whose last search found nothing is skipped until the
+// search backoff has passed.
+func processAllThemeMusic(ctx context.Context, mediaType MediaType) {
+	cacheFile, _ := resolveCachePath(mediaType)
+	items, err := loadCache(cacheFile)
+	if err != nil {
+		TrailarrLog(WARN, "Tasks", "processAllThemeMusic: failed to load cache %s: %v", cacheFile, err)
+		return
+	}
+	mappings := getPathMappingsSafe(mediaType)
+	for _, item := range items {
+		if ctx != nil && ctx.Err() != nil {
+			return
+		}
+		mediaId, ok := parseMediaID(item["id"])
+		title, _ := item["title"].(string)
+		mediaPath, _ := item["path"].(string)
+		if mediaPath != "" {
+			mediaPath = applyPathMappings(mediaPath, mappings)
+		}
+		mediaPath = deriveBasePath(mediaPath, mappings, title)
+		if !ok || mediaPath == "" || hasThemeMusic(mediaPath) || searchBackedOff(searchKindTheme, mediaType, mediaId) {
+			continue
+		}
+		extra := searchThemeMusic(mediaType, mediaId)
+		if extra == nil {
+			continue
+		}
+		if err := handleTypeFilteredExtraDownload(mediaType, mediaId, *extra); err != nil {
+			TrailarrLog(WARN, "Tasks", "Failed to queue theme music for %s %d: %v", mediaType, mediaId, err)
+		}
+	}
+}
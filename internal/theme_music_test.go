@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestThemeMusicDownloadsThemeMp3(t *testing.T) {
+	CreateTempConfig(t)
+	library := t.TempDir()
+	WriteConfig(t, []byte("radarr:\n  pathMappings:\n    - from: /movies\n      to: "+library+"\n"))
+	if err := SaveMediaToStore(MoviesStoreKey, []map[string]interface{}{{"id": 9601, "title": "Jaws", "path": "/movies/Jaws (1975)"}}); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+	ctx := context.Background()
+	holdWorkerPool(t)
+	_ = GetStoreClient().Del(ctx, DownloadQueue)
+	defer GetStoreClient().Del(ctx, DownloadQueue)
+	runner := &fakeRunner{}
+	oldRunner, oldProbe := ytDlpRunner, probeVideo
+	ytDlpRunner = runner
+	probeVideo = func(string) (*VideoInfo, error) { return nil, errors.New("theme music must not be probed as video") }
+	defer func() { ytDlpRunner, probeVideo = oldRunner, oldProbe }()
+
+	AddToDownloadQueue(DownloadQueueItem{MediaType: MediaTypeMovie, MediaId: 9601, ExtraType: string(ThemeMusic), ExtraTitle: "Theme", YouTubeID: "CCCCCCCCCCC", QueuedAt: time.Now()}, QueueSourceAPI)
+	runQueuedItemForTest(t)
+
+	if d := runner.downloadArgs(); len(d) != 1 || !slices.Contains(d[0], "--extract-audio") || slices.Contains(d[0], "--remux-video") {
+		t.Fatalf("expected an audio-only download, got %v", d)
+	}
+	theme := filepath.Join(library, "Jaws (1975)", "theme.mp3")
+	if b, err := os.ReadFile(theme); err != nil || string(b) != "dummy" {
+		t.Fatalf("expected theme.mp3 in the media folder: %q %v", b, err)
+	}
+	e, _ := GetExtraByYoutubeId(ctx, "CCCCCCCCCCC", MediaTypeMovie, 9601)
+	if e == nil || e.Status != "downloaded" || e.FileName != theme {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if !hasThemeMusic(filepath.Join(library, "Jaws (1975)")) {
+		t.Fatalf("expected theme music to be found after download")
+	}
+}
+
+func TestWantedConsidersThemeMusic(t *testing.T) {
+	CreateTempConfig(t)
+	withTrailer := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(withTrailer, "Trailers"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(withTrailer, "Trailers", "Trailer.mkv"), []byte("x"), 0o644)
+	withBoth := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(withBoth, "Trailers"), 0o755)
+	_ = os.WriteFile(filepath.Join(withBoth, "Trailers", "Trailer.mkv"), []byte("x"), 0o644)
+	_ = os.WriteFile(filepath.Join(withBoth, themeMusicFile), []byte("ID3"), 0o644)
+
+	wanted := func(themeMusic bool) []bool {
+		oldConfig := loadedConfig()
+		setLoadedConfig(map[string]interface{}{"extraTypes": map[string]interface{}{"trailers": true, "themeMusic": themeMusic}})
+		defer setLoadedConfig(oldConfig)
+		items := []map[string]interface{}{{"id": 1, "path": withTrailer}, {"id": 2, "path": withBoth}}
+		computeWantedIndexAndSetWants(items)
+		return []bool{isMediaWanted(items[0]), isMediaWanted(items[1])}
+	}
+	if got := wanted(false); got[0] || got[1] {
+		t.Fatalf("expected media with trailers not wanted without theme music, got %v", got)
+	}
+	if got := wanted(true); !got[0] || got[1] {
+		t.Fatalf("expected only media without theme.mp3 wanted, got %v", got)
+	}
+}
+
+func TestThemeMusicDoesNotCountAsVideoExtras(t *testing.T) {
+	CreateTempConfig(t)
+	ctx := context.Background()
+	media := t.TempDir()
+	if err := SaveMediaToStore(MoviesStoreKey, []map[string]interface{}{{"id": 9621, "title": "Psycho", "path": media}}); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+	theme := filepath.Join(media, themeMusicFile)
+	_ = os.WriteFile(theme, []byte("ID3"), 0o644)
+	if err := AddOrUpdateExtra(ctx, ExtrasEntry{MediaType: MediaTypeMovie, MediaId: 9621, ExtraType: string(ThemeMusic), ExtraTitle: "Theme", YoutubeId: "DDDDDDDDDDD", FileName: theme, Status: "downloaded"}); err != nil {
+		t.Fatalf("seed theme music: %v", err)
+	}
+
+	enabled := GetEnabledCanonicalExtraTypes(ExtraTypesConfig{Trailers: true, ThemeMusic: true})
+	if include, _ := shouldIncludeWantedItem(map[string]interface{}{"id": 9621}, true, MediaTypeMovie, enabled, MoviesStoreKey); !include {
+		t.Fatalf("expected media with only theme music still wanted for its trailers")
+	}
+}
+
+func TestThemeMusicScoring(t *testing.T) {
+	target := searchTarget{Titles: []string{"Jaws"}, Year: 1975, ThemeMusic: true}
+	if got := target.searchSuffix(); got != " soundtrack main theme" {
+		t.Fatalf("unexpected movie theme search %q", got)
+	}
+	theme := scoreSearchCandidate(searchCandidate{Title: "Jaws (1975) Main Theme - John Williams", Duration: 150}, target)
+	trailer := scoreSearchCandidate(searchCandidate{Title: "Jaws (1975) Official Trailer", Duration: 150}, target)
+	cover := scoreSearchCandidate(searchCandidate{Title: "Jaws Theme Piano Cover", Duration: 150}, target)
+	if theme.Score <= trailer.Score || theme.Score <= cover.Score {
+		t.Fatalf("expected the theme to rank first, got theme=%+v trailer=%+v cover=%+v", theme, trailer, cover)
+	}
+}
+
+func TestThemeMusicSearchSkipsPresentAndBacksOff(t *testing.T) {
+	CreateTempConfig(t)
+	library := t.TempDir()
+	WriteConfig(t, []byte("radarr:\n  pathMappings:\n    - from: /movies\n      to: "+library+"\n"))
+	_ = GetStoreClient().Del(context.Background(), SearchMissesStoreKey)
+	_ = os.MkdirAll(filepath.Join(library, "Jaws (1975)"), 0o755)
+	_ = os.WriteFile(filepath.Join(library, "Jaws (1975)", themeMusicFile), []byte("ID3"), 0o644)
+	movies := []map[string]interface{}{
+		{"id": 9611, "title": "Jaws", "year": 1975, "path": "/movies/Jaws (1975)"},
+		{"id": 9612, "title": "Lost Film", "year": 1931, "path": "/movies/Lost Film (1931)"},
+	}
+	if err := SaveMediaToStore(MoviesStoreKey, movies); err != nil {
+		t.Fatalf("save movies: %v", err)
+	}
+	runner := &fakeRunner{searchLines: []string{`{"id":"weak1","title":"Unrelated video","duration":4000}`}}
+	oldRunner := ytDlpRunner
+	ytDlpRunner = runner
+	defer func() { ytDlpRunner = oldRunner }()
+
+	processAllThemeMusic(context.Background(), MediaTypeMovie)
+	searches := runner.searchCount()
+	if searches == 0 || searchBackedOff(searchKindTheme, MediaTypeMovie, 9611) || !searchBackedOff(searchKindTheme, MediaTypeMovie, 9612) {
+		t.Fatalf("expected only the movie without theme.mp3 searched and backed off, got %d searches", searches)
+	}
+	processAllThemeMusic(context.Background(), MediaTypeMovie)
+	if n := runner.searchCount() - searches; n != 0 {
+		t.Fatalf("expected no theme searches while backed off, got %d", n)
+	}
+}
@@ -69,11 +69,12 @@ var TMDBExtraTypes = []string{
 }
 
 func GetTMDBKey() (string, error) {
-	if Config == nil {
+	config := loadedConfig()
+	if config == nil {
 		TrailarrLog(WARN, "TMDB", "Config not loaded")
 		return "", fmt.Errorf("config not loaded")
 	}
-	general, ok := Config["general"].(map[string]interface{})
+	general, ok := config["general"].(map[string]interface{})
 	if !ok {
 		TrailarrLog(WARN, "TMDB", "general section missing in config")
 		return "", fmt.Errorf("general section missing in config")
@@ -126,9 +127,27 @@ func getCachedTMDBId(cachePath string, mediaId int) (int, error) {
 	return 0, ErrTMDBNotFound
 }
 
-func FetchTMDBExtras(mediaType MediaType, tmdbId int, tmdbKey string) ([]Extra, error) {
+// FetchTMDBExtras fetches the YouTube videos TMDB lists for a movie or series.
+// With language preferences, TMDB is asked for videos in those languages and
+// the result is ranked by preference.
+func FetchTMDBExtras(mediaType MediaType, tmdbId int, tmdbKey string, languages []string) ([]Extra, error) {
 	videosURL := fmt.Sprintf("https://api.themoviedb.org/3/%s/%d/videos?api_key=%s", mediaType, tmdbId, tmdbKey)
-	resp, err := http.Get(videosURL)
+	return fetchTMDBVideos(videosURL, languages)
+}
+
+// FetchTMDBSeasonExtras fetches the YouTube videos TMDB lists for one season of a series.
+func FetchTMDBSeasonExtras(tmdbId, season int, tmdbKey string, languages []string) ([]Extra, error) {
+	videosURL := fmt.Sprintf("https://api.themoviedb.org/3/tv/%d/season/%d/videos?api_key=%s", tmdbId, season, tmdbKey)
+	extras, err := fetchTMDBVideos(videosURL, languages)
+	for i := range extras {
+		extras[i].Season = season
+	}
+	return extras, err
+}
+
+// fetchTMDBVideos reads a TMDB videos list, keeping the videos on YouTube.
+func fetchTMDBVideos(videosURL string, languages []string) ([]Extra, error) {
+	resp, err := http.Get(videosURL + tmdbVideosLanguageQuery(languages))
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +163,9 @@ func FetchTMDBExtras(mediaType MediaType, tmdbId int, tmdbKey string) ([]Extra,
 			Key  string `json:"key"`
 			Site string `json:"site"`
 			Type string `json:"type"`
+			// Language and region of the video, eg. es and MX
+			Iso639  string `json:"iso_639_1"`
+			Iso3166 string `json:"iso_3166_1"`
 		} `json:"results"`
 	}
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -157,8 +179,10 @@ func FetchTMDBExtras(mediaType MediaType, tmdbId int, tmdbKey string) ([]Extra,
 				ExtraType:  r.Type,
 				ExtraTitle: r.Name,
 				YoutubeId:  r.Key,
+				Language:   tmdbLanguageTag(r.Iso639, r.Iso3166),
 			})
 		}
 	}
+	rankExtrasByLanguage(extras, languages)
 	return extras, nil
 }
@@ -0,0 +1,196 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FfprobeCmd is the ffprobe binary used to inspect downloaded videos.
+var FfprobeCmd = "ffprobe"
+
+// probeVideo inspects a video file. It returns nil, nil when ffprobe is not
+// installed so downloads still work without it. Tests override it.
+var probeVideo = runFfprobe
+
+// VideoInfo is what ffprobe reports about a downloaded extra.
+type VideoInfo struct {
+	Duration   float64 `json:"duration"` // seconds
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	VideoCodec string  `json:"videoCodec,omitempty"`
+	AudioCodec string  `json:"audioCodec,omitempty"`
+	Bitrate    int64   `json:"bitrate,omitempty"` // bits per second
+}
+
+// VideoValidationConfig holds the rules a downloaded video must pass before it
+// is moved into the library. Zero values disable a rule.
+type VideoValidationConfig struct {
+	Enabled      bool `yaml:"enabled" json:"enabled"`
+	RequireVideo bool `yaml:"requireVideo" json:"requireVideo"`
+	MinHeight    int  `yaml:"minHeight" json:"minHeight"`
+	MinDuration  int  `yaml:"minDuration" json:"minDuration"` // seconds
+	MaxDuration  int  `yaml:"maxDuration" json:"maxDuration"` // seconds
+}
+
+func DefaultVideoValidationConfig() VideoValidationConfig {
+	return VideoValidationConfig{
+		Enabled:      true,
+		RequireVideo: true,
+		MinHeight:    0,
+		MinDuration:  0,
+		MaxDuration:  0,
+	}
+}
+
+// GetVideoValidationConfig loads the videoValidation config from config.yml
+func GetVideoValidationConfig() (VideoValidationConfig, error) {
+	cfg := DefaultVideoValidationConfig()
+	if err := decodeConfigSection("videoValidation", &cfg); err != nil {
+		return DefaultVideoValidationConfig(), err
+	}
+	return cfg, nil
+}
+
+// SaveVideoValidationConfig saves the videoValidation config to config.yml
+func SaveVideoValidationConfig(cfg VideoValidationConfig) error {
+	return saveConfigSection("videoValidation", cfg)
+}
+
+func ensureVideoValidationDefaults(config map[string]interface{}) bool {
+	if _, ok := config["videoValidation"].(map[string]interface{}); ok {
+		return false
+	}
+	config["videoValidation"] = DefaultVideoValidationConfig()
+	return true
+}
+
+// Handler to get video validation config
+func GetVideoValidationConfigHandler(c *gin.Context) {
+	cfg, _ := GetVideoValidationConfig()
+	respondJSON(c, http.StatusOK, cfg)
+}
+
+// Handler to save video validation config
+func SaveVideoValidationConfigHandler(c *gin.Context) {
+	var req VideoValidationConfig
+	if err := c.BindJSON(&req); err != nil || req.MinHeight < 0 || req.MinDuration < 0 || req.MaxDuration < 0 {
+		respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	if req.MaxDuration > 0 && req.MinDuration > req.MaxDuration {
+		respondError(c, http.StatusBadRequest, "minDuration must not exceed maxDuration")
+		return
+	}
+	if err := SaveVideoValidationConfig(req); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "saved"})
+}
+
+// VideoValidationError is returned when a downloaded file fails the validation rules.
+type VideoValidationError struct {
+	Reason string
+}
+
+func (e *VideoValidationError) Error() string {
+	return "video validation failed: " + e.Reason
+}
+
+// validateVideo checks a probed video against the configured rules.
+func validateVideo(v *VideoInfo, cfg VideoValidationConfig) error {
+	if cfg.RequireVideo && (v.VideoCodec == "" || v.Height == 0) {
+		return &VideoValidationError{Reason: "file has no video stream"}
+	}
+	if cfg.MinHeight > 0 && v.Height < cfg.MinHeight {
+		return &VideoValidationError{Reason: fmt.Sprintf("height %dp is below the minimum of %dp", v.Height, cfg.MinHeight)}
+	}
+	if cfg.MinDuration > 0 && v.Duration < float64(cfg.MinDuration) {
+		return &VideoValidationError{Reason: fmt.Sprintf("duration %.0fs is shorter than the minimum of %ds", v.Duration, cfg.MinDuration)}
+	}
+	if cfg.MaxDuration > 0 && v.Duration > float64(cfg.MaxDuration) {
+		return &VideoValidationError{Reason: fmt.Sprintf("duration %.0fs is longer than the maximum of %ds", v.Duration, cfg.MaxDuration)}
+	}
+	return nil
+}
+
+// probeAndValidateDownload probes the downloaded temp file, records what it
+// found on info and applies the validation rules. Files ffprobe can't read
+// (eg. truncated downloads) fail validation.
+func probeAndValidateDownload(info *downloadInfo) error {
+	cfg, _ := GetVideoValidationConfig()
+	v, err := probeVideo(info.TempFile)
+	if err != nil {
+		if !cfg.Enabled {
+			TrailarrLog(WARN, "YouTube", "ffprobe failed for %s: %v", info.TempFile, err)
+			return nil
+		}
+		return &VideoValidationError{Reason: "ffprobe could not read the file: " + err.Error()}
+	}
+	if v == nil {
+		return nil
+	}
+	info.Video = v
+	TrailarrLog(DEBUG, "YouTube", "Probed %s: %dx%d %s/%s %.0fs %d bps", info.YouTubeID, v.Width, v.Height, v.VideoCodec, v.AudioCodec, v.Duration, v.Bitrate)
+	if !cfg.Enabled {
+		return nil
+	}
+	return validateVideo(v, cfg)
+}
+
+// runFfprobe runs ffprobe on file and parses its JSON output.
+func runFfprobe(file string) (*VideoInfo, error) {
+	if _, err := exec.LookPath(FfprobeCmd); err != nil {
+		TrailarrLog(DEBUG, "YouTube", "ffprobe not found; skipping video validation")
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, FfprobeCmd, "-v", "error", "-print_format", "json", "-show_format", "-show_streams", file).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseFfprobeOutput(out)
+}
+
+// parseFfprobeOutput reads `ffprobe -print_format json -show_format -show_streams` output.
+func parseFfprobeOutput(out []byte) (*VideoInfo, error) {
+	var probe struct {
+		Format struct {
+			Duration string `json:"duration"`
+			BitRate  string `json:"bit_rate"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			CodecName string `json:"codec_name"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+			// Cover art in audio files shows up as a video stream
+			Disposition struct {
+				AttachedPic int `json:"attached_pic"`
+			} `json:"disposition"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, err
+	}
+	v := &VideoInfo{}
+	v.Duration, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+	v.Bitrate, _ = strconv.ParseInt(probe.Format.BitRate, 10, 64)
+	for _, s := range probe.Streams {
+		switch {
+		case s.CodecType == "video" && s.Disposition.AttachedPic == 0 && v.VideoCodec == "":
+			v.VideoCodec, v.Width, v.Height = s.CodecName, s.Width, s.Height
+		case s.CodecType == "audio" && v.AudioCodec == "":
+			v.AudioCodec = s.CodecName
+		}
+	}
+	return v, nil
+}
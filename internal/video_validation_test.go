@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestParseFfprobeOutput(t *testing.T) {
+	out := []byte(`{
+		"streams": [
+			{"codec_type": "video", "codec_name": "mjpeg", "width": 320, "height": 180, "disposition": {"attached_pic": 1}},
+			{"codec_type": "audio", "codec_name": "opus"},
+			{"codec_type": "video", "codec_name": "vp9", "width": 1920, "height": 1080, "disposition": {"attached_pic": 0}}
+		],
+		"format": {"duration": "142.35", "bit_rate": "2500000"}
+	}`)
+	v, err := parseFfprobeOutput(out)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	want := VideoInfo{Duration: 142.35, Width: 1920, Height: 1080, VideoCodec: "vp9", AudioCodec: "opus", Bitrate: 2500000}
+	if *v != want {
+		t.Fatalf("unexpected probe result: %+v", *v)
+	}
+}
+
+func TestValidateVideoRules(t *testing.T) {
+	cfg := VideoValidationConfig{Enabled: true, RequireVideo: true, MinHeight: 720, MinDuration: 10, MaxDuration: 600}
+	ok := &VideoInfo{Duration: 120, Height: 1080, VideoCodec: "vp9"}
+	if err := validateVideo(ok, cfg); err != nil {
+		t.Fatalf("expected valid video, got %v", err)
+	}
+	for name, v := range map[string]*VideoInfo{
+		"audio only": {Duration: 120, AudioCodec: "opus"},
+		"low res":    {Duration: 120, Height: 360, VideoCodec: "h264"},
+		"too short":  {Duration: 3, Height: 1080, VideoCodec: "vp9"},
+		"too long":   {Duration: 3600, Height: 1080, VideoCodec: "vp9"},
+	} {
+		var vErr *VideoValidationError
+		if err := validateVideo(v, cfg); !errors.As(err, &vErr) {
+			t.Fatalf("%s: expected validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestPerformDownloadRejectsInvalidVideo(t *testing.T) {
+	CreateTempConfig(t)
+	oldRunner, oldProbe := ytDlpRunner, probeVideo
+	ytDlpRunner = &fakeRunner{}
+	probeVideo = func(string) (*VideoInfo, error) {
+		// audio only: fails the default rules
+		return &VideoInfo{Duration: 90, AudioCodec: "opus"}, nil
+	}
+	defer func() { ytDlpRunner, probeVideo = oldRunner, oldProbe }()
+	ctx := context.Background()
+	_ = GetStoreClient().Del(ctx, ExtrasStoreKey)
+
+	info, err := prepareDownloadInfo("movie", 1, "Trailer", "No Video", testYtID)
+	if err != nil {
+		t.Fatalf("prepareDownloadInfo failed: %v", err)
+	}
+	_, err = performDownload(ctx, info, testYtID)
+	var vErr *VideoValidationError
+	if !errors.As(err, &vErr) || isTransientDownloadError(err) {
+		t.Fatalf("expected permanent validation error, got %v", err)
+	}
+	if _, statErr := os.Stat(info.OutFile); !os.IsNotExist(statErr) {
+		t.Fatalf("rejected video must not be moved into the library")
+	}
+	entry, _ := GetExtraByYoutubeId(ctx, testYtID, MediaTypeMovie, 1)
+	if entry == nil || entry.Status != "rejected" || entry.Reason != err.Error() {
+		t.Fatalf("expected extra rejected with reason, got %+v", entry)
+	}
+}
+
+func TestPerformDownloadRecordsProbedVideo(t *testing.T) {
+	CreateTempConfig(t)
+	oldRunner, oldProbe := ytDlpRunner, probeVideo
+	ytDlpRunner = &fakeRunner{}
+	probed := &VideoInfo{Duration: 150, Width: 1920, Height: 1080, VideoCodec: "vp9", AudioCodec: "opus"}
+	probeVideo = func(string) (*VideoInfo, error) { return probed, nil }
+	defer func() { ytDlpRunner, probeVideo = oldRunner, oldProbe }()
+	ctx := context.Background()
+	_ = GetStoreClient().Del(ctx, ExtrasStoreKey)
+
+	info, err := prepareDownloadInfo("movie", 1, "Trailer", "Full HD", testYtID)
+	if err != nil {
+		t.Fatalf("prepareDownloadInfo failed: %v", err)
+	}
+	meta, err := performDownload(ctx, info, testYtID)
+	if err != nil {
+		t.Fatalf("performDownload failed: %v", err)
+	}
+	if meta.Video == nil || *meta.Video != *probed {
+		t.Fatalf("expected probed video in metadata, got %+v", meta.Video)
+	}
+	entry, _ := GetExtraByYoutubeId(ctx, testYtID, MediaTypeMovie, 1)
+	if entry == nil || entry.Video == nil || entry.Video.Height != 1080 {
+		t.Fatalf("expected probed video on store entry, got %+v", entry)
+	}
+}
+
+func TestSaveVideoValidationConfigHandlerRejectsInvalid(t *testing.T) {
+	CreateTempConfig(t)
+	r := NewTestRouter()
+	r.POST("/api/settings/videovalidation", SaveVideoValidationConfigHandler)
+	for _, body := range []string{`{"minHeight":-1}`, `{"minDuration":60,"maxDuration":30}`} {
+		if w := DoRequest(r, "POST", "/api/settings/videovalidation", []byte(body)); w.Code != 400 {
+			t.Fatalf("expected 400 for %s, got %d", body, w.Code)
+		}
+	}
+	w := DoRequest(r, "POST", "/api/settings/videovalidation", []byte(`{"enabled":true,"minHeight":480,"maxDuration":900}`))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	if cfg, _ := GetVideoValidationConfig(); cfg.MinHeight != 480 || cfg.MaxDuration != 900 {
+		t.Fatalf("unexpected saved config: %+v", cfg)
+	}
+}
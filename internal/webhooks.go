@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// arrWebhookMedia is the movie/series object embedded in Radarr/Sonarr webhook payloads.
+// Only the id is used; the full item is fetched from the provider API.
+type arrWebhookMedia struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+// arrWebhookPayload is the subset of the native Radarr/Sonarr webhook payload we use.
+type arrWebhookPayload struct {
+	EventType string           `json:"eventType"`
+	Movie     *arrWebhookMedia `json:"movie"`
+	Series    *arrWebhookMedia `json:"series"`
+}
+
+// triggerNewMediaExtras starts the extras search for a newly imported item.
+// Tests may override it.
+var triggerNewMediaExtras = func(mediaType MediaType, mediaID int) {
+	cfg, _ := GetExtraTypesConfig()
+	go processNewMediaExtras(mediaType, mediaID, cfg)
+}
+
+// ArrWebhookHandler receives Radarr (mediaType movie) or Sonarr (mediaType tv)
+// webhooks. Import events refresh the cached item and immediately search for
+// extras instead of waiting for the next sync; deletes drop it from the cache.
+func ArrWebhookHandler(mediaType MediaType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !webhookSecretValid(c) {
+			respondError(c, http.StatusUnauthorized, "invalid webhook secret")
+			return
+		}
+		var payload arrWebhookPayload
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			respondError(c, http.StatusBadRequest, ErrInvalidRequest)
+			return
+		}
+		if payload.EventType == "Test" {
+			respondJSON(c, http.StatusOK, gin.H{"status": "ok", "event": payload.EventType})
+			return
+		}
+		media := payload.Movie
+		if mediaType == MediaTypeTV {
+			media = payload.Series
+		}
+		if media == nil || media.ID == 0 {
+			respondError(c, http.StatusBadRequest, "payload has no media id")
+			return
+		}
+		TrailarrLog(INFO, "Webhook", "Received %s event for %s id=%d title=%q", payload.EventType, mediaType, media.ID, media.Title)
+
+		handleArrWebhookEvent(c, mediaType, payload.EventType, media.ID)
+	}
+}
+
+// handleArrWebhookEvent applies a single webhook event to the cached item and responds.
+func handleArrWebhookEvent(c *gin.Context, mediaType MediaType, event string, id int) {
+	src, err := mediaSourceFor(mediaType)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch event {
+	case "Download", "MovieAdded", "SeriesAdd", "Rename":
+		kept, err := refreshCachedMedia(src, id)
+		if err != nil {
+			TrailarrLog(WARN, "Webhook", "Failed to refresh %s id=%d: %v", mediaType, id, err)
+			respondError(c, http.StatusBadGateway, err.Error())
+			return
+		}
+		// Renames only move files; extras are searched when media is imported or added
+		triggered := kept && event != "Rename"
+		if triggered {
+			triggerNewMediaExtras(mediaType, id)
+		}
+		respondJSON(c, http.StatusOK, gin.H{"status": "ok", "event": event, "id": id, "extrasTriggered": triggered})
+	case "MovieDelete", "SeriesDelete":
+		if err := removeCachedMedia(src, id); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respondJSON(c, http.StatusOK, gin.H{"status": "ok", "event": event, "id": id})
+	default:
+		respondJSON(c, http.StatusOK, gin.H{"status": "ignored", "event": event})
+	}
+}
+
+// webhookSecretValid checks the shared secret from the query, header or basic auth password.
+func webhookSecretValid(c *gin.Context) bool {
+	cfg, err := GetWebhookConfig()
+	if err != nil || cfg.Secret == "" {
+		return false
+	}
+	got := c.Query("secret")
+	if got == "" {
+		got = c.GetHeader(HeaderWebhookSecret)
+	}
+	if got == "" {
+		_, got, _ = c.Request.BasicAuth()
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(cfg.Secret)) == 1
+}
+
+// refreshCachedMedia fetches a single item from the provider and upserts it in the
+// cache. Items the sync filter would skip (eg. movies without a file) are removed.
+// It reports whether the item is now in the cache.
+func refreshCachedMedia(src mediaSource, id int) (bool, error) {
+	var item map[string]interface{}
+	if err := fetchProviderJSON(src.Provider, fmt.Sprintf("%s/%d", src.APIPath, id), &item); err != nil {
+		return false, err
+	}
+	keep := src.Filter == nil || src.Filter(item)
+	if err := updateCachedMedia(src.CacheFile, id, item, keep); err != nil {
+		return false, err
+	}
+	if keep {
+		CacheMediaPosters(src.Provider, src.PosterDir, []map[string]interface{}{item}, "id", src.PosterSuffixes, false)
+	}
+	if err := updateWantedStatusInStore(src.CacheFile); err != nil {
+		TrailarrLog(WARN, "Webhook", "updateWantedStatusInStore failed for %s: %v", src.CacheFile, err)
+	}
+	return keep, nil
+}
+
+// removeCachedMedia drops an item from the cache.
+func removeCachedMedia(src mediaSource, id int) error {
+	if err := updateCachedMedia(src.CacheFile, id, nil, false); err != nil {
+		return err
+	}
+	if err := updateWantedStatusInStore(src.CacheFile); err != nil {
+		TrailarrLog(WARN, "Webhook", "updateWantedStatusInStore failed for %s: %v", src.CacheFile, err)
+	}
+	return nil
+}
+
+// updateCachedMedia replaces the cached item with the given id by item, appending
+// it if missing, or removes it when keep is false.
+func updateCachedMedia(cacheFile string, id int, item map[string]interface{}, keep bool) error {
+	mediaCacheMu.Lock()
+	defer mediaCacheMu.Unlock()
+	items, err := LoadMediaFromStore(cacheFile)
+	if err != nil {
+		return err
+	}
+	updated := make([]map[string]interface{}, 0, len(items)+1)
+	found := false
+	for _, it := range items {
+		if itID, ok := getMediaID(it); ok && itID == id {
+			if keep && !found {
+				updated = append(updated, item)
+			}
+			found = true
+			continue
+		}
+		updated = append(updated, it)
+	}
+	if keep && !found {
+		updated = append(updated, item)
+	}
+	return SaveMediaToStore(cacheFile, updated)
+}
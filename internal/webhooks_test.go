@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func setupWebhookTest(t *testing.T, movie map[string]interface{}) *httptest.Server {
+	t.Helper()
+	CreateTempConfig(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/movie/42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(movie)
+	}))
+	t.Cleanup(srv.Close)
+	cfg := map[string]interface{}{
+		"general":  DefaultGeneralConfig(),
+		"radarr":   map[string]interface{}{"url": srv.URL, "apiKey": "k"},
+		"webhooks": map[string]interface{}{"secret": "s3cret"},
+	}
+	data, _ := json.Marshal(cfg)
+	WriteConfig(t, data)
+	if err := SaveMediaToStore(MoviesStoreKey, []map[string]interface{}{{"id": 1, "title": "Other", "hasFile": true}}); err != nil {
+		t.Fatalf("seed cache: %v", err)
+	}
+	return srv
+}
+
+func cachedMovieIDs(t *testing.T) map[int]string {
+	t.Helper()
+	items, err := LoadMediaFromStore(MoviesStoreKey)
+	if err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+	ids := map[int]string{}
+	for _, it := range items {
+		if id, ok := getMediaID(it); ok {
+			ids[id], _ = it["title"].(string)
+		}
+	}
+	return ids
+}
+
+func TestRadarrWebhookRequiresSecret(t *testing.T) {
+	setupWebhookTest(t, nil)
+	r := NewTestRouter()
+	r.POST("/api/webhook/radarr", ArrWebhookHandler(MediaTypeMovie))
+
+	body := []byte(`{"eventType":"Test"}`)
+	if w := DoRequest(r, "POST", "/api/webhook/radarr", body); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without secret, got %d", w.Code)
+	}
+	if w := DoRequest(r, "POST", "/api/webhook/radarr?secret=wrong", body); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with wrong secret, got %d", w.Code)
+	}
+	if w := DoRequest(r, "POST", "/api/webhook/radarr?secret=s3cret", body); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for test event, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("POST", "/api/webhook/radarr", bytes.NewReader(body))
+	req.SetBasicAuth("radarr", "s3cret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected basic auth password accepted as secret, got %d", w.Code)
+	}
+}
+
+func TestRadarrWebhookDownloadUpdatesCacheAndTriggersExtras(t *testing.T) {
+	setupWebhookTest(t, map[string]interface{}{"id": 42, "title": "Imported", "hasFile": true, "path": t.TempDir()})
+	r := NewTestRouter()
+	r.POST("/api/webhook/radarr", ArrWebhookHandler(MediaTypeMovie))
+
+	var triggered []int
+	old := triggerNewMediaExtras
+	triggerNewMediaExtras = func(mediaType MediaType, mediaID int) { triggered = append(triggered, mediaID) }
+	defer func() { triggerNewMediaExtras = old }()
+
+	w := DoRequest(r, "POST", "/api/webhook/radarr?secret=s3cret", []byte(`{"eventType":"Download","movie":{"id":42,"title":"Imported"}}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+	if ids := cachedMovieIDs(t); ids[42] != "Imported" || ids[1] != "Other" {
+		t.Fatalf("expected imported movie added to cache, got %v", ids)
+	}
+	if len(triggered) != 1 || triggered[0] != 42 {
+		t.Fatalf("expected extras search for 42, got %v", triggered)
+	}
+
+	// Renames refresh the cache without searching again
+	w = DoRequest(r, "POST", "/api/webhook/radarr?secret=s3cret", []byte(`{"eventType":"Rename","movie":{"id":42}}`))
+	if w.Code != http.StatusOK || len(triggered) != 1 {
+		t.Fatalf("rename must not trigger extras: code=%d triggered=%v", w.Code, triggered)
+	}
+
+	w = DoRequest(r, "POST", "/api/webhook/radarr?secret=s3cret", []byte(`{"eventType":"MovieDelete","movie":{"id":42}}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 on delete, got %d", w.Code)
+	}
+	if ids := cachedMovieIDs(t); len(ids) != 1 || ids[1] != "Other" {
+		t.Fatalf("expected deleted movie removed from cache, got %v", ids)
+	}
+}
+
+func TestRadarrWebhookMovieAddedWithoutFileIsNotCached(t *testing.T) {
+	setupWebhookTest(t, map[string]interface{}{"id": 42, "title": "Announced", "hasFile": false})
+	r := NewTestRouter()
+	r.POST("/api/webhook/radarr", ArrWebhookHandler(MediaTypeMovie))
+
+	called := false
+	old := triggerNewMediaExtras
+	triggerNewMediaExtras = func(MediaType, int) { called = true }
+	defer func() { triggerNewMediaExtras = old }()
+
+	w := DoRequest(r, "POST", "/api/webhook/radarr?secret=s3cret", []byte(`{"eventType":"MovieAdded","movie":{"id":42}}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if _, ok := cachedMovieIDs(t)[42]; ok || called {
+		t.Fatalf("movie without file must not be cached or searched (called=%v)", called)
+	}
+}
+
+func TestEnsureWebhookDefaultsGeneratesSecret(t *testing.T) {
+	config := map[string]interface{}{}
+	if !ensureWebhookDefaults(config) {
+		t.Fatalf("expected defaults to change config")
+	}
+	sec, _ := config["webhooks"].(map[string]interface{})
+	if s, _ := sec["secret"].(string); len(s) != 32 {
+		t.Fatalf("expected generated 32-char secret, got %v", sec)
+	}
+	if ensureWebhookDefaults(config) {
+		t.Fatalf("existing secret must be kept")
+	}
+}
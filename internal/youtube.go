@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -33,6 +34,11 @@ var (
 	// Configurable delays (can be shortened during tests)
 	QueueItemRemoveDelay = 10 * time.Second
 	QueuePollInterval    = 2 * time.Second
+	// Minimum time between progress updates sent for a running download
+	DownloadProgressInterval = 1 * time.Second
+	// Backoff between retries of transient download failures (doubles per attempt)
+	RetryBackoffBase = 30 * time.Second
+	RetryBackoffMax  = 30 * time.Minute
 
 	// Additional configurable timings used across the package. Tests can shorten these.
 	DownloadQueueWatcherInterval    = 1 * time.Second
@@ -88,6 +94,7 @@ func YouTubeTrailerSearchStreamHandler(c *gin.Context) {
 	if title != "" && originalTitle != title {
 		searchTerms = append(searchTerms, title)
 	}
+	target := newSearchTarget(MediaType(mediaType), mediaId, title, originalTitle)
 	videoIdSet := make(map[string]bool)
 	totalCount := 0
 	const maxResults = 10
@@ -96,7 +103,7 @@ func YouTubeTrailerSearchStreamHandler(c *gin.Context) {
 		if totalCount >= maxResults {
 			break
 		}
-		added, err := streamYtDlpSearchForTerm(term, maxResults-totalCount, videoIdSet, c)
+		added, err := streamYtDlpSearchForTerm(term, maxResults-totalCount, videoIdSet, target, c)
 		if err != nil {
 			TrailarrLog(ERROR, "YouTube", "yt-dlp search error for term '%s': %v", term, err)
 			// continue to next term
@@ -110,8 +117,8 @@ func YouTubeTrailerSearchStreamHandler(c *gin.Context) {
 }
 
 // streamYtDlpSearchForTerm runs a single yt-dlp search for "term trailer", streams JSON lines,
-// emits SSE events to the gin context writer for unique video IDs, and returns how many items were added.
-func streamYtDlpSearchForTerm(term string, remaining int, videoIdSet map[string]bool, c *gin.Context) (int, error) {
+// emits scored SSE events to the gin context writer for unique video IDs, and returns how many items were added.
+func streamYtDlpSearchForTerm(term string, remaining int, videoIdSet map[string]bool, target searchTarget, c *gin.Context) (int, error) {
 	if remaining <= 0 {
 		return 0, nil
 	}
@@ -148,7 +155,7 @@ func streamYtDlpSearchForTerm(term string, remaining int, videoIdSet map[string]
 		_ = cmd.Wait()
 	}()
 
-	added, err := streamYtDlpOutput(reader, cmd, remaining, videoIdSet, c)
+	added, err := streamYtDlpOutput(reader, cmd, remaining, videoIdSet, target, c)
 	if ctx.Err() == context.DeadlineExceeded {
 		TrailarrLog(ERROR, "YouTube", "[SSE] yt-dlp search timed out for query: %s", searchQuery)
 	}
@@ -158,7 +165,7 @@ func streamYtDlpSearchForTerm(term string, remaining int, videoIdSet map[string]
 // startYtDlpCommand starts the yt-dlp command with the provided args and returns a buffered reader for stdout.
 func startYtDlpCommand(ctx context.Context, cmdName string, args []string) (*bufio.Reader, *exec.Cmd, error) {
 	// Delegate to the configurable runner
-	stdout, cmd, err := ytDlpRunner.StartCommand(ctx, cmdName, args)
+	stdout, cmd, err := ytDlpRunner.StartCommand(ctx, cmdName, args, "", nil)
 	if err != nil {
 		TrailarrLog(ERROR, "YouTube", "Failed to start yt-dlp via runner: %v", err)
 		return nil, nil, err
@@ -168,11 +175,11 @@ func startYtDlpCommand(ctx context.Context, cmdName string, args []string) (*buf
 }
 
 // streamYtDlpOutput reads lines from reader and processes JSON lines until remaining items are found or reader ends.
-func streamYtDlpOutput(reader *bufio.Reader, cmd *exec.Cmd, remaining int, videoIdSet map[string]bool, c *gin.Context) (int, error) {
+func streamYtDlpOutput(reader *bufio.Reader, cmd *exec.Cmd, remaining int, videoIdSet map[string]bool, target searchTarget, c *gin.Context) (int, error) {
 	added := 0
 
 	for added < remaining {
-		done, err := readProcessLine(reader, &added, remaining, videoIdSet, c)
+		done, err := readProcessLine(reader, &added, remaining, videoIdSet, target, c)
 		if done {
 			if err != nil && err != io.EOF {
 				TrailarrLog(ERROR, "YouTube", "[SSE] Reader error: %v", err)
@@ -190,12 +197,12 @@ func streamYtDlpOutput(reader *bufio.Reader, cmd *exec.Cmd, remaining int, video
 
 // readProcessLine reads a single line from the reader, processes it and updates added; it returns
 // (true, err) when the caller should stop the loop (on error or if added reached remaining).
-func readProcessLine(reader *bufio.Reader, added *int, remaining int, videoIdSet map[string]bool, c *gin.Context) (bool, error) {
+func readProcessLine(reader *bufio.Reader, added *int, remaining int, videoIdSet map[string]bool, target searchTarget, c *gin.Context) (bool, error) {
 	line, err := reader.ReadBytes('\n')
 
 	// process any non-empty trimmed line
 	if len(bytes.TrimSpace(line)) > 0 {
-		if inc, ok := handleYtDlpJSONLine(line, videoIdSet, c); ok {
+		if inc, ok := handleYtDlpJSONLine(line, videoIdSet, target, c); ok {
 			*added += inc
 			if *added >= remaining {
 				return true, nil
@@ -210,42 +217,23 @@ func readProcessLine(reader *bufio.Reader, added *int, remaining int, videoIdSet
 	return false, nil
 }
 
-// handleYtDlpJSONLine parses a single JSON line from yt-dlp, sends an SSE event for a new ID,
-// and returns (increment, true) on success or (0, false) if nothing was emitted.
-func handleYtDlpJSONLine(line []byte, videoIdSet map[string]bool, c *gin.Context) (int, bool) {
-	var item struct {
-		ID          string `json:"id"`
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Thumbnail   string `json:"thumbnail"`
-		Channel     string `json:"channel"`
-		ChannelID   string `json:"channel_id"`
-	}
-	if err := json.Unmarshal(bytes.TrimSpace(line), &item); err != nil {
+// handleYtDlpJSONLine parses a single JSON line from yt-dlp, sends a scored SSE event for a new ID
+// from a usable channel, and returns (increment, true) on success or (0, false) if nothing was emitted.
+func handleYtDlpJSONLine(line []byte, videoIdSet map[string]bool, target searchTarget, c *gin.Context) (int, bool) {
+	item, err := parseSearchCandidate(line)
+	if err != nil {
 		// ignore unparsable lines silently for streaming
 		if len(bytes.TrimSpace(line)) > 0 {
 			TrailarrLog(DEBUG, "YouTube", "Ignored unparsable line: %s | error: %v", string(line), err)
 		}
 		return 0, false
 	}
-	if item.ID == "" || videoIdSet[item.ID] {
+	if item.ID == "" || videoIdSet[item.ID] || item.hidden(target) {
 		return 0, false
 	}
 
 	videoIdSet[item.ID] = true
-	result := gin.H{
-		"id": gin.H{"videoId": item.ID},
-		"snippet": gin.H{
-			"title":       item.Title,
-			"description": item.Description,
-			"thumbnails": gin.H{
-				"default": gin.H{"url": item.Thumbnail},
-			},
-			"channelTitle": item.Channel,
-			"channelId":    item.ChannelID,
-		},
-	}
-	b, _ := json.Marshal(result)
+	b, _ := json.Marshal(item.result(scoreSearchCandidate(item, target)))
 	fmt.Fprintf(c.Writer, "data: %s\n\n", b)
 	c.Writer.Flush()
 	return 1, true
@@ -268,6 +256,32 @@ type YtdlpFlagsConfig struct {
 	CookiesFromBrowser string  `yaml:"cookiesFromBrowser" json:"cookiesFromBrowser"`
 }
 
+// Download queue sources. The source an item is enqueued from sets its priority.
+const (
+	QueueSourceAPI      = "api"       // manual downloads from the UI/API
+	QueueSourceNewMedia = "new-media" // extras for media that just appeared in Radarr/Sonarr
+	QueueSourceTask     = "task"      // scheduled extras task backfill
+)
+
+// Download queue priorities; higher runs first.
+const (
+	QueuePriorityTask     = 0
+	QueuePriorityNewMedia = 50
+	QueuePriorityManual   = 100
+)
+
+// queuePriorityForSource maps an AddToDownloadQueue source to its priority.
+func queuePriorityForSource(source string) int {
+	switch source {
+	case QueueSourceAPI:
+		return QueuePriorityManual
+	case QueueSourceNewMedia:
+		return QueuePriorityNewMedia
+	default:
+		return QueuePriorityTask
+	}
+}
+
 // YtdlpFlagsConfig holds configuration flags for yt-dlp command-line invocations.
 // Fields mirror available CLI flags and are used to build the yt-dlp arguments.
 
@@ -279,9 +293,42 @@ type DownloadQueueItem struct {
 	ExtraType  string    `json:"extraType"`
 	ExtraTitle string    `json:"extraTitle"`
 	YouTubeID  string    `json:"youtubeId"`
+	Language   string    `json:"language,omitempty"`
+	Season     int       `json:"season,omitempty"` // set for extras of one season of a series
+	// ExtraSource is recorded as the Source of the downloaded extra
+	ExtraSource string `json:"extraSource,omitempty"`
+	// SourceURL is set for extras not on YouTube; YouTubeID then holds the source-derived ID
+	SourceURL  string    `json:"sourceUrl,omitempty"`
+	SourceSite string    `json:"sourceSite,omitempty"`
 	QueuedAt   time.Time `json:"queuedAt"`
 	Status     string    `json:"status"` // "queued", "downloading", etc.
 	Reason     string    `json:"reason,omitempty"`
+	// Priority orders queued items (higher first, then oldest QueuedAt)
+	Priority int `json:"priority"`
+	// Progress is set while the item is downloading
+	Progress *DownloadProgress `json:"progress,omitempty"`
+	// Retry bookkeeping for transient failures
+	Attempts      int       `json:"attempts,omitempty"`
+	MaxAttempts   int       `json:"maxAttempts,omitempty"`
+	NextAttemptAt time.Time `json:"nextAttemptAt,omitzero"`
+}
+
+// newExtraQueueItem builds the queue item that downloads extra for the media,
+// carrying over everything the download records about the extra.
+func newExtraQueueItem(mediaType MediaType, mediaId int, extra Extra) DownloadQueueItem {
+	return DownloadQueueItem{
+		MediaType:   mediaType,
+		MediaId:     mediaId,
+		ExtraType:   extra.ExtraType,
+		ExtraTitle:  extra.ExtraTitle,
+		YouTubeID:   extra.YoutubeId,
+		Language:    extra.Language,
+		Season:      extra.Season,
+		ExtraSource: extra.Source,
+		SourceURL:   extra.SourceURL,
+		SourceSite:  extra.SourceSite,
+		QueuedAt:    time.Now(),
+	}
 }
 
 // DownloadStatus holds the status of a download
@@ -289,6 +336,7 @@ type DownloadStatus struct {
 	Status    string // e.g. "queued", "downloading", "downloaded", "failed", "exists", "rejected"
 	UpdatedAt time.Time
 	Error     string
+	Progress  *DownloadProgress `json:",omitempty"`
 }
 
 // runtime state (declared above in the package var block)
@@ -427,9 +475,10 @@ func makeExistsInCacheFunc(movieCache, seriesCache []map[string]interface{}) fun
 	}
 }
 
-// AddToDownloadQueue adds a new download request to the queue and persists in the store
-// source: "task" (block if queue not empty), "api" (always append)
-func AddToDownloadQueue(item DownloadQueueItem, source string) {
+// AddToDownloadQueue adds a new download request to the queue and persists in the store.
+// source is one of the QueueSource* values and sets the item's priority unless
+// the caller already set one. It returns an error when the item could not be stored.
+func AddToDownloadQueue(item DownloadQueueItem, source string) error {
 	TrailarrLog(INFO, "QUEUE", "[AddToDownloadQueue] Entered. YouTubeID=%s, source=%s", item.YouTubeID, source)
 	ctx := context.Background()
 	client := GetStoreClient()
@@ -442,23 +491,33 @@ func AddToDownloadQueue(item DownloadQueueItem, source string) {
 	// immediate enqueue behavior.
 	item.Status = "queued"
 	item.QueuedAt = time.Now()
+	if item.Priority == 0 {
+		item.Priority = queuePriorityForSource(source)
+	}
+	if item.MaxAttempts <= 0 {
+		cfg, _ := GetDownloadQueueConfig()
+		item.MaxAttempts = cfg.MaxAttempts
+	}
 	b, err := json.Marshal(item)
 	TrailarrLog(INFO, "QUEUE", "[AddToDownloadQueue] Marshaled JSON: %s", string(b))
 	if err != nil {
 		TrailarrLog(ERROR, "QUEUE", "[AddToDownloadQueue] Failed to marshal item: %v", err)
-		return
+		return err
 	}
+	downloadQueueStoreMu.Lock()
 	err = client.RPush(ctx, DownloadQueue, b)
+	downloadQueueStoreMu.Unlock()
 	TrailarrLog(INFO, "QUEUE", "[AddToDownloadQueue] RPush error: %v", err)
 	if err != nil {
 		TrailarrLog(ERROR, "QUEUE", "[AddToDownloadQueue] Failed to push to store: %v", err)
-	} else {
-		TrailarrLog(INFO, "QUEUE", "[AddToDownloadQueue] Successfully enqueued item. StoreKey=%s, YouTubeID=%s", DownloadQueue, item.YouTubeID)
-		// Broadcast updated queue to all WebSocket clients
-		BroadcastDownloadQueueChanges([]DownloadQueueItem{item})
+		return err
 	}
-	downloadStatusMap[item.YouTubeID] = &DownloadStatus{Status: "queued", UpdatedAt: time.Now()}
+	TrailarrLog(INFO, "QUEUE", "[AddToDownloadQueue] Successfully enqueued item. StoreKey=%s, YouTubeID=%s", DownloadQueue, item.YouTubeID)
+	// Broadcast updated queue to all WebSocket clients
+	BroadcastDownloadQueueChanges([]DownloadQueueItem{item})
+	setDownloadStatus(item.YouTubeID, &DownloadStatus{Status: "queued", UpdatedAt: time.Now()})
 	TrailarrLog(INFO, "QUEUE", "[AddToDownloadQueue] Enqueued: mediaType=%v, mediaId=%v, extraType=%s, extraTitle=%s, youtubeId=%s, source=%s", item.MediaType, item.MediaId, item.ExtraType, item.ExtraTitle, item.YouTubeID, source)
+	return nil
 }
 
 // fillMediaTitleIfMissing attempts to populate MediaTitle on the queue item using the cache.
@@ -466,6 +525,12 @@ func fillMediaTitleIfMissing(item *DownloadQueueItem) {
 	if item.MediaTitle != "" {
 		return
 	}
+	if item.MediaType == MediaTypeCollection {
+		if col, err := findMovieCollection(item.MediaId); err == nil {
+			item.MediaTitle = col.Title
+		}
+		return
+	}
 	cacheFile, _ := resolveCachePath(item.MediaType)
 	if cacheFile == "" {
 		return
@@ -500,40 +565,64 @@ func NextQueuedItem() (int, DownloadQueueItem, bool) {
 	if err != nil {
 		return -1, DownloadQueueItem{}, false
 	}
+	return pickQueuedItem(queue, func(DownloadQueueItem) bool { return true })
+}
+
+// pickQueuedItem returns the queued entry in queue that should run next, and its
+// index: highest Priority first, then oldest QueuedAt. Items waiting out a retry
+// backoff or rejected by canStart are skipped.
+func pickQueuedItem(queue []string, canStart func(DownloadQueueItem) bool) (int, DownloadQueueItem, bool) {
+	now := time.Now()
+	bestIdx := -1
+	var best DownloadQueueItem
 	for i, qstr := range queue {
 		var item DownloadQueueItem
-		if err := json.Unmarshal([]byte(qstr), &item); err == nil {
-			if item.Status == "queued" {
-				return i, item, true
-			}
+		if err := json.Unmarshal([]byte(qstr), &item); err != nil {
+			continue
+		}
+		if item.Status != "queued" || item.NextAttemptAt.After(now) {
+			continue
+		}
+		if bestIdx >= 0 && !queuedBefore(item, best) {
+			continue
+		}
+		if canStart(item) {
+			bestIdx, best = i, item
 		}
 	}
-	return -1, DownloadQueueItem{}, false
+	return bestIdx, best, bestIdx >= 0
 }
 
-// StartDownloadQueueWorker starts a goroutine to process the download queue from the store
+// queuedBefore reports whether a should run before b.
+func queuedBefore(a, b DownloadQueueItem) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.QueuedAt.Before(b.QueuedAt)
+}
+
+// StartDownloadQueueWorker starts the download worker pool. It is safe to call
+// more than once; only the first call starts workers.
 func StartDownloadQueueWorker() {
-	go func() {
+	downloadWorkersOnce.Do(func() {
 		ctx := context.Background()
-		client := GetStoreClient()
-		// Clean the queue at startup
-		_ = client.Del(ctx, DownloadQueue)
-		for {
-			idx, item, ok := NextQueuedItem()
-			if !ok {
-				time.Sleep(2 * time.Second)
-				continue
-			}
-			if err := processQueueItem(ctx, idx, item); err != nil {
-				TrailarrLog(ERROR, "QUEUE", "[StartDownloadQueueWorker] processQueueItem error: %v", err)
-			}
+		// Keep the persisted queue across restarts; only fix up interrupted entries
+		summary, err := recoverDownloadQueue(ctx)
+		if err != nil {
+			TrailarrLog(WARN, "QUEUE", "Failed to recover download queue: %v", err)
+		} else {
+			TrailarrLog(INFO, "QUEUE", "Recovered download queue: %d queued (%d interrupted downloads re-queued), %d finished entries dropped",
+				summary.Queued, summary.Requeued, summary.Dropped)
 		}
-	}()
+		startDownloadWorkerPool(ctx)
+	})
 }
 
-// processQueueItem handles a single queue item end-to-end and returns an error only for unexpected conditions.
-func processQueueItem(ctx context.Context, idx int, item DownloadQueueItem) error {
-	client := GetStoreClient()
+// processQueueItem handles a single claimed queue item end-to-end and returns an error only for unexpected conditions.
+// ctx is cancelled when the item is cancelled through the API; store updates still run after that.
+func processQueueItem(ctx context.Context, item DownloadQueueItem) error {
+	downloadCtx := withProgressReporter(ctx, newQueueProgressReporter(context.WithoutCancel(ctx), item))
+	ctx = context.WithoutCancel(ctx)
 
 	// 1) Skip and remove rejected extras
 	if skipped, err := skipRejectedExtra(ctx, item); err != nil {
@@ -542,39 +631,46 @@ func processQueueItem(ctx context.Context, idx int, item DownloadQueueItem) erro
 		return nil
 	}
 
-	// 2) Mark as downloading
-	if err := markItemDownloading(ctx, idx, item); err != nil {
-		// log and continue attempting download even if marking failed
-		TrailarrLog(WARN, "QUEUE", "[processQueueItem] Failed to mark downloading in store: %v", err)
+	// 1b) Reject videos from blocked channels
+	if rejectBlockedChannel(ctx, item) {
+		return nil
 	}
 
-	// 3) Perform the download
-	meta, metaErr := DownloadYouTubeExtra(item.MediaType, item.MediaId, item.ExtraType, item.ExtraTitle, item.YouTubeID)
+	// 2) Perform the download (the item was marked downloading when claimed)
+	meta, metaErr := DownloadYouTubeExtraContext(downloadCtx, item)
 
-	// 4) If 429, pause the queue (handled inside)
+	// 3) If 429, pause the whole pool
 	if metaErr != nil {
 		if tooMany, ok := metaErr.(*TooManyRequestsError); ok {
 			handleTooManyRequestsPause(tooMany)
 		}
 	}
 
-	// 5) Determine final status and update in-memory map
+	// 3b) Transient failures go back in the queue with a backoff until attempts run out
+	if isTransientDownloadError(metaErr) && requeueForRetry(ctx, item, metaErr.Error()) {
+		return nil
+	}
+
+	// 4) Determine final status and update in-memory map
 	var finalStatus, failReason string
-	if metaErr != nil {
+	if errors.Is(metaErr, errDownloadCancelled) {
+		finalStatus = "cancelled"
+		setDownloadStatus(item.YouTubeID, &DownloadStatus{Status: finalStatus, UpdatedAt: time.Now()})
+	} else if metaErr != nil {
 		finalStatus = "failed"
 		failReason = metaErr.Error()
-		downloadStatusMap[item.YouTubeID] = &DownloadStatus{Status: finalStatus, UpdatedAt: time.Now(), Error: failReason}
+		setDownloadStatus(item.YouTubeID, &DownloadStatus{Status: finalStatus, UpdatedAt: time.Now(), Error: failReason})
 	} else if meta != nil {
 		finalStatus = meta.Status
-		downloadStatusMap[item.YouTubeID] = &DownloadStatus{Status: finalStatus, UpdatedAt: time.Now()}
+		setDownloadStatus(item.YouTubeID, &DownloadStatus{Status: finalStatus, UpdatedAt: time.Now()})
 	} else {
 		finalStatus = "failed"
 		failReason = "No metadata returned from download"
-		downloadStatusMap[item.YouTubeID] = &DownloadStatus{Status: finalStatus, UpdatedAt: time.Now(), Error: failReason}
+		setDownloadStatus(item.YouTubeID, &DownloadStatus{Status: finalStatus, UpdatedAt: time.Now(), Error: failReason})
 	}
 
-	// 6) Update the queue entry in the store and broadcast final status
-	if err := updateFinalStatusInStore(ctx, idx, finalStatus, failReason); err != nil {
+	// 5) Update the queue entry in the store and broadcast final status
+	if err := updateFinalStatusInStore(ctx, item, finalStatus, failReason); err != nil {
 		// If updating the store failed, still broadcast the status using the item
 		item.Status = finalStatus
 		if finalStatus == "failed" && failReason != "" {
@@ -583,10 +679,9 @@ func processQueueItem(ctx context.Context, idx int, item DownloadQueueItem) erro
 		BroadcastDownloadQueueChanges([]DownloadQueueItem{item})
 	}
 
-	// 7) Wait briefly then remove from queue (configurable for tests)
+	// 6) Wait briefly then remove from queue (configurable for tests)
 	time.Sleep(QueueItemRemoveDelay)
-	b, _ := json.Marshal(item)
-	_ = client.LRem(ctx, DownloadQueue, 1, b)
+	_ = removeDownloadQueueItem(ctx, item.YouTubeID, item.QueuedAt)
 
 	return nil
 }
@@ -600,60 +695,31 @@ func skipRejectedExtra(ctx context.Context, item DownloadQueueItem) (bool, error
 	}
 	if entry != nil && entry.Status == "rejected" {
 		TrailarrLog(WARN, "QUEUE", "[StartDownloadQueueWorker] Skipping rejected extra: mediaType=%v, mediaId=%v, extraType=%s, extraTitle=%s, youtubeId=%s", item.MediaType, item.MediaId, item.ExtraType, item.ExtraTitle, item.YouTubeID)
-		b, _ := json.Marshal(item)
 		// Remove from queue immediately
-		_ = GetStoreClient().LRem(ctx, DownloadQueue, 1, b)
+		_ = removeDownloadQueueItem(ctx, item.YouTubeID, item.QueuedAt)
 		BroadcastDownloadQueueChanges([]DownloadQueueItem{item})
 		return true, nil
 	}
 	return false, nil
 }
 
-func markItemDownloading(ctx context.Context, idx int, item DownloadQueueItem) error {
-	queue, err := GetStoreClient().LRange(ctx, DownloadQueue, 0, -1)
-	if err != nil {
-		return err
-	}
-	if idx >= 0 && idx < len(queue) {
-		var q DownloadQueueItem
-		if err := json.Unmarshal([]byte(queue[idx]), &q); err == nil {
-			q.Status = "downloading"
-			b, _ := json.Marshal(q)
-			_ = GetStoreClient().LSet(ctx, DownloadQueue, int64(idx), b)
-			downloadStatusMap[item.YouTubeID] = &DownloadStatus{Status: "downloading", UpdatedAt: time.Now()}
-			BroadcastDownloadQueueChanges([]DownloadQueueItem{q})
-		}
-	}
-	return nil
-}
-
+// handleTooManyRequestsPause pauses every download worker, not just the one that hit the 429.
 func handleTooManyRequestsPause(err429 *TooManyRequestsError) {
 	TrailarrLog(WARN, "QUEUE", "[StartDownloadQueueWorker] 429 detected, pausing queue for %v: %s", TooManyRequestsPauseDuration, err429.Error())
-	pauseUntil := time.Now().Add(TooManyRequestsPauseDuration)
-	for time.Now().Before(pauseUntil) {
-		TrailarrLog(INFO, "QUEUE", "[StartDownloadQueueWorker] Queue paused for 429. Resuming in %v seconds...", int(time.Until(pauseUntil).Seconds()))
-		time.Sleep(TooManyRequestsPauseLogInterval)
-	}
-	TrailarrLog(INFO, "QUEUE", "[StartDownloadQueueWorker] %v pause for 429 complete. Resuming queue.", TooManyRequestsPauseDuration)
+	pauseDownloadQueueUntil(time.Now().Add(TooManyRequestsPauseDuration))
 }
 
-func updateFinalStatusInStore(ctx context.Context, idx int, finalStatus, failReason string) error {
-	queue, err := GetStoreClient().LRange(ctx, DownloadQueue, 0, -1)
+func updateFinalStatusInStore(ctx context.Context, item DownloadQueueItem, finalStatus, failReason string) error {
+	q, err := updateDownloadQueueItem(ctx, item.YouTubeID, item.QueuedAt, func(q *DownloadQueueItem) {
+		q.Status = finalStatus
+		if finalStatus == "failed" && failReason != "" {
+			q.Reason = failReason
+		}
+	})
 	if err != nil {
 		return err
 	}
-	if idx >= 0 && idx < len(queue) {
-		var q DownloadQueueItem
-		if err := json.Unmarshal([]byte(queue[idx]), &q); err == nil {
-			q.Status = finalStatus
-			if finalStatus == "failed" && failReason != "" {
-				q.Reason = failReason
-			}
-			b, _ := json.Marshal(q)
-			_ = GetStoreClient().LSet(ctx, DownloadQueue, int64(idx), b)
-			BroadcastDownloadQueueChanges([]DownloadQueueItem{q})
-		}
-	}
+	BroadcastDownloadQueueChanges([]DownloadQueueItem{q})
 	return nil
 }
 
@@ -710,6 +776,12 @@ type ExtraDownloadMetadata struct {
 	YouTubeID  string
 	FileName   string
 	Status     string
+	// Filename template values only known after download, kept for renames
+	Resolution string     `json:",omitempty"`
+	Language   string     `json:",omitempty"`
+	Video      *VideoInfo `json:",omitempty"`
+	SourceURL  string     `json:",omitempty"`
+	Season     int        `json:",omitempty"`
 }
 
 // NewExtraDownloadMetadata constructs an ExtraDownloadMetadata with status and all fields
@@ -723,6 +795,11 @@ func NewExtraDownloadMetadata(info *downloadInfo, youtubeId string, status strin
 		YouTubeID:  info.YouTubeID,
 		FileName:   info.OutFile,
 		Status:     status,
+		Resolution: info.Values.Resolution,
+		Language:   info.Values.Language,
+		Video:      info.Video,
+		SourceURL:  info.SourceURL,
+		Season:     info.Season,
 	}
 }
 
@@ -733,19 +810,44 @@ type RejectedExtra struct {
 	ExtraTitle string    `json:"extraTitle"`
 	YoutubeId  string    `json:"youtubeId"`
 	Reason     string    `json:"reason"`
+	Season     int       `json:"season,omitempty"`
 }
 
 // DownloadYouTubeExtra downloads the specified YouTube extra (trailer/clip)
 // for the given media and returns metadata about the downloaded file. If
 // forceDownload is provided and true, an existing file may be re-downloaded.
 func DownloadYouTubeExtra(mediaType MediaType, mediaId int, extraType, extraTitle, youtubeId string, forceDownload ...bool) (*ExtraDownloadMetadata, error) {
-	TrailarrLog(DEBUG, "YouTube", "DownloadYouTubeExtra called with mediaType=%s, mediaId=%d, extraType=%s, extraTitle=%s, youtubeId=%s, forceDownload=%v",
-		mediaType, mediaId, extraType, extraTitle, youtubeId, forceDownload)
+	TrailarrLog(DEBUG, "YouTube", "DownloadYouTubeExtra called with forceDownload=%v", forceDownload)
+	return DownloadYouTubeExtraContext(context.Background(), DownloadQueueItem{
+		MediaType:  mediaType,
+		MediaId:    mediaId,
+		ExtraType:  extraType,
+		ExtraTitle: extraTitle,
+		YouTubeID:  youtubeId,
+	})
+}
+
+// DownloadYouTubeExtraContext downloads the extra of a queue item; cancelling
+// ctx kills the yt-dlp process and returns errDownloadCancelled. The temp dir is
+// removed either way.
+func DownloadYouTubeExtraContext(ctx context.Context, item DownloadQueueItem) (*ExtraDownloadMetadata, error) {
+	mediaType, extraType, extraTitle, youtubeId := item.MediaType, item.ExtraType, item.ExtraTitle, item.YouTubeID
+	TrailarrLog(DEBUG, "YouTube", "DownloadYouTubeExtraContext called with mediaType=%s, mediaId=%d, extraType=%s, extraTitle=%s, youtubeId=%s",
+		mediaType, item.MediaId, extraType, extraTitle, youtubeId)
 
-	downloadInfo, err := prepareDownloadInfo(mediaType, mediaId, extraType, extraTitle, youtubeId)
+	downloadInfo, err := prepareDownloadInfo(mediaType, item.MediaId, extraType, extraTitle, youtubeId)
 	if err != nil {
 		return nil, err
 	}
+	downloadInfo.Values.Language = item.Language
+	downloadInfo.Source = item.ExtraSource
+	downloadInfo.SourceURL, downloadInfo.SourceSite = item.SourceURL, item.SourceSite
+	if item.Season > 0 && mediaType == MediaTypeTV {
+		useSeasonFolder(downloadInfo, item.Season)
+	}
+	if isThemeMusic(extraType) {
+		useThemeMusicOutput(downloadInfo)
+	}
 
 	// Always clean up temp dir after download attempt
 	defer func() {
@@ -764,13 +866,14 @@ func DownloadYouTubeExtra(mediaType MediaType, mediaId int, extraType, extraTitl
 	}
 
 	// Perform the download
-	return performDownload(downloadInfo, youtubeId)
+	return performDownload(ctx, downloadInfo, youtubeId)
 }
 
 type downloadInfo struct {
 	MediaType  MediaType
 	MediaId    int
 	MediaTitle string
+	MediaPath  string
 	OutDir     string
 	OutFile    string
 	TempDir    string
@@ -779,9 +882,30 @@ type downloadInfo struct {
 	ExtraType  string
 	ExtraTitle string
 	SafeTitle  string
+	// Naming and Values render OutFile; Resolution and Language are only
+	// known once yt-dlp has downloaded the video.
+	Naming NamingConfig
+	Values namingValues
+	// Video is set once the downloaded file has been probed
+	Video *VideoInfo
+	// Source is where the extra was found, recorded on its store entry
+	Source string
+	// SourceURL and SourceSite are set for extras not on YouTube
+	SourceURL  string
+	SourceSite string
+	// Season is set for season extras; MediaPath is then the season folder
+	Season int
+	// AudioOnly extracts the audio to TempFile instead of saving the video (theme music)
+	AudioOnly bool
+	// CollectionMembers are the other movies a collection extra is copied to
+	CollectionMembers []collectionMember
 }
 
 func prepareDownloadInfo(mediaType MediaType, mediaId int, extraType, extraTitle, youtubeID string) (*downloadInfo, error) {
+	if mediaType == MediaTypeCollection {
+		return prepareCollectionDownloadInfo(mediaId, extraType, extraTitle, youtubeID)
+	}
+
 	// Resolve cache file and media title
 	cacheFile, mediaTitle := resolveCacheAndTitle(mediaType, mediaId)
 
@@ -794,14 +918,19 @@ func prepareDownloadInfo(mediaType MediaType, mediaId int, extraType, extraTitle
 	// Derive base path using mapped path, mappings fallback, or media title
 	basePath := deriveBasePath(mappedMediaPath, mappings, mediaTitle)
 
-	// Build output directory and sanitize title
+	// Build output paths for the configured naming scheme and filename template
 	canonicalType := canonicalizeExtraType(extraType)
-	outDir := filepath.Join(basePath, canonicalType)
 	safeTitle := sanitizeFileName(extraTitle)
-
-	// Prepare filenames
 	outExt := "mkv"
-	outFile := filepath.Join(outDir, fmt.Sprintf("%s.%s", safeTitle, outExt))
+	naming, _ := GetNamingConfig()
+	values := namingValues{
+		MediaTitle: mediaTitle,
+		Year:       lookupMediaYear(cacheFile, mediaId),
+		ExtraType:  canonicalType,
+		ExtraTitle: extraTitle,
+		YouTubeID:  youtubeID,
+	}
+	outDir, outFile := resolveExtraOutput(naming, basePath, values)
 
 	// Create temp directory and temp file path
 	tempDir, tempFile, err := createTempPaths(safeTitle, outExt)
@@ -819,6 +948,7 @@ func prepareDownloadInfo(mediaType MediaType, mediaId int, extraType, extraTitle
 		MediaType:  mediaType,
 		MediaId:    mediaId,
 		MediaTitle: mediaTitle,
+		MediaPath:  basePath,
 		OutDir:     outDir,
 		OutFile:    outFile,
 		TempDir:    tempDir,
@@ -827,6 +957,8 @@ func prepareDownloadInfo(mediaType MediaType, mediaId int, extraType, extraTitle
 		ExtraType:  extraType,
 		ExtraTitle: extraTitle,
 		SafeTitle:  safeTitle,
+		Naming:     naming,
+		Values:     values,
 	}, nil
 }
 
@@ -932,6 +1064,13 @@ func checkExistingExtra(info *downloadInfo, youtubeId string) (*ExtraDownloadMet
 		TrailarrLog(INFO, "YouTube", "File already exists, skipping: %s", info.OutFile)
 		return NewExtraDownloadMetadata(info, youtubeId, "exists"), nil
 	}
+	// Templated names may differ from OutFile (eg. {Resolution}); match by video instead
+	for _, f := range listExtraFiles(info.MediaPath) {
+		if f.YouTubeID == youtubeId && f.ExtraType == info.Values.ExtraType {
+			TrailarrLog(INFO, "YouTube", "Video already downloaded, skipping: %s", f.Path)
+			return NewExtraDownloadMetadata(info, youtubeId, "exists"), nil
+		}
+	}
 
 	return nil, nil
 }
@@ -946,40 +1085,135 @@ func checkRejectedExtras(info *downloadInfo, youtubeId string) *ExtraDownloadMet
 	return nil
 }
 
-func performDownload(info *downloadInfo, youtubeId string) (*ExtraDownloadMetadata, error) {
+func performDownload(ctx context.Context, info *downloadInfo, youtubeId string) (*ExtraDownloadMetadata, error) {
+	output, err := downloadToTemp(ctx, info, youtubeId)
+	if ctx.Err() != nil {
+		TrailarrLog(INFO, "YouTube", "Download cancelled for %s", youtubeId)
+		return nil, errDownloadCancelled
+	}
+	if err != nil {
+		// Check for 429/Too Many Requests in output
+		if strings.Contains(output, "429") || strings.Contains(strings.ToLower(output), "too many requests") {
+			return nil, &TooManyRequestsError{Message: "yt-dlp hit 429 Too Many Requests"}
+		}
+		// Only failures that can never succeed reject the extra
+		if isPermanentDownloadFailure(output) {
+			return nil, handleDownloadErrorNative(info, youtubeId, err, output)
+		}
+		TrailarrLog(WARN, "YouTube", "Transient download failure for %s: %v", youtubeId, err)
+		return nil, &TransientDownloadError{Reason: downloadFailureReason(err, output)}
+	}
+
+	// Reject truncated or unwanted files before they reach the library;
+	// theme music has no video to validate
+	if !info.AudioOnly {
+		if err := probeAndValidateDownload(info); err != nil {
+			return nil, handleVideoValidationFailure(info, youtubeId, err)
+		}
+	}
+
+	// Fill in tokens that depend on the downloaded video, then move file to final location
+	applyDownloadedVideoTokens(info)
+	if err := moveDownloadedFile(info); err != nil {
+		return nil, err
+	}
+	copyToCollectionMembers(info, youtubeId)
+
+	// Create metadata
+	return createSuccessMetadata(info, youtubeId)
+}
+
+// downloadToTemp runs yt-dlp for youtubeId into info.TempFile, retrying without
+// impersonation when the impersonation target is unavailable. It returns the
+// yt-dlp output for error classification.
+func downloadToTemp(ctx context.Context, info *downloadInfo, youtubeId string) (string, error) {
 	args := buildYtDlpArgs(info, youtubeId, true)
-	// Execute yt-dlp command via configurable runner
-	output, err := ytDlpRunner.CombinedOutput(YtDlpCmd, args, info.TempDir)
+	// Execute yt-dlp command via configurable runner, streaming its progress
+	output, err := runYtDlpDownload(ctx, args, info.TempDir)
 
-	if err != nil && isImpersonationErrorNative(string(output)) {
+	if err != nil && ctx.Err() == nil && isImpersonationErrorNative(output) {
 		TrailarrLog(WARN, "YouTube", "Impersonation failed for %s, retrying without impersonation", youtubeId)
 		args = buildYtDlpArgs(info, youtubeId, false)
-		output, err = ytDlpRunner.CombinedOutput(YtDlpCmd, args, info.TempDir)
+		output, err = runYtDlpDownload(ctx, args, info.TempDir)
+	}
+	if ctx.Err() != nil {
+		return output, err
 	}
 	TrailarrLog(DEBUG, "YouTube", "yt-dlp command executed: %s %s", YtDlpCmd, strings.Join(args, " "))
 
 	if len(output) > 0 {
-		for _, line := range strings.Split(string(output), "\n") {
+		for _, line := range strings.Split(output, "\n") {
 			if strings.TrimSpace(line) != "" {
 				TrailarrLog(DEBUG, "YouTube", "yt-dlp output for %s: %s", youtubeId, line)
 			}
 		}
 	}
+	return output, err
+}
+
+// fetchVideoInfoJSON runs `yt-dlp -J` for a video URL and returns its info JSON.
+func fetchVideoInfoJSON(ctx context.Context, videoURL string, extraArgs ...string) ([]byte, error) {
+	args := append([]string{"-J", "--no-warnings"}, extraArgs...)
+	if hasUsableCookies() {
+		args = append(args, "--cookies", CookiesFile)
+	}
+	args = append(args, videoURL)
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	out, err := ytDlpRunner.CombinedOutput(ctx, YtDlpCmd, args, "")
 	if err != nil {
-		// Check for 429/Too Many Requests in output
-		if strings.Contains(string(output), "429") || strings.Contains(strings.ToLower(string(output)), "too many requests") {
-			return nil, &TooManyRequestsError{Message: "yt-dlp hit 429 Too Many Requests"}
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return videoInfoJSONLine(out)
+}
+
+// videoInfoJSONLine returns the JSON document in `yt-dlp -J` output, skipping
+// any log lines printed before it.
+func videoInfoJSONLine(out []byte) ([]byte, error) {
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("{")) {
+			return line, nil
 		}
-		return nil, handleDownloadErrorNative(info, youtubeId, err, string(output))
 	}
+	return nil, fmt.Errorf("no video info in yt-dlp output")
+}
 
-	// Move file to final location
-	if err := moveDownloadedFile(info); err != nil {
-		return nil, err
+// runYtDlpDownload runs a yt-dlp download in dir through the streaming runner.
+// Progress lines are passed to the progress reporter attached to ctx; every other
+// line, plus stderr, is returned as the command output once the process exits.
+func runYtDlpDownload(ctx context.Context, args []string, dir string) (string, error) {
+	var stderr bytes.Buffer
+	stdout, cmd, err := ytDlpRunner.StartCommand(ctx, YtDlpCmd, args, dir, &stderr)
+	if err != nil {
+		return "", err
 	}
+	report := progressReporterFrom(ctx)
+	reader := bufio.NewReader(stdout)
+	var output strings.Builder
+	for {
+		line, readErr := reader.ReadString('\n')
+		if p, ok := parseYtDlpProgressLine(line); ok {
+			report(p)
+		} else {
+			output.WriteString(line)
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	waitErr := waitYtDlpCommand(cmd)
+	output.WriteString(stderr.String())
+	return output.String(), waitErr
+}
 
-	// Create metadata
-	return createSuccessMetadata(info, youtubeId)
+// waitYtDlpCommand waits for a command returned by the runner. Commands that
+// were never started (eg. from fake runners) have nothing to wait for.
+func waitYtDlpCommand(cmd *exec.Cmd) error {
+	if cmd == nil || cmd.Process == nil {
+		return nil
+	}
+	return cmd.Wait()
 }
 
 // TooManyRequestsError is returned when a 429/Too Many Requests is detected
@@ -999,25 +1233,29 @@ func isImpersonationErrorNative(output string) bool {
 
 func buildYtDlpArgs(info *downloadInfo, youtubeId string, impersonate bool) []string {
 	cfg, _ := GetYtdlpFlagsConfig()
-	args := []string{
-		"--cookies", CookiesFile,
-		"--remux-video", "mkv",
-		"--format", cfg.RequestedFormats,
-		"--output", info.TempFile,
+	args := []string{"--cookies", CookiesFile}
+	if info.AudioOnly {
+		args = append(args, audioOnlyYtDlpArgs(info)...)
+	} else {
+		args = append(args, "--remux-video", "mkv", "--format", cfg.RequestedFormats, "--output", info.TempFile)
+	}
+	args = append(args,
 		"--max-downloads", fmt.Sprintf("%d", cfg.MaxDownloads),
 		"--limit-rate", cfg.LimitRate,
 		"--sleep-interval", fmt.Sprintf("%.0f", cfg.SleepInterval),
 		"--sleep-requests", fmt.Sprintf("%.0f", cfg.SleepRequests),
 		"--max-sleep-interval", fmt.Sprintf("%.0f", cfg.MaxSleepInterval),
 		"--socket-timeout", fmt.Sprintf("%.0f", cfg.Timeout),
-	}
+	)
 	if cfg.Quiet {
 		args = append(args, "--quiet")
 	}
 	if cfg.NoProgress {
 		args = append(args, "--no-progress")
+	} else {
+		args = append(args, ytDlpProgressTemplateArgs()...)
 	}
-	if cfg.WriteSubs {
+	if cfg.WriteSubs && !info.AudioOnly {
 		args = append(args, "--write-subs")
 		args = append(args, "--sub-format", "srt")
 		if cfg.WriteAutoSubs {
@@ -1033,27 +1271,45 @@ func buildYtDlpArgs(info *downloadInfo, youtubeId string, impersonate bool) []st
 	if impersonate {
 		args = append(args, "--impersonate", "chrome")
 	}
+	if needsDownloadedVideoInfo(info) {
+		args = append(args, "--write-info-json")
+	}
 
-	args = append(args, "--", youtubeId)
+	args = append(args, "--", extraDownloadURL(youtubeId, info.SourceURL))
 	return args
 }
 
-func handleDownloadErrorNative(info *downloadInfo, youtubeId string, err error, output string) error {
+// downloadFailureReason combines the process error and yt-dlp output into a reason string.
+func downloadFailureReason(err error, output string) string {
 	reason := err.Error()
 	if output != "" {
 		reason += " | output: " + output
 	}
+	return reason
+}
+
+func handleDownloadErrorNative(info *downloadInfo, youtubeId string, err error, output string) error {
+	reason := downloadFailureReason(err, output)
 
 	TrailarrLog(ERROR, "YouTube", "Download failed for %s: %s", youtubeId, reason)
 	addToRejectedExtras(info, youtubeId, reason)
 	// Also update the unified extras collection in the persistent store
-	errMark := SetExtraRejectedPersistent(info.MediaType, info.MediaId, info.ExtraType, info.ExtraTitle, youtubeId, reason)
+	errMark := setDownloadRejected(info, youtubeId, reason)
 	if errMark != nil {
 		TrailarrLog(ERROR, "YouTube", "Failed to mark extra as rejected in store: %v", errMark)
 	}
 	return fmt.Errorf(reason+": %w", err)
 }
 
+// handleVideoValidationFailure rejects an extra whose download failed validation.
+func handleVideoValidationFailure(info *downloadInfo, youtubeId string, err error) error {
+	TrailarrLog(WARN, "YouTube", "Rejecting %s: %v", youtubeId, err)
+	if errMark := setDownloadRejected(info, youtubeId, err.Error()); errMark != nil {
+		TrailarrLog(ERROR, "YouTube", "Failed to mark extra as rejected in store: %v", errMark)
+	}
+	return err
+}
+
 func addToRejectedExtras(info *downloadInfo, youtubeId, reason string) {
 	// Use the hash-based approach: mark as rejected in the hash only if not already rejected
 	ctx := context.Background()
@@ -1062,7 +1318,59 @@ func addToRejectedExtras(info *downloadInfo, youtubeId, reason string) {
 		return
 	}
 	// Add or update as rejected
-	_ = SetExtraRejectedPersistent(info.MediaType, info.MediaId, info.ExtraType, info.ExtraTitle, youtubeId, reason)
+	_ = setDownloadRejected(info, youtubeId, reason)
+}
+
+// setDownloadRejected marks the extra being downloaded as rejected, keeping
+// the source of non-YouTube extras so they can still be retried.
+func setDownloadRejected(info *downloadInfo, youtubeId, reason string) error {
+	return setExtraEntryRejected(ExtrasEntry{
+		MediaType:  info.MediaType,
+		MediaId:    info.MediaId,
+		ExtraType:  info.ExtraType,
+		ExtraTitle: info.ExtraTitle,
+		YoutubeId:  youtubeId,
+		SourceURL:  info.SourceURL,
+		SourceSite: info.SourceSite,
+		Season:     info.Season,
+	}, reason)
+}
+
+// needsDownloadedVideoInfo reports whether the filename template uses tokens
+// read from yt-dlp's .info.json after the download.
+func needsDownloadedVideoInfo(info *downloadInfo) bool {
+	if info.AudioOnly {
+		return false
+	}
+	return templateUsesDownloadedVideoTokens(info.Naming.Template)
+}
+
+// applyDownloadedVideoTokens re-renders OutFile with the resolution and
+// language yt-dlp reported for the downloaded video.
+func applyDownloadedVideoTokens(info *downloadInfo) {
+	if !needsDownloadedVideoInfo(info) {
+		return
+	}
+	var videoInfo struct {
+		Height   int    `json:"height"`
+		Language string `json:"language"`
+	}
+	stem := strings.TrimSuffix(info.TempFile, filepath.Ext(info.TempFile))
+	for _, p := range []string{stem + ".info.json", info.TempFile + ".info.json"} {
+		if err := ReadJSONFile(p, &videoInfo); err == nil {
+			break
+		}
+	}
+	if info.Video != nil && info.Video.Height > 0 {
+		videoInfo.Height = info.Video.Height
+	}
+	if videoInfo.Height > 0 {
+		info.Values.Resolution = fmt.Sprintf("%dp", videoInfo.Height)
+	}
+	if videoInfo.Language != "" {
+		info.Values.Language = videoInfo.Language
+	}
+	info.OutDir, info.OutFile = resolveExtraOutput(info.Naming, info.MediaPath, info.Values)
 }
 
 func moveDownloadedFile(info *downloadInfo) error {
@@ -1146,16 +1454,32 @@ func createSuccessMetadata(info *downloadInfo, youtubeId string) (*ExtraDownload
 		FileName:   info.OutFile,
 		YoutubeId:  youtubeId,
 		Status:     "downloaded",
+		Language:   info.Values.Language,
+		Source:     info.Source,
+		SourceURL:  info.SourceURL,
+		SourceSite: info.SourceSite,
+		Season:     info.Season,
+		Video:      info.Video,
+	}
+	if info.MediaType == MediaTypeCollection {
+		// Collection titles aren't in the media caches GetAllExtras fills titles from
+		entry.MediaTitle = info.MediaTitle
 	}
 	persistExtraEntry(entry)
 
-	// Mark media as not wanted and persist wanted-index asynchronously
-	markMediaNotWantedAndPersistAsync(info)
+	// Mark media as not wanted and persist wanted-index asynchronously;
+	// season extras don't count as extras of the whole series
+	if info.Season == 0 {
+		markMediaNotWantedAndPersistAsync(info)
+	}
 
 	// Record history and write the metadata file
 	recordDownloadHistory(info)
 	writeMetaFile(meta, info.OutFile)
 
+	// Let media servers pick up the new extra from the media folder
+	scheduleLibraryRefresh(info.MediaPath)
+
 	TrailarrLog(INFO, "YouTube", "Downloaded %s to %s", info.ExtraTitle, info.OutFile)
 	return meta, nil
 }
@@ -1182,27 +1506,35 @@ func markMediaNotWantedAndPersistAsync(info *downloadInfo) {
 				}
 			}
 		}
-		go func(path string) {
-			if err := updateWantedStatusInStore(path); err != nil {
-				TrailarrLog(WARN, "YouTube", "updateWantedStatusInStore failed for %s: %v", path, err)
+		updateIndexAsync(func() {
+			if err := updateWantedStatusInStore(mainCacheFile); err != nil {
+				TrailarrLog(WARN, "YouTube", "updateWantedStatusInStore failed for %s: %v", mainCacheFile, err)
 			}
-		}(mainCacheFile)
+		})
 	}
 }
 
 // recordDownloadHistory appends a download event to the history.
 func recordDownloadHistory(info *downloadInfo) {
 	mediaTitle := getMediaTitleFromCache(info.MediaType, info.MediaId)
+	if mediaTitle == "" {
+		mediaTitle = info.MediaTitle
+	}
 	if mediaTitle == "" {
 		mediaTitle = "Unknown"
 	}
+	action := "download"
+	if info.Source == ExtraSourceUpload {
+		action = "upload"
+	}
 	event := HistoryEvent{
-		Action:     "download",
+		Action:     action,
 		MediaTitle: mediaTitle,
 		MediaType:  info.MediaType,
 		MediaId:    info.MediaId,
 		ExtraType:  info.ExtraType,
 		ExtraTitle: info.ExtraTitle,
+		Season:     info.Season,
 		Date:       time.Now(),
 	}
 	_ = AppendHistoryEvent(event)
@@ -1268,7 +1600,8 @@ func YouTubeTrailerSearchHandler(c *gin.Context) {
 		searchTerms = append(searchTerms, title)
 	}
 
-	results, _ := searchYtDlpForTerms(searchTerms, 10)
+	target := newSearchTarget(MediaType(req.MediaType), req.MediaId, title, originalTitle)
+	results, _ := searchYtDlpForTerms(searchTerms, 10, target)
 	if len(results) > 10 {
 		results = results[:10]
 	}
@@ -1302,38 +1635,40 @@ func getTitlesFromCache(mediaType MediaType, mediaId int) (string, string, error
 	return title, originalTitle, nil
 }
 
-// searchYtDlpForTerms runs yt-dlp searches for the provided terms and returns up to maxResults unique items
-func searchYtDlpForTerms(terms []string, maxResults int) ([]gin.H, error) {
+// searchYtDlpForTerms runs yt-dlp searches for the provided terms and returns up to maxResults unique items,
+// best scoring first
+func searchYtDlpForTerms(terms []string, maxResults int, target searchTarget) ([]gin.H, error) {
 	var allResults []gin.H
 	videoIdSet := make(map[string]bool)
 	for _, term := range terms {
 		if len(allResults) >= maxResults {
 			break
 		}
-		searchQuery := term + " trailer"
+		searchQuery := term + target.searchSuffix()
 		TrailarrLog(INFO, "YouTube", "yt-dlp command: yt-dlp %v", []string{"-j", ytDlpSearchPrefix + searchQuery, ytDlpSkipDownload})
-		if err := runYtDlpSearch(searchQuery, videoIdSet, &allResults, maxResults); err != nil {
+		if err := runYtDlpSearch(searchQuery, videoIdSet, &allResults, maxResults, target); err != nil {
 			TrailarrLog(ERROR, "YouTube", "yt-dlp search error for query '%s': %v", searchQuery, err)
 			// continue searching other terms despite the error
 		}
 	}
+	sortSearchResults(allResults)
 	return allResults, nil
 }
 
 // runYtDlpSearch executes yt-dlp for a single searchQuery, appending unique results to results up to maxResults.
-func runYtDlpSearch(searchQuery string, videoIdSet map[string]bool, results *[]gin.H, maxResults int) error {
+func runYtDlpSearch(searchQuery string, videoIdSet map[string]bool, results *[]gin.H, maxResults int, target searchTarget) error {
 	ytDlpArgs := []string{"-j", ytDlpSearchPrefix + searchQuery, ytDlpSkipDownload}
 	if YtDlpTestMode {
 		runYtDlpSearchTestMode(searchQuery, videoIdSet, results, maxResults)
 		return nil
 	}
-	return runYtDlpSearchReal(searchQuery, videoIdSet, results, maxResults, ytDlpArgs)
+	return runYtDlpSearchReal(searchQuery, videoIdSet, results, maxResults, ytDlpArgs, target)
 }
 
-func runYtDlpSearchReal(searchQuery string, videoIdSet map[string]bool, results *[]gin.H, maxResults int, ytDlpArgs []string) error {
+func runYtDlpSearchReal(searchQuery string, videoIdSet map[string]bool, results *[]gin.H, maxResults int, ytDlpArgs []string, target searchTarget) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
 	defer cancel()
-	stdout, cmd, err := ytDlpRunner.StartCommand(ctx, YtDlpCmd, ytDlpArgs)
+	stdout, cmd, err := ytDlpRunner.StartCommand(ctx, YtDlpCmd, ytDlpArgs, "", nil)
 	if err != nil {
 		return fmt.Errorf("failed to start yt-dlp via runner: %w", err)
 	}
@@ -1348,7 +1683,7 @@ func runYtDlpSearchReal(searchQuery string, videoIdSet map[string]bool, results
 		// process any non-empty line
 		if len(line) > 0 {
 			TrailarrLog(DEBUG, "YouTube", "Raw yt-dlp output line: %s", string(line))
-			parseYtDlpLine(line, videoIdSet, results)
+			parseYtDlpLine(line, videoIdSet, results, target)
 		}
 		if err != nil {
 			if err != io.EOF {
@@ -1380,18 +1715,11 @@ func runYtDlpSearchTestMode(searchQuery string, videoIdSet map[string]bool, resu
 	}
 }
 
-// parseYtDlpLine parses a single yt-dlp JSON line and appends it to results if unique.
-func parseYtDlpLine(line []byte, videoIdSet map[string]bool, results *[]gin.H) {
-	type ytItem struct {
-		ID          string `json:"id"`
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Thumbnail   string `json:"thumbnail"`
-		Channel     string `json:"channel"`
-		ChannelID   string `json:"channel_id"`
-	}
-	var it ytItem
-	if err := json.Unmarshal(bytes.TrimSpace(line), &it); err != nil {
+// parseYtDlpLine parses a single yt-dlp JSON line and appends it, scored, to results if unique
+// and from a usable channel.
+func parseYtDlpLine(line []byte, videoIdSet map[string]bool, results *[]gin.H, target searchTarget) {
+	it, err := parseSearchCandidate(line)
+	if err != nil {
 		if len(bytes.TrimSpace(line)) > 0 {
 			TrailarrLog(WARN, "YouTube", "Failed to parse yt-dlp output line: %s | error: %v", string(line), err)
 		}
@@ -1401,20 +1729,9 @@ func parseYtDlpLine(line []byte, videoIdSet map[string]bool, results *[]gin.H) {
 	if it.ID == "" {
 		return
 	}
-	if videoIdSet[it.ID] {
+	if videoIdSet[it.ID] || it.hidden(target) {
 		return
 	}
-	*results = append(*results, gin.H{
-		"id": gin.H{"videoId": it.ID},
-		"snippet": gin.H{
-			"title":       it.Title,
-			"description": it.Description,
-			"thumbnails": gin.H{
-				"default": gin.H{"url": it.Thumbnail},
-			},
-			"channelTitle": it.Channel,
-			"channelId":    it.ChannelID,
-		},
-	})
+	*results = append(*results, it.result(scoreSearchCandidate(it, target)))
 	videoIdSet[it.ID] = true
 }
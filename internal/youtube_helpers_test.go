@@ -49,12 +49,12 @@ func TestParseYtDlpLine(t *testing.T) {
 	item := map[string]string{"id": "abc123", "title": "Test Title", "thumbnail": "http://img"}
 	b, _ := json.Marshal(item)
 	// call parseYtDlpLine (which expects a []byte)
-	parseYtDlpLine(b, videoIdSet, results)
+	parseYtDlpLine(b, videoIdSet, results, searchTarget{})
 	if len(*results) != 1 {
 		t.Fatalf("expected 1 result, got %d", len(*results))
 	}
 	// calling again with same id should not add duplicate
-	parseYtDlpLine(b, videoIdSet, results)
+	parseYtDlpLine(b, videoIdSet, results, searchTarget{})
 	if len(*results) != 1 {
 		t.Fatalf("expected 1 result after duplicate, got %d", len(*results))
 	}
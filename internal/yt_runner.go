@@ -9,17 +9,22 @@ import (
 
 // YtDlpRunner abstracts running yt-dlp so tests can inject a fake runner.
 type YtDlpRunner interface {
-	// StartCommand starts the command and returns a reader for stdout and the started *exec.Cmd.
-	StartCommand(ctx context.Context, name string, args []string) (io.ReadCloser, *exec.Cmd, error)
+	// StartCommand starts the command in dir (the current directory when empty) and
+	// returns a reader for stdout and the started *exec.Cmd. Stderr goes to stderr
+	// when it is non-nil and is discarded otherwise.
+	StartCommand(ctx context.Context, name string, args []string, dir string, stderr io.Writer) (io.ReadCloser, *exec.Cmd, error)
 	// CombinedOutput runs the command and returns combined stdout/stderr bytes.
-	CombinedOutput(name string, args []string, dir string) ([]byte, error)
+	// Cancelling ctx kills the process.
+	CombinedOutput(ctx context.Context, name string, args []string, dir string) ([]byte, error)
 }
 
 // DefaultYtDlpRunner uses os/exec to run yt-dlp.
 type DefaultYtDlpRunner struct{}
 
-func (r *DefaultYtDlpRunner) StartCommand(ctx context.Context, name string, args []string) (io.ReadCloser, *exec.Cmd, error) {
+func (r *DefaultYtDlpRunner) StartCommand(ctx context.Context, name string, args []string, dir string, stderr io.Writer) (io.ReadCloser, *exec.Cmd, error) {
 	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Stderr = stderr
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get StdoutPipe: %w", err)
@@ -30,8 +35,8 @@ func (r *DefaultYtDlpRunner) StartCommand(ctx context.Context, name string, args
 	return stdout, cmd, nil
 }
 
-func (r *DefaultYtDlpRunner) CombinedOutput(name string, args []string, dir string) ([]byte, error) {
-	cmd := exec.Command(name, args...)
+func (r *DefaultYtDlpRunner) CombinedOutput(ctx context.Context, name string, args []string, dir string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
 	if dir != "" {
 		cmd.Dir = dir
 	}
@@ -16,17 +16,13 @@ const ytdlpFlagsSettingsPath = "/api/settings/ytdlpflags"
 func TestSaveAndGetYtdlpFlagsHandler(t *testing.T) {
 	tmp := t.TempDir()
 	oldRoot := TrailarrRoot
-	oldConfigPath := ConfigPath
-	defer func() {
-		TrailarrRoot = oldRoot
-		ConfigPath = oldConfigPath
-	}()
+	defer func() { TrailarrRoot = oldRoot }()
 	TrailarrRoot = tmp
 	cfgDir := filepath.Join(TrailarrRoot, "config")
 	if err := os.MkdirAll(cfgDir, 0755); err != nil {
 		t.Fatalf("failed to create config dir: %v", err)
 	}
-	ConfigPath = filepath.Join(cfgDir, "config.yml")
+	setConfigPathForTest(t, filepath.Join(cfgDir, "config.yml"))
 
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
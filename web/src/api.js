@@ -58,6 +58,21 @@ export async function getExtras({ mediaType, id }) {
   return await res.json();
 }
 
+// Extras of one season of a series, placed in its season folder
+export async function getSeasonExtras({ id, season }) {
+  const res = await fetch(`/api/series/${encodeURIComponent(id)}/seasons/${encodeURIComponent(season)}/extras`);
+  if (!res.ok) throw new Error("Failed to fetch season extras");
+  return await res.json();
+}
+
+// TMDB collections of the Radarr movies, each with its extras
+export async function getCollections() {
+  const res = await fetch("/api/collections");
+  if (!res.ok) throw new Error("Failed to fetch collections");
+  const data = await res.json();
+  return data.collections || [];
+}
+
 export async function getSeriesWanted() {
   const res = await fetch("/api/series/wanted");
   if (!res.ok) throw new Error("Failed to fetch Sonarr wanted list");
@@ -65,8 +80,26 @@ export async function getSeriesWanted() {
   return { items: data.items || [] };
 }
 
-export async function downloadExtra({ moviePath, extraType, extraTitle, url }) {
-  const payload = { moviePath, extraType, extraTitle, url };
+// file is a local video (eg. a disc rip) added as an extra of the media
+export async function uploadExtra({ mediaType, mediaId, extraType, extraTitle, file }) {
+  const form = new FormData();
+  form.append("mediaType", mediaType);
+  form.append("mediaId", mediaId);
+  form.append("extraType", extraType);
+  if (extraTitle) form.append("extraTitle", extraTitle);
+  form.append("file", file);
+  const res = await fetch(`/api/extras/upload`, { method: "POST", body: form });
+  if (!res.ok) {
+    const data = await res.json().catch(() => ({}));
+    throw new Error(data.error || "Failed to upload extra");
+  }
+  return await res.json();
+}
+
+// url may be any video URL yt-dlp supports, not only YouTube; season places
+// a series extra in that season's folder
+export async function downloadExtra({ mediaType, mediaId, extraType, extraTitle, url, season }) {
+  const payload = { mediaType, mediaId, extraType, extraTitle, url, season };
   console.log("downloadExtra payload:", payload);
   const res = await fetch(`/api/extras/download`, {
     method: "POST",
@@ -18,6 +18,7 @@ const EXTRA_TYPES = [
   { key: "interviews", label: "Interviews" },
   { key: "featurettes", label: "Featurettes" },
   { key: "deletedScenes", label: "Deleted Scenes" },
+  { key: "themeMusic", label: "Theme Music (theme.mp3)" },
   { key: "other", label: "Other" },
 ];
 
@@ -3,7 +3,7 @@ import { isDark, addDarkModeListener } from "../utils/isDark";
 import { Link } from "react-router-dom";
 import Container from "./Container";
 import { getHistory } from "../api";
-import { FaDownload, FaTrash } from "react-icons/fa";
+import { FaDownload, FaTrash, FaUpload } from "react-icons/fa";
 
 function formatDate(date) {
   if (!date) return "";
@@ -248,6 +248,16 @@ const HistoryPage = () => {
                     }}
                   />
                 );
+              } else if (item.action === "upload") {
+                icon = (
+                  <FaUpload
+                    title="Uploaded"
+                    style={{
+                      fontSize: 20,
+                      color: "var(--history-icon-color, #111)",
+                    }}
+                  />
+                );
               } else if (item.action === "delete") {
                 icon = (
                   <FaTrash
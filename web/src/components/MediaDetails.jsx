@@ -464,6 +464,9 @@ export default function MediaDetails({ mediaItems, loading, mediaType }) {
                 mediaId: media.id,
                 onResult: (item) => {
                   results.push(item);
+                  // Best scoring results first; the sort is stable so equal
+                  // scores keep the order they arrived in
+                  results.sort((a, b) => (b.score ?? 0) - (a.score ?? 0));
                   setYtResults([...results]);
                 },
                 onDone: () => setSearchLoading(false),